@@ -0,0 +1,199 @@
+package goa
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Keys of the messages of the errors created by the helper functions of this package. Messages
+// may reference the error metadata using placeholders of the form {name}, for example:
+//
+//	catalog.Register("fr", map[string]string{
+//		goa.MsgMissingAttribute: "l'attribut {attribute} de {parent} est requis",
+//	})
+const (
+	MsgMissingPayload       = "invalid_request.missing_payload"
+	MsgInvalidParamType     = "invalid_request.invalid_param_type"
+	MsgMissingParam         = "invalid_request.missing_param"
+	MsgInvalidAttributeType = "invalid_request.invalid_attribute_type"
+	MsgMissingAttribute     = "invalid_request.missing_attribute"
+	MsgMissingHeader        = "invalid_request.missing_header"
+	MsgInvalidEnumValue     = "invalid_request.invalid_enum_value"
+	MsgInvalidFormat        = "invalid_request.invalid_format"
+	MsgInvalidPattern       = "invalid_request.invalid_pattern"
+	MsgInvalidMinimum       = "invalid_request.invalid_range.min"
+	MsgInvalidMaximum       = "invalid_request.invalid_range.max"
+	MsgInvalidMinLength     = "invalid_request.invalid_length.min"
+	MsgInvalidMaxLength     = "invalid_request.invalid_length.max"
+	MsgInvalidUnion         = "invalid_request.invalid_union"
+	MsgNoAuthMiddleware     = "no_auth_middleware"
+	MsgMethodNotAllowed     = "method_not_allowed"
+	MsgNotAcceptable        = "not_acceptable"
+	MsgTooManyRequests      = "too_many_requests"
+	MsgPreconditionFailed   = "precondition_failed"
+)
+
+type (
+	// MessageCatalog contains the translations of error messages indexed by language and
+	// message key. The key of the messages of errors created via an ErrorClass is the error
+	// code, the helper functions of this package use the more specific keys defined by the
+	// Msg constants. Messages may reference the error metadata values with placeholders of the
+	// form {name}.
+	MessageCatalog struct {
+		mu       sync.RWMutex
+		messages map[string]map[string]string
+	}
+
+	// errorMessage records the key and arguments of a message so that it may be translated.
+	errorMessage struct {
+		key    string
+		detail string
+		args   map[string]interface{}
+	}
+
+	// languageRange is a language tag listed in an Accept-Language header.
+	languageRange struct {
+		tag string
+		q   float64
+	}
+)
+
+// NewMessageCatalog returns an empty message catalog.
+func NewMessageCatalog() *MessageCatalog {
+	return &MessageCatalog{messages: make(map[string]map[string]string)}
+}
+
+// Register adds the translations of the given messages indexed by key in the given language.
+// lang is a BCP 47 language tag such as "fr" or "de-CH". Register overrides existing
+// translations with the same language and key.
+func (c *MessageCatalog) Register(lang string, messages map[string]string) {
+	lang = strings.ToLower(lang)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m, ok := c.messages[lang]
+	if !ok {
+		m = make(map[string]string, len(messages))
+		c.messages[lang] = m
+	}
+	for k, v := range messages {
+		m[k] = v
+	}
+}
+
+// Languages returns the languages that have translations sorted alphabetically.
+func (c *MessageCatalog) Languages() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	langs := make([]string, 0, len(c.messages))
+	for l := range c.messages {
+		langs = append(langs, l)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Match returns the registered language that best matches the given Accept-Language header
+// value. Ranges are considered by decreasing quality, a range matches the registered language
+// with the same tag or failing that the registered language obtained by removing its trailing
+// subtags, for example "fr" for "fr-CH". Match returns an empty string if no registered language
+// is acceptable.
+func (c *MessageCatalog) Match(acceptLanguage string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, r := range parseAcceptLanguage(acceptLanguage) {
+		if r.tag == "*" {
+			return ""
+		}
+		if _, ok := c.messages[r.tag]; ok {
+			return r.tag
+		}
+		for tag := r.tag; strings.Contains(tag, "-"); {
+			tag = tag[:strings.LastIndex(tag, "-")]
+			if _, ok := c.messages[tag]; ok {
+				return tag
+			}
+		}
+	}
+	return ""
+}
+
+// Localize returns a copy of err whose detail is translated in the given language. Messages
+// that have no translation are left untouched. Localize returns err if no message could be
+// translated.
+func (c *MessageCatalog) Localize(err *ErrorResponse, lang string) *ErrorResponse {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	translations, ok := c.messages[strings.ToLower(lang)]
+	if !ok || len(err.messages) == 0 {
+		return err
+	}
+	var translated bool
+	details := make([]string, len(err.messages))
+	for i, m := range err.messages {
+		details[i] = m.detail
+		tmpl, ok := translations[m.key]
+		if !ok {
+			tmpl, ok = translations[err.Code]
+		}
+		if ok {
+			details[i] = m.format(tmpl)
+			translated = true
+		}
+	}
+	if !translated {
+		return err
+	}
+	localized := *err
+	localized.Detail = strings.Join(details, "; ")
+	return &localized
+}
+
+// format replaces the placeholders of tmpl with the message arguments.
+func (m *errorMessage) format(tmpl string) string {
+	if len(m.args) == 0 {
+		return tmpl
+	}
+	oldnew := make([]string, 0, 2*len(m.args))
+	for k, v := range m.args {
+		oldnew = append(oldnew, "{"+k+"}", fmt.Sprintf("%v", v))
+	}
+	return strings.NewReplacer(oldnew...).Replace(tmpl)
+}
+
+// withMessageKey sets the key used to translate the message of err.
+func withMessageKey(err error, key string) error {
+	if e, ok := err.(*ErrorResponse); ok && len(e.messages) == 1 {
+		e.messages[0].key = key
+	}
+	return err
+}
+
+// parseAcceptLanguage returns the language ranges listed in the given Accept-Language header
+// value sorted by decreasing quality. Ranges with quality 0 are omitted.
+func parseAcceptLanguage(header string) []*languageRange {
+	var ranges []*languageRange
+	for _, part := range strings.Split(header, ",") {
+		elems := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(elems[0]))
+		if tag == "" {
+			continue
+		}
+		r := &languageRange{tag: tag, q: 1}
+		for _, param := range elems[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					r.q = q
+				}
+			}
+		}
+		if r.q > 0 {
+			ranges = append(ranges, r)
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	return ranges
+}
@@ -0,0 +1,72 @@
+package goa_test
+
+import (
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MessageCatalog", func() {
+	var catalog *goa.MessageCatalog
+
+	BeforeEach(func() {
+		catalog = goa.NewMessageCatalog()
+		catalog.Register("fr", map[string]string{
+			goa.MsgMissingAttribute: "l'attribut {attribute} de {parent} est requis",
+			goa.MsgInvalidMinimum:   "{attribute} doit être supérieur ou égal à {expected}",
+			"teapot":                "je suis une théière",
+		})
+		catalog.Register("de-CH", map[string]string{
+			goa.MsgMissingAttribute: "Attribut {attribute} fehlt",
+		})
+	})
+
+	Describe("Match", func() {
+		cases := []struct{ desc, header, expected string }{
+			{"with no header", "", ""},
+			{"with an exact match", "fr", "fr"},
+			{"with a different case", "DE-ch", "de-ch"},
+			{"with a more specific range", "fr-CH", "fr"},
+			{"with quality values", "de-CH;q=0.5, fr;q=0.8", "fr"},
+			{"with an unknown language", "es, fr;q=0.2", "fr"},
+			{"with a wildcard", "*", ""},
+			{"with an excluded language", "fr;q=0", ""},
+		}
+		for _, c := range cases {
+			c := c
+			It("selects the best language "+c.desc, func() {
+				Ω(catalog.Match(c.header)).Should(Equal(c.expected))
+			})
+		}
+	})
+
+	Describe("Localize", func() {
+		It("translates the helper function errors", func() {
+			err := goa.MissingAttributeError("request body", "name").(*goa.ErrorResponse)
+			localized := catalog.Localize(err, "fr")
+			Ω(localized.Detail).Should(Equal("l'attribut name de request body est requis"))
+			Ω(localized.Meta).Should(Equal(err.Meta))
+			Ω(err.Detail).Should(Equal(`attribute "name" of request body is missing and required`))
+		})
+
+		It("translates error class errors by code", func() {
+			err := goa.NewErrorClass("teapot", 418)("I'm a teapot").(*goa.ErrorResponse)
+			Ω(catalog.Localize(err, "fr").Detail).Should(Equal("je suis une théière"))
+		})
+
+		It("translates merged errors", func() {
+			err := goa.MergeErrors(goa.MissingAttributeError("request body", "name"), goa.InvalidRangeError("request.count", 0, 1, true))
+			err = goa.MergeErrors(err, goa.InvalidRangeError("request.count", 20, 10, false))
+			localized := catalog.Localize(err.(*goa.ErrorResponse), "fr")
+			Ω(localized.Detail).Should(Equal("l'attribut name de request body est requis; " +
+				"request.count doit être supérieur ou égal à 1; " +
+				"request.count must be less than or equal to 10 but got value 20"))
+		})
+
+		It("returns the error when nothing is translated", func() {
+			err := goa.MissingHeaderError("X-Request-ID").(*goa.ErrorResponse)
+			Ω(catalog.Localize(err, "fr")).Should(BeIdenticalTo(err))
+			Ω(catalog.Localize(err, "es")).Should(BeIdenticalTo(err))
+		})
+	})
+})
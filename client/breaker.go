@@ -0,0 +1,280 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+// BreakerState is the state of a circuit.
+type BreakerState int
+
+const (
+	// BreakerClosed is the state of circuits that let requests through.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen is the state of circuits that reject requests without sending them.
+	BreakerOpen
+	// BreakerHalfOpen is the state of circuits that let a single trial request through to
+	// decide whether to close or to open again.
+	BreakerHalfOpen
+)
+
+type (
+	// CircuitBreakerDoer is a Doer that stops sending requests to a downstream service that
+	// keeps failing so that callers fail fast instead of waiting for their requests to time
+	// out. Requests are grouped into circuits identified by the Key function.
+	//
+	// A circuit opens when the ratio of failed requests over the last Window exceeds
+	// FailureRatio. Requests made while the circuit is open fail immediately with a
+	// *CircuitOpenError. Once CoolDown has elapsed the circuit becomes half-open and lets a
+	// single trial request through, the circuit closes if the request succeeds and opens
+	// again otherwise.
+	CircuitBreakerDoer struct {
+		// Doer is the underlying doer.
+		Doer
+		// Key returns the name of the circuit used for the request, BreakerByHost if nil.
+		Key func(context.Context, *http.Request) string
+		// FailureRatio is the ratio of failed requests above which the circuit opens.
+		FailureRatio float64
+		// MinRequests is the minimum number of requests made during Window before the
+		// circuit may open.
+		MinRequests int
+		// Window is the period over which failures are counted.
+		Window time.Duration
+		// CoolDown is the time an open circuit waits before letting a trial request
+		// through.
+		CoolDown time.Duration
+		// IsFailure returns true if the request that produced the given response or error
+		// failed. Requests that produce an error or a 5xx response fail if nil.
+		IsFailure func(*http.Response, error) bool
+		// OnStateChange is called each time a circuit changes state.
+		OnStateChange func(key string, from, to BreakerState)
+
+		mu       sync.Mutex
+		circuits map[string]*circuit
+	}
+
+	// CircuitOpenError is the error returned by CircuitBreakerDoer for requests made while
+	// the circuit is open.
+	CircuitOpenError struct {
+		// Key is the name of the circuit.
+		Key string
+		// RetryAfter is the time left before the circuit lets a trial request through.
+		RetryAfter time.Duration
+	}
+
+	// circuit records the state and the request outcomes of a circuit. generation is
+	// incremented on each state change so that the outcomes of the requests let through
+	// before the change are ignored.
+	circuit struct {
+		state       BreakerState
+		generation  uint64
+		windowStart time.Time
+		requests    int
+		failures    int
+		openedAt    time.Time
+		trial       bool
+	}
+
+	// stateChange describes a circuit state transition.
+	stateChange struct {
+		key      string
+		from, to BreakerState
+	}
+)
+
+// NewCircuitBreakerDoer returns a CircuitBreakerDoer that wraps d. The returned doer uses one
+// circuit per host, opens circuits when more than half of at least 10 requests made in the last
+// 10 seconds fail, waits 30 seconds before letting a trial request through and reports state
+// changes with BreakerMetrics. Requests fail if they produce an error or a 5xx response. The
+// fields of the returned value may be modified to change these defaults.
+func NewCircuitBreakerDoer(d Doer) *CircuitBreakerDoer {
+	return &CircuitBreakerDoer{
+		Doer:          d,
+		Key:           BreakerByHost,
+		FailureRatio:  0.5,
+		MinRequests:   10,
+		Window:        10 * time.Second,
+		CoolDown:      30 * time.Second,
+		IsFailure:     serverFailure,
+		OnStateChange: BreakerMetrics,
+	}
+}
+
+// BreakerByHost returns the request host. Use it as CircuitBreakerDoer Key to use one circuit
+// per host.
+func BreakerByHost(_ context.Context, req *http.Request) string {
+	return req.URL.Host
+}
+
+// BreakerByAction returns the request host followed by the resource and action recorded in the
+// context by the generated clients. Use it as CircuitBreakerDoer Key to use one circuit per
+// action. BreakerByAction returns the request host if the context does not record an action.
+func BreakerByAction(ctx context.Context, req *http.Request) string {
+	if a := ContextAction(ctx); a != "" {
+		return req.URL.Host + "/" + a
+	}
+	return req.URL.Host
+}
+
+// BreakerMetrics reports circuit state changes using the goa metrics collector. It increments
+// the goa.client.breaker.<key>.<state> counter and sets the goa.client.breaker.<key>.state gauge
+// to the numeric value of the new state.
+func BreakerMetrics(key string, _, to BreakerState) {
+	goa.IncrCounter([]string{"goa", "client", "breaker", key, to.String()}, 1.0)
+	goa.SetGauge([]string{"goa", "client", "breaker", key, "state"}, float32(to))
+}
+
+// Do sends the request unless the circuit is open.
+func (b *CircuitBreakerDoer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	keyFn, isFailure := b.Key, b.IsFailure
+	if keyFn == nil {
+		keyFn = BreakerByHost
+	}
+	if isFailure == nil {
+		isFailure = serverFailure
+	}
+	key := keyFn(ctx, req)
+	gen, err := b.allow(key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.Doer.Do(ctx, req)
+	// Requests canceled by the caller say nothing about the health of the downstream service.
+	canceled := err != nil && ctx.Err() != nil
+	b.record(key, gen, !canceled && isFailure(resp, err), canceled)
+	return resp, err
+}
+
+// State returns the current state of the given circuit.
+func (b *CircuitBreakerDoer) State(key string) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[key]; ok {
+		return c.state
+	}
+	return BreakerClosed
+}
+
+// allow returns the generation of the circuit that lets the request through or a
+// *CircuitOpenError if the circuit does not let it through.
+func (b *CircuitBreakerDoer) allow(key string) (uint64, error) {
+	var change *stateChange
+	defer func() { b.notify(change) }()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	c := b.circuit(key, now)
+	if c.state == BreakerOpen {
+		if wait := b.CoolDown - now.Sub(c.openedAt); wait > 0 {
+			return 0, &CircuitOpenError{Key: key, RetryAfter: wait}
+		}
+		change = c.transition(key, BreakerHalfOpen, now)
+	}
+	if c.state == BreakerHalfOpen {
+		if c.trial {
+			return 0, &CircuitOpenError{Key: key}
+		}
+		c.trial = true
+	}
+	return c.generation, nil
+}
+
+// record updates the circuit with the outcome of a request let through by the given generation
+// of the circuit. The outcomes of requests let through before the circuit last changed state are
+// ignored so that only the trial request decides whether a half-open circuit closes.
+func (b *CircuitBreakerDoer) record(key string, gen uint64, failed, canceled bool) {
+	var change *stateChange
+	defer func() { b.notify(change) }()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	c := b.circuit(key, now)
+	if c.generation != gen {
+		return
+	}
+	switch c.state {
+	case BreakerHalfOpen:
+		c.trial = false
+		if canceled {
+			return
+		}
+		if failed {
+			change = c.transition(key, BreakerOpen, now)
+		} else {
+			change = c.transition(key, BreakerClosed, now)
+		}
+	case BreakerClosed:
+		if canceled {
+			return
+		}
+		if now.Sub(c.windowStart) >= b.Window {
+			c.windowStart, c.requests, c.failures = now, 0, 0
+		}
+		c.requests++
+		if failed {
+			c.failures++
+		}
+		if c.requests >= b.MinRequests && float64(c.failures)/float64(c.requests) > b.FailureRatio {
+			change = c.transition(key, BreakerOpen, now)
+		}
+	}
+}
+
+// circuit returns the circuit with the given key, creating it if needed. b.mu must be held.
+func (b *CircuitBreakerDoer) circuit(key string, now time.Time) *circuit {
+	if b.circuits == nil {
+		b.circuits = make(map[string]*circuit)
+	}
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{windowStart: now}
+		b.circuits[key] = c
+	}
+	return c
+}
+
+// notify calls OnStateChange if the circuit changed state.
+func (b *CircuitBreakerDoer) notify(change *stateChange) {
+	if change != nil && b.OnStateChange != nil {
+		b.OnStateChange(change.key, change.from, change.to)
+	}
+}
+
+// transition sets the circuit state and resets its counters.
+func (c *circuit) transition(key string, to BreakerState, now time.Time) *stateChange {
+	change := &stateChange{key: key, from: c.state, to: to}
+	c.state = to
+	c.generation++
+	c.windowStart, c.requests, c.failures = now, 0, 0
+	if to == BreakerOpen {
+		c.openedAt = now
+	}
+	return change
+}
+
+// String returns the name of the state.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// Error returns the error message.
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit %s is open", e.Key)
+}
+
+// serverFailure returns true if err is not nil or the response status code is 5xx.
+func serverFailure(resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode >= 500
+}
@@ -0,0 +1,157 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CircuitBreakerDoer", func() {
+	var doer *scriptedDoer
+	var breaker *client.CircuitBreakerDoer
+	var changes []string
+	var ctx context.Context
+
+	BeforeEach(func() {
+		doer = &scriptedDoer{statuses: []int{503}}
+		changes = nil
+		breaker = client.NewCircuitBreakerDoer(doer)
+		breaker.MinRequests = 2
+		breaker.CoolDown = 20 * time.Millisecond
+		breaker.OnStateChange = func(key string, from, to client.BreakerState) {
+			changes = append(changes, key+": "+from.String()+" -> "+to.String())
+		}
+		ctx = context.Background()
+	})
+
+	send := func(ctx context.Context) (*http.Response, error) {
+		req, _ := http.NewRequest("GET", "http://svc.local/bottles", nil)
+		return breaker.Do(ctx, req)
+	}
+
+	It("opens the circuit when requests keep failing", func() {
+		send(ctx)
+		send(ctx)
+		Ω(breaker.State("svc.local")).Should(Equal(client.BreakerOpen))
+		_, err := send(ctx)
+		Ω(err).Should(BeAssignableToTypeOf(&client.CircuitOpenError{}))
+		Ω(err.(*client.CircuitOpenError).RetryAfter).Should(BeNumerically(">", 0))
+		Ω(doer.bodies).Should(HaveLen(2))
+		Ω(changes).Should(Equal([]string{"svc.local: closed -> open"}))
+	})
+
+	It("stays closed when the failure ratio is low", func() {
+		doer.statuses = []int{503, 200}
+		send(ctx)
+		send(ctx)
+		Ω(breaker.State("svc.local")).Should(Equal(client.BreakerClosed))
+	})
+
+	It("closes the circuit when the trial request succeeds", func() {
+		send(ctx)
+		send(ctx)
+		doer.statuses = []int{200}
+		time.Sleep(breaker.CoolDown)
+		resp, err := send(ctx)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(200))
+		Ω(breaker.State("svc.local")).Should(Equal(client.BreakerClosed))
+		Ω(changes).Should(Equal([]string{
+			"svc.local: closed -> open",
+			"svc.local: open -> half_open",
+			"svc.local: half_open -> closed",
+		}))
+	})
+
+	It("opens the circuit again when the trial request fails", func() {
+		send(ctx)
+		send(ctx)
+		time.Sleep(breaker.CoolDown)
+		send(ctx)
+		Ω(breaker.State("svc.local")).Should(Equal(client.BreakerOpen))
+		Ω(changes[len(changes)-1]).Should(Equal("svc.local: half_open -> open"))
+	})
+
+	Context("with slow requests", func() {
+		var slow *blockingDoer
+
+		BeforeEach(func() {
+			slow = &blockingDoer{started: make(chan chan int)}
+			breaker.Doer = slow
+		})
+
+		// start sends a request and returns the channel that sets its response status and
+		// the channel that receives its error once it completes.
+		start := func() (chan int, chan error) {
+			done := make(chan error, 1)
+			go func() {
+				_, err := send(ctx)
+				done <- err
+			}()
+			return <-slow.started, done
+		}
+
+		It("only lets the trial request decide whether to close the circuit", func() {
+			stale, staleDone := start()
+			for i := 0; i < 2; i++ {
+				status, done := start()
+				status <- 503
+				<-done
+			}
+			Ω(breaker.State("svc.local")).Should(Equal(client.BreakerOpen))
+			time.Sleep(breaker.CoolDown)
+			trial, trialDone := start()
+			stale <- 200
+			Ω(<-staleDone).ShouldNot(HaveOccurred())
+			Ω(breaker.State("svc.local")).Should(Equal(client.BreakerHalfOpen))
+			trial <- 503
+			<-trialDone
+			Ω(breaker.State("svc.local")).Should(Equal(client.BreakerOpen))
+		})
+	})
+
+	Context("with no key or failure functions", func() {
+		BeforeEach(func() {
+			breaker = &client.CircuitBreakerDoer{Doer: doer, MinRequests: 2, Window: time.Minute}
+		})
+
+		It("uses one circuit per host and fails on 5xx responses", func() {
+			send(ctx)
+			send(ctx)
+			Ω(breaker.State("svc.local")).Should(Equal(client.BreakerOpen))
+		})
+	})
+
+	Context("keyed by action", func() {
+		BeforeEach(func() {
+			breaker.Key = client.BreakerByAction
+		})
+
+		It("uses one circuit per action", func() {
+			show := client.WithAction(ctx, "bottle", "show")
+			send(show)
+			send(show)
+			Ω(breaker.State("svc.local/bottle#show")).Should(Equal(client.BreakerOpen))
+			doer.statuses = []int{200}
+			_, err := send(client.WithAction(ctx, "bottle", "list"))
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
+})
+
+// blockingDoer sends the channel that sets the status of each request on started and waits for
+// the status.
+type blockingDoer struct {
+	started chan chan int
+}
+
+func (d *blockingDoer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	status := make(chan int)
+	d.started <- status
+	return &http.Response{StatusCode: <-status, Body: http.NoBody}, nil
+}
@@ -73,6 +73,23 @@ func HandleResponse(c *Client, resp *http.Response, pretty bool) {
 	os.Exit(exitStatus)
 }
 
+// HandleEvent prints the JSON representation of an event received from a server-sent events
+// stream to STDOUT.
+func HandleEvent(event interface{}, pretty bool) {
+	var b []byte
+	var err error
+	if pretty {
+		b, err = json.MarshalIndent(event, "", "    ")
+	} else {
+		b, err = json.Marshal(event)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode event: %s\n", err)
+		return
+	}
+	fmt.Println(string(b))
+}
+
 // WSWrite sends STDIN lines to a websocket server.
 func WSWrite(ws *websocket.Conn) {
 	scanner := bufio.NewScanner(os.Stdin)
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -33,6 +34,16 @@ type (
 		UserAgent string
 		// Dump indicates whether to dump request response.
 		Dump bool
+		// ContentEncoding is the content coding used to compress the request bodies, either
+		// "gzip" or "deflate". Request bodies are not compressed if empty.
+		ContentEncoding string
+		// MinCompressSize is the minimum size in bytes of the request bodies compressed with
+		// ContentEncoding.
+		MinCompressSize int
+		// SensitiveFields lists the names of the JSON object fields whose values are replaced
+		// with goa.RedactedValue in the dumped bodies. The generated clients initialize it with
+		// the names of the attributes marked as sensitive in the design.
+		SensitiveFields []string
 	}
 )
 
@@ -77,6 +88,12 @@ func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, err
 	if c.Dump {
 		c.dumpRequest(ctx, req)
 	}
+	if c.ContentEncoding != "" {
+		if err := compressBody(req, c.ContentEncoding, c.MinCompressSize); err != nil {
+			goa.LogError(ctx, "failed", "err", err)
+			return nil, err
+		}
+	}
 	resp, err := c.Doer.Do(ctx, req)
 	if err != nil {
 		goa.LogError(ctx, "failed", "err", err)
@@ -97,7 +114,7 @@ func (c *Client) dumpRequest(ctx context.Context, req *http.Request) {
 	}
 	goa.LogInfo(ctx, "request headers", headersToSlice(req.Header)...)
 	if reqBody != nil {
-		goa.LogInfo(ctx, "request", "body", string(reqBody))
+		goa.LogInfo(ctx, "request", "body", string(c.redactBody(reqBody)))
 	}
 }
 
@@ -106,10 +123,63 @@ func (c *Client) dumpResponse(ctx context.Context, resp *http.Response) {
 	respBody, _ := dumpRespBody(resp)
 	goa.LogInfo(ctx, "response headers", headersToSlice(resp.Header)...)
 	if respBody != nil {
-		goa.LogInfo(ctx, "response", "body", string(respBody))
+		goa.LogInfo(ctx, "response", "body", string(c.redactBody(respBody)))
 	}
 }
 
+// redactBody returns a copy of the given JSON body where the values of the fields listed in
+// SensitiveFields are replaced with goa.RedactedValue at any depth. redactBody returns the body
+// unchanged if it is not a JSON document or if there is no sensitive field.
+func (c *Client) redactBody(body []byte) []byte {
+	if len(c.SensitiveFields) == 0 {
+		return body
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return body
+	}
+	fields := make(map[string]struct{}, len(c.SensitiveFields))
+	for _, f := range c.SensitiveFields {
+		fields[f] = struct{}{}
+	}
+	if !redactFields(v, fields) {
+		return body
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return body
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+// redactFields replaces the values of the given fields of the objects contained in v, it returns
+// true if v was modified.
+func redactFields(v interface{}, fields map[string]struct{}) bool {
+	redacted := false
+	switch actual := v.(type) {
+	case map[string]interface{}:
+		for k, e := range actual {
+			if _, ok := fields[k]; ok {
+				actual[k] = goa.RedactedValue
+				redacted = true
+			} else if redactFields(e, fields) {
+				redacted = true
+			}
+		}
+	case []interface{}:
+		for _, e := range actual {
+			if redactFields(e, fields) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
 // headersToSlice produces a loggable slice from a HTTP header.
 func headersToSlice(header http.Header) []interface{} {
 	res := make([]interface{}, 2*len(header))
@@ -224,6 +294,9 @@ type clientKey int
 // ReqIDKey is the context key used to store the request ID value.
 const reqIDKey clientKey = 1
 
+// actionKey is the context key used to store the name of the action being requested.
+const actionKey clientKey = 2
+
 // ContextRequestID extracts the Request ID from the context.
 func ContextRequestID(ctx context.Context) string {
 	var reqID string
@@ -249,3 +322,18 @@ func ContextWithRequestID(ctx context.Context) (context.Context, string) {
 func SetContextRequestID(ctx context.Context, reqID string) context.Context {
 	return context.WithValue(ctx, reqIDKey, reqID)
 }
+
+// WithAction returns a context that records the resource and action being requested. Generated
+// clients call WithAction so that Doers may identify the action of the requests they send.
+func WithAction(ctx context.Context, resource, action string) context.Context {
+	return context.WithValue(ctx, actionKey, resource+"#"+action)
+}
+
+// ContextAction returns the resource and action recorded with WithAction formatted as
+// "resource#action", the empty string if there is none.
+func ContextAction(ctx context.Context) string {
+	if a, ok := ctx.Value(actionKey).(string); ok {
+		return a
+	}
+	return ""
+}
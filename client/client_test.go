@@ -1,8 +1,14 @@
 package client_test
 
 import (
+	"bytes"
 	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
 
+	"github.com/goadesign/goa"
 	"github.com/goadesign/goa/client"
 
 	. "github.com/onsi/ginkgo"
@@ -46,3 +52,44 @@ var _ = Describe("client", func() {
 		})
 	})
 })
+
+// bodyDoer responds with the given body.
+type bodyDoer string
+
+func (d bodyDoer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(string(d)))}, nil
+}
+
+var _ = Describe("Client Dump", func() {
+	var logs bytes.Buffer
+	var ctx context.Context
+	var c *client.Client
+
+	BeforeEach(func() {
+		logs.Reset()
+		ctx = goa.WithLogger(context.Background(), goa.NewLogger(log.New(&logs, "", 0)))
+		c = client.New(bodyDoer(`{"user":"joe","creds":[{"password":"response-secret"}]}`))
+		c.Dump = true
+		c.SensitiveFields = []string{"password", "pin"}
+	})
+
+	send := func(body string) {
+		req, _ := http.NewRequest("POST", "http://localhost/login", strings.NewReader(body))
+		_, err := c.Do(ctx, req)
+		Ω(err).ShouldNot(HaveOccurred())
+	}
+
+	It("masks the sensitive fields of the dumped bodies", func() {
+		send(`{"user":"joe","password":"request-secret","nested":{"pin":1234}}`)
+		Ω(logs.String()).ShouldNot(ContainSubstring("secret"))
+		Ω(logs.String()).ShouldNot(ContainSubstring("1234"))
+		Ω(logs.String()).Should(ContainSubstring(`"password":"<redacted>"`))
+		Ω(logs.String()).Should(ContainSubstring(`"pin":"<redacted>"`))
+		Ω(logs.String()).Should(ContainSubstring(`"user":"joe"`))
+	})
+
+	It("dumps the bodies that are not JSON as is", func() {
+		send("password=request-secret")
+		Ω(logs.String()).Should(ContainSubstring("password=request-secret"))
+	})
+})
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// compressBody compresses the request body with the given content coding if it is at least
+// minSize bytes long and if the request does not already have a "Content-Encoding" header.
+func compressBody(req *http.Request, encoding string, minSize int) error {
+	if req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	var newWriter func(io.Writer) io.WriteCloser
+	switch encoding {
+	case "gzip":
+		newWriter = func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }
+	case "deflate":
+		newWriter = func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }
+	default:
+		return fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if err := req.Body.Close(); err != nil {
+		return err
+	}
+	if len(body) >= minSize {
+		var buf bytes.Buffer
+		w := newWriter(&buf)
+		if _, err := w.Write(body); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
@@ -0,0 +1,68 @@
+package client_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// captureDoer records the last request and its body.
+type captureDoer struct {
+	req  *http.Request
+	body []byte
+}
+
+func (d *captureDoer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	d.req = req
+	d.body, _ = ioutil.ReadAll(req.Body)
+	return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+}
+
+var _ = Describe("Client ContentEncoding", func() {
+	var doer *captureDoer
+	var c *client.Client
+	payload := strings.Repeat(`{"name":"bottle"}`, 100)
+
+	BeforeEach(func() {
+		doer = &captureDoer{}
+		c = client.New(doer)
+		c.ContentEncoding = "gzip"
+	})
+
+	send := func(body string) error {
+		req, _ := http.NewRequest("POST", "http://localhost/bottles", strings.NewReader(body))
+		_, err := c.Do(context.Background(), req)
+		return err
+	}
+
+	It("compresses the request bodies", func() {
+		Ω(send(payload)).ShouldNot(HaveOccurred())
+		Ω(doer.req.Header.Get("Content-Encoding")).Should(Equal("gzip"))
+		Ω(doer.req.ContentLength).Should(BeEquivalentTo(len(doer.body)))
+		r, err := gzip.NewReader(bytes.NewReader(doer.body))
+		Ω(err).ShouldNot(HaveOccurred())
+		decoded, err := ioutil.ReadAll(r)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(decoded)).Should(Equal(payload))
+	})
+
+	It("does not compress small bodies", func() {
+		c.MinCompressSize = 1024
+		Ω(send(`{"name":"bottle"}`)).ShouldNot(HaveOccurred())
+		Ω(doer.req.Header.Get("Content-Encoding")).Should(BeEmpty())
+		Ω(string(doer.body)).Should(Equal(`{"name":"bottle"}`))
+	})
+
+	It("fails with unsupported encodings", func() {
+		c.ContentEncoding = "br"
+		Ω(send(payload)).Should(HaveOccurred())
+	})
+})
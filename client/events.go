@@ -0,0 +1,50 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// EventReader reads server-sent events from a "text/event-stream" response body.
+type EventReader struct {
+	body   io.ReadCloser
+	reader *bufio.Reader
+}
+
+// NewEventReader creates a reader of the events written to the given response body.
+func NewEventReader(body io.ReadCloser) *EventReader {
+	return &EventReader{body: body, reader: bufio.NewReader(body)}
+}
+
+// Next returns the data of the next event. Multiple "data" lines are joined with newlines as
+// described in the server-sent events specification, comments and other fields are ignored.
+// Next returns io.EOF once the stream ends.
+func (r *EventReader) Next() ([]byte, error) {
+	var data [][]byte
+	for {
+		line, err := r.reader.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			if err == io.EOF && len(data) > 0 {
+				return bytes.Join(data, []byte("\n")), nil
+			}
+			return nil, err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		if len(line) == 0 {
+			if len(data) > 0 {
+				return bytes.Join(data, []byte("\n")), nil
+			}
+			continue
+		}
+		if bytes.HasPrefix(line, []byte("data:")) {
+			value := bytes.TrimPrefix(line[5:], []byte(" "))
+			data = append(data, value)
+		}
+	}
+}
+
+// Close closes the underlying response body.
+func (r *EventReader) Close() error {
+	return r.body.Close()
+}
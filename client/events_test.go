@@ -0,0 +1,53 @@
+package client_test
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EventReader", func() {
+	var stream string
+	var reader *client.EventReader
+
+	JustBeforeEach(func() {
+		reader = client.NewEventReader(ioutil.NopCloser(strings.NewReader(stream)))
+	})
+
+	Context("with data frames", func() {
+		BeforeEach(func() {
+			stream = ": comment\n\ndata: {\"n\":1}\n\nevent: update\ndata: {\"n\":\ndata: 2}\r\n\r\ndata:3"
+		})
+
+		It("returns the data of each event", func() {
+			data, err := reader.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"n":1}`))
+			data, err = reader.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("{\"n\":\n2}"))
+			data, err = reader.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("3"))
+			_, err = reader.Next()
+			Expect(err).To(Equal(io.EOF))
+		})
+	})
+
+	Context("with an empty stream", func() {
+		BeforeEach(func() {
+			stream = ""
+		})
+
+		It("returns io.EOF", func() {
+			_, err := reader.Next()
+			Expect(err).To(Equal(io.EOF))
+			Expect(reader.Close()).ToNot(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Pager walks the pages of results of a paginated action. Pager follows the "next" link of the
+// "Link" header (RFC 8288) of each response until a response does not have one.
+type Pager struct {
+	ctx    context.Context
+	client *Client
+	req    *http.Request
+}
+
+// NewPager creates a pager that starts with the given request.
+func NewPager(ctx context.Context, c *Client, req *http.Request) *Pager {
+	return &Pager{ctx: ctx, client: c, req: req}
+}
+
+// Next sends the request for the next page and returns the response. The caller is responsible
+// for closing the response body. Next returns io.EOF once the last page has been retrieved.
+func (p *Pager) Next() (*http.Response, error) {
+	if p.req == nil {
+		return nil, io.EOF
+	}
+	req := p.req
+	resp, err := p.client.Do(p.ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	p.req = nil
+	if link := NextLink(resp.Header); link != "" {
+		if u, err := req.URL.Parse(link); err == nil {
+			next := req.WithContext(req.Context())
+			next.URL = u
+			next.Host = u.Host
+			p.req = next
+		}
+	}
+	return resp, nil
+}
+
+// NextLink returns the target of the "next" link listed in the "Link" header if any.
+func NextLink(h http.Header) string {
+	for _, v := range h["Link"] {
+		for v != "" {
+			start := strings.Index(v, "<")
+			end := strings.Index(v, ">")
+			if start < 0 || end < start {
+				break
+			}
+			target := v[start+1 : end]
+			v = v[end+1:]
+			params := v
+			if i := strings.Index(v, "<"); i >= 0 {
+				params = v[:i]
+			}
+			if hasRel(params, "next") {
+				return target
+			}
+		}
+	}
+	return ""
+}
+
+// hasRel returns true if the given link parameters include the relation type rel.
+func hasRel(params, rel string) bool {
+	for _, param := range strings.Split(params, ";") {
+		param = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(param), ","))
+		if !strings.HasPrefix(strings.ToLower(param), "rel=") {
+			continue
+		}
+		for _, r := range strings.Fields(strings.Trim(param[4:], `"`)) {
+			if strings.EqualFold(r, rel) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package client_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pager", func() {
+	var server *httptest.Server
+	var pager *client.Pager
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cursor := r.URL.Query().Get("cursor")
+			switch cursor {
+			case "":
+				w.Header().Set("Link", `</items?cursor=2&limit=1>; rel="next"`)
+			case "2":
+				w.Header().Set("Link", `</items?cursor=1&limit=1>; rel="prev", </items?cursor=3&limit=1>; rel="next"`)
+			case "3":
+				w.Header().Set("Link", `</items?cursor=2&limit=1>; rel="prev"`)
+			}
+			w.Write([]byte("page" + cursor))
+		}))
+		req, err := http.NewRequest("GET", server.URL+"/items?limit=1", nil)
+		Expect(err).ToNot(HaveOccurred())
+		pager = client.NewPager(context.Background(), client.New(nil), req)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("follows the next links until exhaustion", func() {
+		var pages []string
+		for {
+			resp, err := pager.Next()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).ToNot(HaveOccurred())
+			b, err := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			Expect(err).ToNot(HaveOccurred())
+			pages = append(pages, string(b))
+		}
+		Expect(pages).To(Equal([]string{"page", "page2", "page3"}))
+	})
+})
+
+var _ = Describe("NextLink", func() {
+	It("returns the next link target", func() {
+		h := http.Header{"Link": []string{`<http://a/?c=1>; rel="prev first", <http://a/?c=3>; rel=next`}}
+		Expect(client.NextLink(h)).To(Equal("http://a/?c=3"))
+	})
+
+	It("returns an empty string when there is no next link", func() {
+		h := http.Header{"Link": []string{`<http://a/?c=1>; rel="prev"`}}
+		Expect(client.NextLink(h)).To(BeEmpty())
+		Expect(client.NextLink(http.Header{})).To(BeEmpty())
+	})
+})
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryDoer is a Doer that retries requests that fail with a network error or with one of the
+// configured response status codes. The delay between two attempts grows exponentially and is
+// randomized, it is overridden by the response "Retry-After" header if any. The delay never exceeds
+// MaxBackoff and RetryDoer gives up when the next attempt would start after the context deadline.
+//
+// Requests that use a non idempotent method (POST, PATCH etc.) are only retried if they have an
+// "Idempotency-Key" header. Request bodies are buffered so that they can be sent again.
+type RetryDoer struct {
+	// Doer is the underlying doer.
+	Doer
+	// MaxAttempts is the maximum number of times a request is sent.
+	MaxAttempts int
+	// StatusCodes lists the response status codes that cause the request to be retried.
+	StatusCodes []int
+	// MinBackoff is the maximum delay before the first retry.
+	MinBackoff time.Duration
+	// MaxBackoff caps the exponential growth of the delay between attempts.
+	MaxBackoff time.Duration
+}
+
+// IdempotencyKeyHeader is the name of the request header that makes it safe to retry requests
+// that use a non idempotent method.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// NewRetryDoer returns a RetryDoer that wraps d. The doer makes at most 3 attempts, retries
+// requests that get a 429, 502, 503 or 504 response and waits between 100ms and 10s between
+// attempts. The fields of the returned value may be modified to change these defaults.
+func NewRetryDoer(d Doer) *RetryDoer {
+	return &RetryDoer{
+		Doer:        d,
+		MaxAttempts: 3,
+		StatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: 10 * time.Second,
+	}
+}
+
+// Do sends the request and retries it as needed.
+func (r *RetryDoer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if r.MaxAttempts <= 1 || !replayable(req) {
+		return r.Doer.Do(ctx, req)
+	}
+	if err := bufferBody(req); err != nil {
+		return nil, err
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := r.Doer.Do(ctx, req)
+		if attempt >= r.MaxAttempts || !r.retriable(ctx, resp, err) {
+			return resp, err
+		}
+		delay := r.delay(attempt, resp)
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// retriable returns true if the request that produced the given response or error should be
+// sent again.
+func (r *RetryDoer) retriable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+	for _, code := range r.StatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns the time to wait before the next attempt: the delay specified by the response
+// "Retry-After" header capped to MaxBackoff if any, the backoff of the attempt otherwise.
+func (r *RetryDoer) delay(attempt int, resp *http.Response) time.Duration {
+	if resp == nil {
+		return r.backoff(attempt)
+	}
+	d, ok := retryAfter(resp)
+	if !ok {
+		return r.backoff(attempt)
+	}
+	if r.MaxBackoff > 0 && d > r.MaxBackoff {
+		return r.MaxBackoff
+	}
+	return d
+}
+
+// backoff returns a random delay between 0 and the exponential backoff of the given attempt.
+func (r *RetryDoer) backoff(attempt int) time.Duration {
+	max := r.MinBackoff
+	for i := 1; i < attempt && max < r.MaxBackoff; i++ {
+		max *= 2
+	}
+	if max > r.MaxBackoff {
+		max = r.MaxBackoff
+	}
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
+
+// replayable returns true if the request may be sent multiple times.
+func replayable(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE":
+		return true
+	}
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// bufferBody reads the request body in memory and sets the request GetBody function so that the
+// body can be read again for each attempt.
+func bufferBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+	body, saved, err := drainBody(req.Body)
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	req.GetBody = func() (io.ReadCloser, error) {
+		body, next, err := drainBody(saved)
+		if err != nil {
+			return nil, err
+		}
+		saved = next
+		return body, nil
+	}
+	return nil
+}
+
+// retryAfter returns the delay specified by the response "Retry-After" header if any.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
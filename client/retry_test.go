@@ -0,0 +1,143 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// scriptedDoer returns the given responses in order and records the request bodies.
+type scriptedDoer struct {
+	statuses []int
+	headers  []http.Header
+	bodies   []string
+}
+
+func (d *scriptedDoer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	i := len(d.bodies)
+	var body string
+	if req.Body != nil {
+		b, _ := ioutil.ReadAll(req.Body)
+		body = string(b)
+	}
+	d.bodies = append(d.bodies, body)
+	if i >= len(d.statuses) {
+		i = len(d.statuses) - 1
+	}
+	if d.statuses[i] == 0 {
+		return nil, errors.New("connection reset")
+	}
+	header := make(http.Header)
+	if i < len(d.headers) && d.headers[i] != nil {
+		header = d.headers[i]
+	}
+	return &http.Response{
+		StatusCode: d.statuses[i],
+		Header:     header,
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+	}, nil
+}
+
+var _ = Describe("RetryDoer", func() {
+	var doer *scriptedDoer
+	var retry *client.RetryDoer
+	var req *http.Request
+	var ctx context.Context
+
+	BeforeEach(func() {
+		doer = &scriptedDoer{}
+		retry = client.NewRetryDoer(doer)
+		retry.MinBackoff = time.Millisecond
+		retry.MaxBackoff = 5 * time.Millisecond
+		req, _ = http.NewRequest("PUT", "http://localhost/bottles/1", ioutil.NopCloser(strings.NewReader("payload")))
+		ctx = context.Background()
+	})
+
+	It("retries network errors and configured status codes", func() {
+		doer.statuses = []int{0, 503, 200}
+		resp, err := retry.Do(ctx, req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(200))
+		Ω(doer.bodies).Should(Equal([]string{"payload", "payload", "payload"}))
+	})
+
+	It("gives up after the maximum number of attempts", func() {
+		doer.statuses = []int{502}
+		resp, err := retry.Do(ctx, req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(502))
+		Ω(doer.bodies).Should(HaveLen(3))
+	})
+
+	It("does not retry other status codes", func() {
+		doer.statuses = []int{500, 200}
+		resp, err := retry.Do(ctx, req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(500))
+		Ω(doer.bodies).Should(HaveLen(1))
+	})
+
+	It("honors the Retry-After header", func() {
+		doer.statuses = []int{429, 200}
+		doer.headers = []http.Header{{"Retry-After": []string{"1"}}}
+		retry.MaxBackoff = 2 * time.Second
+		start := time.Now()
+		resp, err := retry.Do(ctx, req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(200))
+		Ω(time.Since(start)).Should(BeNumerically(">=", time.Second))
+	})
+
+	It("caps the Retry-After delay to the maximum backoff", func() {
+		doer.statuses = []int{503, 200}
+		doer.headers = []http.Header{{"Retry-After": []string{"60"}}}
+		start := time.Now()
+		resp, err := retry.Do(ctx, req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(200))
+		Ω(time.Since(start)).Should(BeNumerically("<", time.Second))
+	})
+
+	It("stops when the next attempt would exceed the context deadline", func() {
+		doer.statuses = []int{429, 200}
+		doer.headers = []http.Header{{"Retry-After": []string{"60"}}}
+		retry.MaxBackoff = time.Minute
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		resp, err := retry.Do(ctx, req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(429))
+		Ω(doer.bodies).Should(HaveLen(1))
+	})
+
+	Context("with a non idempotent method", func() {
+		BeforeEach(func() {
+			req.Method = "POST"
+			doer.statuses = []int{503, 200}
+		})
+
+		It("does not retry the request", func() {
+			resp, err := retry.Do(ctx, req)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(resp.StatusCode).Should(Equal(503))
+			Ω(doer.bodies).Should(HaveLen(1))
+		})
+
+		It("retries the request if it has an idempotency key", func() {
+			req.Header.Set(client.IdempotencyKeyHeader, "abc")
+			resp, err := retry.Do(ctx, req)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(resp.StatusCode).Should(Equal(200))
+			Ω(doer.bodies).Should(Equal([]string{"payload", "payload"}))
+		})
+	})
+})
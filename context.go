@@ -19,6 +19,8 @@ const (
 	logContextKey
 	errKey
 	securityScopesKey
+	encoderKey
+	decoderKey
 )
 
 type (
@@ -45,6 +47,9 @@ type (
 		Status int
 		// Length is the response body length.
 		Length int
+		// Redactor is the body sent with Send if it implements Redactor. It makes it possible
+		// for the logging middlewares to log the redacted body.
+		Redactor Redactor
 	}
 
 	// key is the type used to store internal values in the context.
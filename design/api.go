@@ -127,6 +127,10 @@ var (
 	// Gob by default.
 	GobContentTypes = []string{"application/gob", "application/x-gob"}
 
+	// EventStreamMediaType is the media type of the responses of actions that stream
+	// server-sent events.
+	EventStreamMediaType = "text/event-stream"
+
 	// ErrorMediaIdentifier is the media type identifier used for error responses.
 	ErrorMediaIdentifier = "application/vnd.goa.error"
 
@@ -150,6 +154,34 @@ var (
 		Views:      map[string]*ViewDefinition{"default": errorMediaView},
 	}
 
+	// ProblemMediaIdentifier is the media type identifier used for error responses rendered as
+	// RFC 7807 problem details documents.
+	ProblemMediaIdentifier = "application/problem+json"
+
+	// ProblemMedia is the built-in media type for error responses rendered as problem details
+	// documents. The error metadata entries are rendered as additional members.
+	ProblemMedia = &MediaTypeDefinition{
+		UserTypeDefinition: &UserTypeDefinition{
+			AttributeDefinition: &AttributeDefinition{
+				Type:        problemMediaType,
+				Description: "Problem details error response media type (RFC 7807)",
+				Example: map[string]interface{}{
+					"type":     "about:blank",
+					"title":    "Bad Request",
+					"status":   400,
+					"detail":   "Value of ID must be an integer",
+					"instance": "/bottles/abc",
+					"id":       "3F1FKVRR",
+					"code":     "invalid_value",
+				},
+				Validation: &dslengine.ValidationDefinition{Required: []string{"type", "title", "status"}},
+			},
+			TypeName: "problem",
+		},
+		Identifier: ProblemMediaIdentifier,
+		Views:      map[string]*ViewDefinition{"default": problemMediaView},
+	}
+
 	errorMediaType = Object{
 		"id": &AttributeDefinition{
 			Type:        String,
@@ -181,6 +213,49 @@ var (
 		},
 	}
 
+	problemMediaType = Object{
+		"type": &AttributeDefinition{
+			Type:        String,
+			Description: "a URI reference that identifies the problem type.",
+			Example:     "about:blank",
+		},
+		"title": &AttributeDefinition{
+			Type:        String,
+			Description: "a short, human-readable summary of the problem type.",
+			Example:     "Bad Request",
+		},
+		"status": &AttributeDefinition{
+			Type:        Integer,
+			Description: "the HTTP status code applicable to this problem.",
+			Example:     400,
+		},
+		"detail": &AttributeDefinition{
+			Type:        String,
+			Description: "a human-readable explanation specific to this occurrence of the problem.",
+			Example:     "Value of ID must be an integer",
+		},
+		"instance": &AttributeDefinition{
+			Type:        String,
+			Description: "a URI reference that identifies the specific occurrence of the problem.",
+			Example:     "/bottles/abc",
+		},
+		"id": &AttributeDefinition{
+			Type:        String,
+			Description: "a unique identifier for this particular occurrence of the problem.",
+			Example:     "3F1FKVRR",
+		},
+		"code": &AttributeDefinition{
+			Type:        String,
+			Description: "an application-specific error code, expressed as a string value.",
+			Example:     "invalid_value",
+		},
+	}
+
+	problemMediaView = &ViewDefinition{
+		AttributeDefinition: &AttributeDefinition{Type: problemMediaType},
+		Name:                "default",
+	}
+
 	errorMediaView = &ViewDefinition{
 		AttributeDefinition: &AttributeDefinition{Type: errorMediaType},
 		Name:                "default",
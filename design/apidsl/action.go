@@ -489,6 +489,98 @@ func MultipartForm() {
 	}
 }
 
+// Stream can be used in: Action
+//
+// Stream indicates that the action response is a stream of server-sent events. The argument is
+// the media type of the events given either as a media type definition or as a media type
+// identifier. Events are rendered using the media type default view. Stream also defines the "OK"
+// response with the "text/event-stream" media type unless the action already defines it.
+// Example:
+//
+//	Action("watch", func() {
+//		Routing(GET("/:id/events"))
+//		Stream(BottleEventMedia)
+//		Response(NotFound)
+//	})
+//
+func Stream(mt interface{}) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	switch actual := mt.(type) {
+	case *design.MediaTypeDefinition:
+		a.Stream = actual
+	case string:
+		a.Stream = design.Design.MediaTypeWithIdentifier(actual)
+		if a.Stream == nil {
+			dslengine.ReportError("unknown stream media type %s", actual)
+			return
+		}
+	default:
+		dslengine.ReportError("invalid Stream argument, must be a media type or a media type identifier")
+		return
+	}
+	if a.Responses == nil {
+		a.Responses = make(map[string]*design.ResponseDefinition)
+	}
+	if _, ok := a.Responses["OK"]; !ok {
+		a.Responses["OK"] = &design.ResponseDefinition{
+			Name:      "OK",
+			Status:    200,
+			Parent:    a,
+			MediaType: design.EventStreamMediaType,
+		}
+	}
+}
+
+// Paginated can be used in: Action
+//
+// Paginated indicates that the action results are split in pages. The arguments are the names of
+// the query string parameters that identify the page to retrieve (the cursor) and that set the
+// maximum number of items per page (the limit). Paginated defines the parameters unless the
+// action already does: the cursor is a string and the limit an integer between 1 and 100 that
+// defaults to 20. Parameters defined with Params after Paginated override these definitions.
+// The success responses of paginated actions document the "Link" header used to return the URLs
+// of the next and previous pages. Example:
+//
+//	Action("list", func() {
+//		Routing(GET(""))
+//		Paginated("cursor", "limit")
+//		Response(OK, CollectionOf(BottleMedia))
+//	})
+//
+func Paginated(cursor, limit string) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	var existing design.Object
+	if a.Params != nil {
+		existing = a.Params.Type.ToObject()
+	}
+	Params(func() {
+		if _, ok := existing[cursor]; !ok {
+			Param(cursor, design.String, "Cursor of the page to retrieve")
+		}
+		if _, ok := existing[limit]; !ok {
+			Param(limit, design.Integer, "Maximum number of items per page", func() {
+				Minimum(1)
+				Maximum(maxPageLimit)
+				Default(defaultPageLimit)
+			})
+		}
+	})
+	a.Pagination = &design.PaginationDefinition{CursorParam: cursor, LimitParam: limit}
+}
+
+const (
+	// defaultPageLimit is the default value of the limit parameter defined by Paginated.
+	defaultPageLimit = 20
+	// maxPageLimit is the maximum value of the limit parameter defined by Paginated.
+	maxPageLimit = 100
+)
+
 // newAttribute creates a new attribute definition using the media type with the given identifier
 // as base type.
 func newAttribute(baseMT string) *design.AttributeDefinition {
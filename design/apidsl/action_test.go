@@ -2,6 +2,7 @@ package apidsl_test
 
 import (
 	"strconv"
+	"time"
 
 	. "github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
@@ -364,3 +365,554 @@ var _ = Describe("Payload", func() {
 	})
 
 })
+
+var _ = Describe("Stream", func() {
+	var dsl func()
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		MediaType("application/vnd.event", func() {
+			Attributes(func() {
+				Attribute("name")
+			})
+			View("default", func() {
+				Attribute("name")
+			})
+		})
+		dsl = func() {
+			Routing(GET("/events"))
+			Stream("application/vnd.event")
+		}
+	})
+
+	JustBeforeEach(func() {
+		Resource("foo", func() {
+			Action("bar", dsl)
+		})
+		dslengine.Run()
+	})
+
+	It("sets the stream media type and the OK response", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		a := Design.Resources["foo"].Actions["bar"]
+		Ω(a.Stream).ShouldNot(BeNil())
+		Ω(a.Stream.Identifier).Should(Equal("application/vnd.event"))
+		Ω(a.Responses).Should(HaveKey("OK"))
+		Ω(a.Responses["OK"].Status).Should(Equal(200))
+		Ω(a.Responses["OK"].MediaType).Should(Equal(EventStreamMediaType))
+	})
+
+	Context("with a websocket scheme", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Scheme("ws")
+				Routing(GET("/events"))
+				Stream("application/vnd.event")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with an unknown media type", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Routing(GET("/events"))
+				Stream("application/vnd.unknown")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Paginated", func() {
+	var dsl func()
+	var action *ActionDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dsl = func() {
+			Routing(GET(""))
+			Paginated("cursor", "limit")
+			Response(OK, "text/plain")
+		}
+	})
+
+	JustBeforeEach(func() {
+		Resource("foo", func() {
+			Action("list", dsl)
+		})
+		dslengine.Run()
+		action = Design.Resources["foo"].Actions["list"]
+	})
+
+	It("defines the pagination query string parameters", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(action.Pagination).Should(Equal(&PaginationDefinition{CursorParam: "cursor", LimitParam: "limit"}))
+		query := action.QueryParams.Type.ToObject()
+		Ω(query).Should(HaveKey("cursor"))
+		Ω(query["cursor"].Type).Should(Equal(String))
+		Ω(query).Should(HaveKey("limit"))
+		Ω(query["limit"].Type).Should(Equal(Integer))
+		Ω(query["limit"].DefaultValue).Should(Equal(20))
+		Ω(*query["limit"].Validation.Minimum).Should(Equal(1.0))
+		Ω(*query["limit"].Validation.Maximum).Should(Equal(100.0))
+	})
+
+	It("documents the Link header of the success responses", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(action.Responses["OK"].Headers.Type.ToObject()).Should(HaveKey("Link"))
+	})
+
+	Context("with parameters defined beforehand", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Routing(GET(""))
+				Params(func() {
+					Param("page", Integer)
+				})
+				Paginated("page", "size")
+			}
+		})
+
+		It("keeps the existing definitions", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			query := action.QueryParams.Type.ToObject()
+			Ω(query["page"].Type).Should(Equal(Integer))
+			Ω(query["size"].Type).Should(Equal(Integer))
+		})
+	})
+
+	Context("with a path parameter", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Routing(GET("/:cursor"))
+				Paginated("cursor", "limit")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Deprecated", func() {
+	var resDSL, actionDSL func()
+	var action *ActionDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		resDSL = nil
+		actionDSL = func() {
+			Routing(GET(""))
+			Deprecated("use get instead", "2020-06-30")
+			Params(func() {
+				Param("sort", String, func() {
+					Deprecated("sorting is always ascending")
+				})
+			})
+		}
+	})
+
+	JustBeforeEach(func() {
+		Resource("foo", func() {
+			if resDSL != nil {
+				resDSL()
+			}
+			Action("show", actionDSL)
+		})
+		dslengine.Run()
+		if r, ok := Design.Resources["foo"]; ok {
+			action = r.Actions["show"]
+		}
+	})
+
+	It("records the deprecation of actions and attributes", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		dep := action.EffectiveDeprecation()
+		Ω(dep).ShouldNot(BeNil())
+		Ω(dep.Reason).Should(Equal("use get instead"))
+		Ω(dep.SunsetHeader()).Should(Equal("Tue, 30 Jun 2020 00:00:00 GMT"))
+		sort := action.Params.Type.ToObject()["sort"]
+		Ω(sort.Deprecation).ShouldNot(BeNil())
+		Ω(sort.Deprecation.Sunset.IsZero()).Should(BeTrue())
+		Ω(sort.Deprecation.SunsetHeader()).Should(BeEmpty())
+	})
+
+	Context("on a resource", func() {
+		BeforeEach(func() {
+			resDSL = func() {
+				Deprecated("use bar instead")
+			}
+			actionDSL = func() {
+				Routing(GET(""))
+			}
+		})
+
+		It("applies to the resource actions", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Deprecation).Should(BeNil())
+			Ω(action.EffectiveDeprecation()).ShouldNot(BeNil())
+			Ω(action.EffectiveDeprecation().Reason).Should(Equal("use bar instead"))
+		})
+	})
+
+	Context("with an invalid sunset date", func() {
+		BeforeEach(func() {
+			actionDSL = func() {
+				Routing(GET(""))
+				Deprecated("use get instead", "June 30th")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Consumes and Produces", func() {
+	var res *ResourceDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		Resource("foo", func() {
+			Produces("application/xml")
+			Action("import", func() {
+				Routing(POST(""))
+				Consumes("application/x-msgpack")
+			})
+			Action("show", func() {
+				Routing(GET(""))
+			})
+		})
+		dslengine.Run()
+		res = Design.Resources["foo"]
+	})
+
+	It("overrides the API encodings", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		imp := res.Actions["import"]
+		Ω(imp.OverridesConsumes()).Should(BeTrue())
+		Ω(imp.EffectiveConsumes()).Should(HaveLen(1))
+		Ω(imp.EffectiveConsumes()[0].MIMETypes).Should(Equal([]string{"application/x-msgpack"}))
+		Ω(imp.OverridesProduces()).Should(BeTrue())
+		Ω(imp.EffectiveProduces()[0].MIMETypes).Should(Equal([]string{"application/xml"}))
+	})
+
+	It("defaults to the API encodings", func() {
+		show := res.Actions["show"]
+		Ω(show.OverridesConsumes()).Should(BeFalse())
+		Ω(show.EffectiveConsumes()).Should(Equal(Design.Consumes))
+		Ω(show.EffectiveProduces()).Should(Equal(res.Produces))
+	})
+})
+
+var _ = Describe("RateLimit", func() {
+	var apiDSL, resDSL, actionDSL func()
+	var action *ActionDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		apiDSL = nil
+		resDSL = nil
+		actionDSL = func() {
+			Routing(GET(""))
+			RateLimit(10, time.Second, ByHeader("X-API-Key"))
+		}
+	})
+
+	JustBeforeEach(func() {
+		if apiDSL != nil {
+			API("test", apiDSL)
+		}
+		Resource("foo", func() {
+			if resDSL != nil {
+				resDSL()
+			}
+			Action("show", actionDSL)
+		})
+		dslengine.Run()
+		if r, ok := Design.Resources["foo"]; ok {
+			action = r.Actions["show"]
+		}
+	})
+
+	It("records the action rate limit", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		rl := action.EffectiveRateLimit()
+		Ω(rl).ShouldNot(BeNil())
+		Ω(rl.Limit).Should(Equal(10))
+		Ω(rl.Period).Should(Equal(time.Second))
+		Ω(rl.Key.Kind).Should(Equal(RateLimitKeyHeader))
+		Ω(rl.Key.Name).Should(Equal("X-API-Key"))
+		Ω(rl.Scope).Should(Equal("foo.show"))
+	})
+
+	Context("on a resource", func() {
+		BeforeEach(func() {
+			resDSL = func() {
+				RateLimit(100, time.Minute)
+			}
+			actionDSL = func() {
+				Routing(GET(""))
+			}
+		})
+
+		It("applies to the resource actions", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.RateLimit).Should(BeNil())
+			rl := action.EffectiveRateLimit()
+			Ω(rl).ShouldNot(BeNil())
+			Ω(rl.Key.Kind).Should(Equal(RateLimitKeyIP))
+			Ω(rl.Scope).Should(Equal("foo"))
+		})
+	})
+
+	Context("on the API", func() {
+		BeforeEach(func() {
+			apiDSL = func() {
+				RateLimit(1000, time.Hour)
+			}
+			actionDSL = func() {
+				Routing(GET(""))
+			}
+		})
+
+		It("applies to all the actions", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			rl := action.EffectiveRateLimit()
+			Ω(rl).ShouldNot(BeNil())
+			Ω(rl.Limit).Should(Equal(1000))
+			Ω(rl.Scope).Should(BeEmpty())
+		})
+	})
+
+	Context("with an invalid limit", func() {
+		BeforeEach(func() {
+			actionDSL = func() {
+				Routing(GET(""))
+				RateLimit(0, time.Second)
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("ETag", func() {
+	var resDSL, actionDSL func()
+	var action *ActionDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		resDSL = nil
+		actionDSL = func() {
+			Routing(GET(""))
+			Response(OK)
+			ETag()
+		}
+	})
+
+	JustBeforeEach(func() {
+		Resource("foo", func() {
+			if resDSL != nil {
+				resDSL()
+			}
+			Action("show", actionDSL)
+		})
+		dslengine.Run()
+		if r, ok := Design.Resources["foo"]; ok {
+			action = r.Actions["show"]
+		}
+	})
+
+	It("documents the conditional GET headers and response", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(action.EffectiveETag()).ShouldNot(BeNil())
+		Ω(action.EffectiveETag().Weak).Should(BeFalse())
+		Ω(action.Responses[OK].Headers.Type.ToObject()).Should(HaveKey("ETag"))
+		Ω(action.Headers.Type.ToObject()).Should(HaveKey("If-None-Match"))
+		Ω(action.Headers.Type.ToObject()).ShouldNot(HaveKey("If-Match"))
+		Ω(action.Responses).Should(HaveKey(NotModified))
+		Ω(action.HasPreconditions()).Should(BeFalse())
+	})
+
+	Context("on a resource with an action that modifies resources", func() {
+		BeforeEach(func() {
+			resDSL = func() {
+				ETag()
+			}
+			actionDSL = func() {
+				Routing(PUT(""))
+			}
+		})
+
+		It("documents the If-Match header and the 412 response", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.ETag).Should(BeNil())
+			Ω(action.HasPreconditions()).Should(BeTrue())
+			Ω(action.Headers.Type.ToObject()).Should(HaveKey("If-Match"))
+			Ω(action.Responses).Should(HaveKey(PreconditionFailed))
+		})
+	})
+
+	Context("with weak tags on an action that modifies resources", func() {
+		BeforeEach(func() {
+			actionDSL = func() {
+				Routing(PATCH(""))
+				WeakETag()
+			}
+		})
+
+		It("fails", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("strong entity tags"))
+		})
+	})
+})
+
+var _ = Describe("CacheControl", func() {
+	var resDSL, actionDSL func()
+	var action *ActionDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		resDSL = nil
+		actionDSL = func() {
+			Routing(GET(""))
+			Response(OK)
+			CacheControl(time.Minute, "public")
+		}
+	})
+
+	JustBeforeEach(func() {
+		Resource("foo", func() {
+			if resDSL != nil {
+				resDSL()
+			}
+			Action("show", actionDSL)
+		})
+		dslengine.Run()
+		if r, ok := Design.Resources["foo"]; ok {
+			action = r.Actions["show"]
+		}
+	})
+
+	It("sets the header and caches the responses for the max age", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		cc := action.EffectiveCacheControl()
+		Ω(cc).ShouldNot(BeNil())
+		Ω(cc.Value()).Should(Equal("max-age=60, public"))
+		Ω(action.CacheTTL()).Should(Equal(time.Minute))
+		Ω(action.Responses[OK].Headers.Type.ToObject()).Should(HaveKey("Cache-Control"))
+	})
+
+	Context("with private responses and a TTL metadata on the resource", func() {
+		BeforeEach(func() {
+			resDSL = func() {
+				Metadata("cache:ttl", "10s")
+			}
+			actionDSL = func() {
+				Routing(GET(""))
+				CacheControl(time.Minute, "private")
+			}
+		})
+
+		It("uses the metadata TTL", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.CacheTTL()).Should(Equal(10 * time.Second))
+		})
+	})
+
+	Context("with private responses", func() {
+		BeforeEach(func() {
+			actionDSL = func() {
+				Routing(GET(""))
+				CacheControl(time.Minute, "private")
+			}
+		})
+
+		It("does not cache the responses", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.CacheTTL()).Should(BeZero())
+		})
+	})
+
+	Context("on an action that does not use GET", func() {
+		BeforeEach(func() {
+			actionDSL = func() {
+				Routing(POST(""))
+				CacheControl(time.Minute)
+			}
+		})
+
+		It("fails", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("on a resource with a streaming action", func() {
+		BeforeEach(func() {
+			MediaType("application/vnd.event", func() {
+				Attributes(func() {
+					Attribute("name")
+				})
+				View("default", func() {
+					Attribute("name")
+				})
+			})
+			resDSL = func() {
+				CacheControl(time.Minute, "public")
+				Metadata("cache:ttl", "10s")
+			}
+			actionDSL = func() {
+				Routing(GET(""))
+				Stream("application/vnd.event")
+			}
+		})
+
+		It("does not apply to the action", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.EffectiveCacheControl()).Should(BeNil())
+			Ω(action.CacheTTL()).Should(BeZero())
+		})
+
+		Context("that defines its own", func() {
+			BeforeEach(func() {
+				actionDSL = func() {
+					Routing(GET(""))
+					Stream("application/vnd.event")
+					CacheControl(time.Minute)
+				}
+			})
+
+			It("fails", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("CacheControl cannot be used on streaming actions"))
+			})
+		})
+	})
+
+	Context("with an unknown directive", func() {
+		BeforeEach(func() {
+			actionDSL = func() {
+				Routing(GET(""))
+				CacheControl(time.Minute, "forever")
+			}
+		})
+
+		It("fails", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`unknown directive "forever"`))
+		})
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
@@ -127,6 +128,49 @@ func Description(d string) {
 	}
 }
 
+// Deprecated can be used in: Resource, Action, Attribute
+//
+// Deprecated marks the definition as deprecated. The first argument explains why and what should
+// be used instead. The optional second argument is the date after which the definition may be
+// removed formatted as "2006-01-02" or as a RFC 3339 timestamp. Deprecated actions (including the
+// actions of deprecated resources) and attributes are flagged in the generated documentation,
+// handlers of deprecated actions set the "Deprecation" and "Sunset" response headers and the
+// generated clients log a warning when calling them. Example:
+//
+//	Action("show", func() {
+//		Deprecated("use the get action instead", "2020-06-30")
+//		Routing(GET("/:id"))
+//		Response(OK)
+//	})
+//
+func Deprecated(reason string, sunset ...string) {
+	if len(sunset) > 1 {
+		dslengine.ReportError("too many arguments given to Deprecated")
+		return
+	}
+	dep := &design.DeprecationDefinition{Reason: reason}
+	if len(sunset) == 1 {
+		t, err := time.Parse("2006-01-02", sunset[0])
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, sunset[0]); err != nil {
+				dslengine.ReportError("invalid sunset date %#v, must be formatted as 2006-01-02 or RFC 3339", sunset[0])
+				return
+			}
+		}
+		dep.Sunset = t
+	}
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ResourceDefinition:
+		def.Deprecation = dep
+	case *design.ActionDefinition:
+		def.Deprecation = dep
+	case *design.AttributeDefinition:
+		def.Deprecation = dep
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
 // BasePath can used in: API, Resource
 //
 // BasePath defines the API base path, i.e. the common path prefix to all the API actions.
@@ -265,6 +309,60 @@ func Host(host string) {
 	}
 }
 
+// HealthChecks can be used in: API
+//
+// HealthChecks documents the liveness and readiness endpoints mounted by the health package.
+// The endpoints are described in the generated swagger specification, no code is generated for
+// them. HealthChecks accepts optionally the paths of the liveness and readiness endpoints which
+// default to "/livez" and "/readyz" respectively. Example:
+//
+//	API("cellar", func() {
+//		HealthChecks("/health/live", "/health/ready")
+//	})
+//
+func HealthChecks(paths ...string) {
+	if len(paths) > 2 {
+		dslengine.ReportError("too many arguments given to HealthChecks")
+		return
+	}
+	h := &design.HealthDefinition{LivePath: "/livez", ReadyPath: "/readyz"}
+	if len(paths) > 0 {
+		h.LivePath = paths[0]
+	}
+	if len(paths) > 1 {
+		h.ReadyPath = paths[1]
+	}
+	for _, p := range []string{h.LivePath, h.ReadyPath} {
+		if !strings.HasPrefix(p, "/") {
+			dslengine.ReportError("invalid health check path %#v, must start with /", p)
+			return
+		}
+	}
+	if h.LivePath == h.ReadyPath {
+		dslengine.ReportError("liveness and readiness endpoints must use different paths")
+		return
+	}
+	if a, ok := apiDefinition(); ok {
+		a.Health = h
+	}
+}
+
+// ProblemDetails can be used in: API
+//
+// ProblemDetails documents that the service renders error responses as RFC 7807 problem details
+// documents (application/problem+json). The generated swagger specification describes the error
+// responses accordingly and the generated main sets the service ProblemDetails field. Example:
+//
+//	API("cellar", func() {
+//		ProblemDetails()
+//	})
+//
+func ProblemDetails() {
+	if a, ok := apiDefinition(); ok {
+		a.ProblemDetails = true
+	}
+}
+
 // Scheme can be used in: API, Resource, Action
 //
 // Scheme sets the API URL schemes.
@@ -377,32 +475,60 @@ func URL(url string) {
 	}
 }
 
-// Consumes can be used in: API
+// Consumes can be used in: API, Resource, Action
 //
 // Consumes adds a MIME type to the list of MIME types the APIs supports when accepting requests.
 // Consumes may also specify the path of the decoding package.
 // The package must expose a DecoderFactory method that returns an object which implements
-// goa.DecoderFactory.
+// goa.DecoderFactory. Consumes used in a Resource or Action overrides the MIME types listed in
+// the API for the resource or action requests, for example:
+//
+//	Action("import", func() {
+//		Routing(POST("/import"))
+//		Consumes("text/csv", func() {
+//			Package("github.com/acme/encoding/csv")
+//		})
+//		Payload(ImportPayload)
+//	})
 func Consumes(args ...interface{}) {
-	if a, ok := apiDefinition(); ok {
-		if def := buildEncodingDefinition(false, args...); def != nil {
-			a.Consumes = append(a.Consumes, def)
-		}
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		def.Consumes = appendEncodingDefinition(def.Consumes, false, args...)
+	case *design.ResourceDefinition:
+		def.Consumes = appendEncodingDefinition(def.Consumes, false, args...)
+	case *design.ActionDefinition:
+		def.Consumes = appendEncodingDefinition(def.Consumes, false, args...)
+	default:
+		dslengine.IncompatibleDSL()
 	}
 }
 
-// Produces can be used in: API
+// Produces can be used in: API, Resource, Action
 //
 // Produces adds a MIME type to the list of MIME types the APIs can encode responses with.
 // Produces may also specify the path of the encoding package.
 // The package must expose a EncoderFactory method that returns an object which implements
-// goa.EncoderFactory.
+// goa.EncoderFactory. Produces used in a Resource or Action overrides the MIME types listed in
+// the API for the resource or action responses.
 func Produces(args ...interface{}) {
-	if a, ok := apiDefinition(); ok {
-		if def := buildEncodingDefinition(true, args...); def != nil {
-			a.Produces = append(a.Produces, def)
-		}
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		def.Produces = appendEncodingDefinition(def.Produces, true, args...)
+	case *design.ResourceDefinition:
+		def.Produces = appendEncodingDefinition(def.Produces, true, args...)
+	case *design.ActionDefinition:
+		def.Produces = appendEncodingDefinition(def.Produces, true, args...)
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// appendEncodingDefinition builds up an encoding definition and appends it to defs.
+func appendEncodingDefinition(defs []*design.EncodingDefinition, encoding bool, args ...interface{}) []*design.EncodingDefinition {
+	if def := buildEncodingDefinition(encoding, args...); def != nil {
+		defs = append(defs, def)
 	}
+	return defs
 }
 
 // buildEncodingDefinition builds up an encoding definition.
@@ -56,6 +56,19 @@ var _ = Describe("API", func() {
 		})
 	})
 
+	Context("with HealthChecks using the same path twice", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				HealthChecks("/health", "/health")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
 	Context("with valid DSL", func() {
 		JustBeforeEach(func() {
 			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
@@ -214,6 +227,30 @@ var _ = Describe("API", func() {
 			})
 		})
 
+		Context("with HealthChecks", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					HealthChecks("/health/live")
+				}
+			})
+
+			It("sets the health check paths", func() {
+				Ω(Design.Health).Should(Equal(&HealthDefinition{LivePath: "/health/live", ReadyPath: "/readyz"}))
+			})
+		})
+
+		Context("with ProblemDetails", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					ProblemDetails()
+				}
+			})
+
+			It("enables problem details error responses", func() {
+				Ω(Design.ProblemDetails).Should(BeTrue())
+			})
+		})
+
 		Context("with Params", func() {
 			const param1Name = "accountID"
 			const param1Type = Integer
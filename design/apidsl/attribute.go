@@ -329,6 +329,24 @@ func ReadOnly() {
 	}
 }
 
+// Sensitive can be used in: Attribute
+//
+// Sensitive marks the attribute as holding sensitive data such as a password or a card number. The
+// generated payload, media and user types implement goa.Redactor: their Redact method returns a
+// copy of the value where the sensitive strings are replaced with "<redacted>" and the other
+// sensitive values are cleared. The logging middlewares log the redacted values and the generated
+// clients mask the sensitive fields of the JSON bodies they dump. Sensitive is equivalent to
+// Metadata("log:redact"):
+//
+//	Attribute("password", String, func() {
+//		Sensitive()
+//	})
+func Sensitive() {
+	if a, ok := attributeDefinition(); ok {
+		a.SetSensitive()
+	}
+}
+
 // NoExample can be used in: Attribute, Header, Param, HashOf, ArrayOf
 //
 // NoExample sets the example of an attribute to be blank for the documentation. It is used when
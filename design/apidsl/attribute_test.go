@@ -212,6 +212,21 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with a name and a DSL defining a sensitive attribute", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() { Sensitive() }
+		})
+
+		It("produces a sensitive attribute", func() {
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].IsSensitive()).Should(BeTrue())
+			Ω(o[name].Metadata).Should(HaveKey("log:redact"))
+			Ω(parent.HasSensitive()).Should(BeTrue())
+		})
+	})
+
 	Context("with a name and a DSL defining an enum validation", func() {
 		BeforeEach(func() {
 			name = "foo"
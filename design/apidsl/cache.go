@@ -0,0 +1,43 @@
+package apidsl
+
+import (
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// CacheControl can be used in: Resource, Action
+//
+// CacheControl sets the "Cache-Control" header of the successful responses of the action. The
+// first argument is the time the responses remain fresh (the "max-age" directive), the optional
+// other arguments list additional directives: "public", "private", "no-cache", "no-store",
+// "no-transform", "must-revalidate", "proxy-revalidate" or "immutable". When defined on a
+// Resource it applies to all the resource actions that use the GET method unless overridden by
+// individual actions.
+//
+// The generated controllers set the header and cache the encoded responses for the max age
+// unless the directives prevent shared caches from storing them, see the middleware/cache
+// package. The "cache:ttl" metadata overrides the time the service caches the responses:
+//
+//	Resource("bottle", func() {
+//		CacheControl(time.Minute, "public")
+//		Action("show", func() {
+//			Routing(GET("/:id"))
+//			Metadata("cache:ttl", "10s")
+//		})
+//	})
+//
+func CacheControl(maxAge time.Duration, directives ...string) {
+	def := &design.CacheControlDefinition{MaxAge: maxAge, Directives: directives}
+	switch parent := dslengine.CurrentDefinition().(type) {
+	case *design.ResourceDefinition:
+		def.Parent = parent
+		parent.CacheControl = def
+	case *design.ActionDefinition:
+		def.Parent = parent
+		parent.CacheControl = def
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
@@ -0,0 +1,56 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// ETag can be used in: Resource, Action
+//
+// ETag makes the action compute the strong entity tag of its responses. When defined on a
+// Resource it applies to all the resource actions except the streaming actions unless overridden
+// by individual actions.
+//
+// The generated controllers set the "ETag" header of the successful responses to GET and HEAD
+// requests and reply with 304 Not Modified when the "If-None-Match" or "If-Modified-Since"
+// request headers show that the client already has the response, see the middleware package
+// ETag function. Actions that use the PUT, PATCH or DELETE methods accept the "If-Match" request
+// header, their contexts expose a CheckIfMatch method that returns a 412 Precondition Failed
+// error if the header does not match the current representation of the resource.
+// Example:
+//
+//	Resource("bottle", func() {
+//		ETag()
+//		Action("show", func() {
+//			Routing(GET("/:id"))
+//		})
+//		Action("update", func() {
+//			Routing(PUT("/:id"))
+//		})
+//	})
+//
+func ETag() {
+	etag(false)
+}
+
+// WeakETag can be used in: Resource, Action
+//
+// WeakETag behaves like ETag but makes the action compute weak entity tags. Weak entity tags
+// cannot be used with the "If-Match" header so WeakETag cannot be applied to actions that use
+// the PUT, PATCH or DELETE methods.
+func WeakETag() {
+	etag(true)
+}
+
+// etag implements ETag and WeakETag.
+func etag(weak bool) {
+	def := &design.ETagDefinition{Weak: weak}
+	switch parent := dslengine.CurrentDefinition().(type) {
+	case *design.ResourceDefinition:
+		parent.ETag = def
+	case *design.ActionDefinition:
+		parent.ETag = def
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
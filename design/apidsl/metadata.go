@@ -11,8 +11,7 @@ import (
 // slice of strings so that multiple invocation of the Metadata function on the same target using
 // the same key builds up the slice.
 //
-// While keys can have any value the following names are handled explicitly by goagen when set on
-// attributes.
+// While keys can have any value the following names are handled explicitly by goagen.
 //
 // `struct:field:name`: overrides the Go struct field name generated by default by goagen.
 // Applicable to attributes only.
@@ -35,6 +34,16 @@ import (
 //        Metadata("struct:tag:json", "myName,omitempty")
 //        Metadata("struct:tag:xml", "myName,attr")
 //
+// `cache:ttl`: sets the time the service caches the responses of the action, see CacheControl.
+// Applicable to resources and actions.
+//
+//        Metadata("cache:ttl", "30s")
+//
+// `log:redact`: marks the attribute as sensitive, see Sensitive.
+// Applicable to attributes only.
+//
+//        Metadata("log:redact")
+//
 // `swagger:generate`: specifies whether Swagger specification should be generated. Defaults to
 // true.
 // Applicable to resources, actions and file servers.
@@ -0,0 +1,67 @@
+package apidsl
+
+import (
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// RateLimit can be used in: API, Resource, Action
+//
+// RateLimit defines the maximum number of requests a client may make during the given period.
+// The optional third argument identifies the clients, it is either the value returned by ByIP
+// (the default) or by ByHeader. When defined on a Resource the limit is shared by all the resource
+// actions unless overridden by individual actions. When defined at the API level it is shared by
+// all the API actions unless overridden by resources or actions, following the same logic.
+//
+// The generated controllers enforce the limits with the middleware/ratelimit package: requests
+// that exceed the limit get a 429 response with the "Retry-After" and "RateLimit-*" headers.
+// Example:
+//
+//	Resource("bottle", func() {
+//		RateLimit(100, time.Minute, ByHeader("X-API-Key"))
+//		Action("show", func() {
+//			RateLimit(10, time.Second)
+//			Routing(GET("/:id"))
+//		})
+//	})
+//
+func RateLimit(limit int, period time.Duration, key ...*design.RateLimitKeyDefinition) {
+	if len(key) > 1 {
+		dslengine.ReportError("too many arguments given to RateLimit")
+		return
+	}
+	def := &design.RateLimitDefinition{Limit: limit, Period: period, Key: ByIP()}
+	if len(key) == 1 {
+		def.Key = key[0]
+	}
+	switch parent := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		parent.RateLimit = def
+	case *design.ResourceDefinition:
+		def.Scope = parent.Name
+		parent.RateLimit = def
+	case *design.ActionDefinition:
+		def.Scope = parent.Parent.Name + "." + parent.Name
+		parent.RateLimit = def
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// ByIP can be used as the last argument of RateLimit to apply the limit to each client IP
+// address. The address is the request remote address, use the ratelimit package ByForwardedFor
+// key function in a service middleware for services running behind proxies.
+func ByIP() *design.RateLimitKeyDefinition {
+	return &design.RateLimitKeyDefinition{Kind: design.RateLimitKeyIP}
+}
+
+// ByHeader can be used as the last argument of RateLimit to apply the limit to each value of the
+// given request header, for example:
+//
+//	RateLimit(1000, time.Hour, ByHeader("X-API-Key"))
+//
+func ByHeader(name string) *design.RateLimitKeyDefinition {
+	return &design.RateLimitKeyDefinition{Kind: design.RateLimitKeyHeader, Name: name}
+}
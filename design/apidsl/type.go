@@ -169,6 +169,51 @@ func HashOf(k, v interface{}, dsls ...func()) *design.Hash {
 	return &design.Hash{KeyType: &kat, ElemType: &vat}
 }
 
+// OneOf creates a union type from the attributes defined in the given DSL. A value of a union
+// type holds exactly one of the alternatives. Each alternative is identified by its attribute
+// name which is used as the single key of the JSON object representing the value. The result
+// can be used anywhere a type can. Example:
+//
+//	var EmailChannel = Type("EmailChannel", func() {
+//		Attribute("address", String, func() {
+//			Format("email")
+//		})
+//		Required("address")
+//	})
+//
+//	var SMSChannel = Type("SMSChannel", func() {
+//		Attribute("phone", String)
+//		Required("phone")
+//	})
+//
+//	var Notification = Type("Notification", func() {
+//		Attribute("message", String)
+//		Attribute("channel", OneOf(func() {
+//			Attribute("email", EmailChannel)
+//			Attribute("sms", SMSChannel)
+//		}))
+//		Required("message", "channel")
+//	})
+//
+// The alternatives may not be marked as required and may not define default values.
+func OneOf(dsl func()) *design.Union {
+	at := design.AttributeDefinition{Type: make(design.Object)}
+	dslengine.Execute(dsl, &at)
+	alternatives := at.Type.ToObject()
+	if len(alternatives) == 0 {
+		dslengine.ReportError("OneOf: union must define at least one alternative")
+	}
+	if at.Validation != nil && len(at.Validation.Required) > 0 {
+		dslengine.ReportError("OneOf: union alternatives cannot be required")
+	}
+	for n, alt := range alternatives {
+		if alt.DefaultValue != nil {
+			dslengine.ReportError("OneOf: union alternative %#v cannot have a default value", n)
+		}
+	}
+	return &design.Union{Alternatives: alternatives}
+}
+
 func resolveType(v interface{}) design.DataType {
 	if t, ok := v.(design.DataType); ok {
 		return t
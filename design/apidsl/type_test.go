@@ -289,3 +289,66 @@ var _ = Describe("HashOf", func() {
 		})
 	})
 })
+
+var _ = Describe("OneOf", func() {
+	Context("used on a global variable", func() {
+		var (
+			email *UserTypeDefinition
+			sms   *UserTypeDefinition
+			un    *Union
+		)
+		BeforeEach(func() {
+			dslengine.Reset()
+			email = Type("email", func() {
+				Attribute("address")
+			})
+			sms = Type("sms", func() {
+				Attribute("phone")
+			})
+			un = OneOf(func() {
+				Attribute("email", email)
+				Attribute("sms", sms)
+			})
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		It("produces a union type", func() {
+			Ω(un).ShouldNot(BeNil())
+			Ω(un.Kind()).Should(Equal(UnionKind))
+			Ω(un.IsObject()).Should(BeTrue())
+			Ω(un.Names()).Should(Equal([]string{"email", "sms"}))
+			Ω(un.Alternatives["email"].Type).Should(Equal(email))
+			Ω(un.Alternatives["sms"].Type).Should(Equal(sms))
+		})
+	})
+
+	Context("with required alternatives", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			OneOf(func() {
+				Attribute("email", String)
+				Required("email")
+			})
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with no alternative", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			OneOf(func() {})
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
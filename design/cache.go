@@ -0,0 +1,180 @@
+package design
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa/dslengine"
+)
+
+// CacheTTLMetadata is the name of the metadata that sets the time the responses of an action are
+// cached by the service, for example:
+//
+//	Metadata("cache:ttl", "30s")
+//
+const CacheTTLMetadata = "cache:ttl"
+
+// CacheControlDefinition describes the "Cache-Control" header of the responses of an action.
+type CacheControlDefinition struct {
+	// MaxAge is the time the response remains fresh.
+	MaxAge time.Duration
+	// Directives lists the other response directives, e.g. "public" or "must-revalidate".
+	Directives []string
+	// Parent is the resource or action that defines the header.
+	Parent dslengine.Definition
+}
+
+// cacheDirectives lists the response directives accepted by CacheControl.
+var cacheDirectives = map[string]bool{
+	"public":           true,
+	"private":          true,
+	"no-cache":         true,
+	"no-store":         true,
+	"no-transform":     true,
+	"must-revalidate":  true,
+	"proxy-revalidate": true,
+	"immutable":        true,
+}
+
+// Context returns the generic definition name used in error messages.
+func (c *CacheControlDefinition) Context() string {
+	if c.Parent == nil {
+		return "cache control"
+	}
+	return "cache control of " + c.Parent.Context()
+}
+
+// Validate checks the max age is not negative and the directives are known.
+func (c *CacheControlDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if c.MaxAge < 0 {
+		verr.Add(c, "max age cannot be negative, got %s", c.MaxAge)
+	}
+	for _, d := range c.Directives {
+		if !cacheDirectives[d] {
+			verr.Add(c, "unknown directive %q", d)
+		}
+	}
+	return verr.AsError()
+}
+
+// Value returns the value of the "Cache-Control" header, e.g. "max-age=60, public".
+func (c *CacheControlDefinition) Value() string {
+	var values []string
+	if !c.has("no-store") {
+		values = append(values, fmt.Sprintf("max-age=%d", int64(c.MaxAge/time.Second)))
+	}
+	return strings.Join(append(values, c.Directives...), ", ")
+}
+
+// Shared returns true if the responses may be stored by shared caches, that is if the
+// directives do not include "private", "no-cache" or "no-store".
+func (c *CacheControlDefinition) Shared() bool {
+	return !c.has("private") && !c.has("no-cache") && !c.has("no-store")
+}
+
+// has returns true if the given directive is set.
+func (c *CacheControlDefinition) has(directive string) bool {
+	for _, d := range c.Directives {
+		if d == directive {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectiveCacheControl returns the "Cache-Control" header definition that applies to the action,
+// either defined on the action or inherited from its resource, nil if there is none. Only the
+// actions that use the GET method inherit the resource definition, streaming actions never have
+// one.
+func (a *ActionDefinition) EffectiveCacheControl() *CacheControlDefinition {
+	if a.Stream != nil {
+		return nil
+	}
+	if a.CacheControl != nil {
+		return a.CacheControl
+	}
+	if a.Parent != nil && a.hasVerb("GET") {
+		return a.Parent.CacheControl
+	}
+	return nil
+}
+
+// CacheTTL returns the time the responses of the action are cached by the service. The value is
+// read from the CacheTTLMetadata metadata of the action or of its resource if any, otherwise it is
+// the max age of the "Cache-Control" header if shared caches may store the responses. CacheTTL
+// returns 0 if the responses are not cached, if the action does not use the GET method or if it
+// streams its responses.
+func (a *ActionDefinition) CacheTTL() time.Duration {
+	if !a.hasVerb("GET") || a.Stream != nil {
+		return 0
+	}
+	if ttl, ok := a.cacheTTLMetadata(); ok {
+		d, _ := time.ParseDuration(ttl)
+		return d
+	}
+	if cc := a.EffectiveCacheControl(); cc != nil && cc.Shared() {
+		return cc.MaxAge
+	}
+	return 0
+}
+
+// cacheTTLMetadata returns the value of the CacheTTLMetadata metadata of the action or of its
+// resource.
+func (a *ActionDefinition) cacheTTLMetadata() (string, bool) {
+	if v, ok := a.Metadata[CacheTTLMetadata]; ok && len(v) > 0 {
+		return v[0], true
+	}
+	if a.Parent != nil {
+		if v, ok := a.Parent.Metadata[CacheTTLMetadata]; ok && len(v) > 0 {
+			return v[0], true
+		}
+	}
+	return "", false
+}
+
+// validateCache checks the action cache control and TTL metadata and that streaming actions do not
+// define a cache control.
+func (a *ActionDefinition) validateCache() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if a.CacheControl != nil && a.Stream != nil {
+		verr.Add(a, "CacheControl cannot be used on streaming actions")
+	}
+	if a.CacheControl != nil {
+		if !a.hasVerb("GET") {
+			verr.Add(a, "CacheControl can only be used on actions that use the GET method")
+		}
+		verr.Merge(a.CacheControl.Validate())
+	}
+	if ttl, ok := a.cacheTTLMetadata(); ok {
+		if d, err := time.ParseDuration(ttl); err != nil || d < 0 {
+			verr.Add(a, "invalid %s metadata %q, must be a positive duration such as \"30s\"", CacheTTLMetadata, ttl)
+		}
+	}
+	return verr.AsError()
+}
+
+// initCacheHeaders documents the "Cache-Control" header on the success responses of the actions
+// that define it.
+func (a *ActionDefinition) initCacheHeaders() {
+	cc := a.EffectiveCacheControl()
+	if cc == nil {
+		return
+	}
+	for _, resp := range a.Responses {
+		if resp.Status < 200 || resp.Status > 299 {
+			continue
+		}
+		if resp.Headers == nil {
+			resp.Headers = &AttributeDefinition{Type: Object{}}
+		}
+		headers := resp.Headers.Type.ToObject()
+		if _, ok := headers["Cache-Control"]; !ok {
+			headers["Cache-Control"] = &AttributeDefinition{
+				Type:        String,
+				Description: fmt.Sprintf("Caching directives as defined in RFC 7234: %s", cc.Value()),
+			}
+		}
+	}
+}
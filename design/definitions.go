@@ -7,6 +7,7 @@ import (
 	"path"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/dimfeld/httppath"
 	"github.com/goadesign/goa/dslengine"
@@ -74,11 +75,28 @@ type (
 		Security *SecurityDefinition
 		// NoExamples indicates whether to bypass automatic example generation.
 		NoExamples bool
+		// Health describes the liveness and readiness endpoints mounted by the health
+		// package if any.
+		Health *HealthDefinition
+		// ProblemDetails indicates whether the service renders errors as RFC 7807 problem
+		// details documents.
+		ProblemDetails bool
+		// RateLimit describes the limit that applies to all the API requests unless
+		// overridden by a resource or an action.
+		RateLimit *RateLimitDefinition
 
 		// rand is the random generator used to generate examples.
 		rand *RandomGenerator
 	}
 
+	// HealthDefinition contains the paths of the liveness and readiness endpoints.
+	HealthDefinition struct {
+		// LivePath is the path of the liveness endpoint.
+		LivePath string
+		// ReadyPath is the path of the readiness endpoint.
+		ReadyPath string
+	}
+
 	// ContactDefinition contains the API contact information.
 	ContactDefinition struct {
 		// Name of the contact person/organization
@@ -144,6 +162,23 @@ type (
 		// Security defines security requirements for the Resource,
 		// for actions that don't define one themselves.
 		Security *SecurityDefinition
+		// Deprecation describes the deprecation of all the resource actions if any.
+		Deprecation *DeprecationDefinition
+		// Consumes lists the mime types supported by the resource actions, overrides the API
+		// Consumes if not empty.
+		Consumes []*EncodingDefinition
+		// Produces lists the mime types generated by the resource actions, overrides the API
+		// Produces if not empty.
+		Produces []*EncodingDefinition
+		// RateLimit describes the limit shared by the resource actions, overrides the API
+		// RateLimit if set.
+		RateLimit *RateLimitDefinition
+		// ETag describes the entity tags computed for the responses of the resource actions
+		// if any.
+		ETag *ETagDefinition
+		// CacheControl describes the "Cache-Control" header of the responses of the
+		// resource actions that use the GET method if any.
+		CacheControl *CacheControlDefinition
 	}
 
 	// CORSDefinition contains the definition for a specific origin CORS policy.
@@ -253,6 +288,46 @@ type (
 		Metadata dslengine.MetadataDefinition
 		// Security defines security requirements for the action
 		Security *SecurityDefinition
+		// Stream is the media type of the server-sent events sent by the action if any.
+		Stream *MediaTypeDefinition
+		// Pagination describes the query string parameters used to paginate the action
+		// results if any.
+		Pagination *PaginationDefinition
+		// Deprecation describes the deprecation of the action if any.
+		Deprecation *DeprecationDefinition
+		// Consumes lists the mime types supported by the action, overrides the resource and
+		// API Consumes if not empty.
+		Consumes []*EncodingDefinition
+		// Produces lists the mime types generated by the action, overrides the resource and
+		// API Produces if not empty.
+		Produces []*EncodingDefinition
+		// RateLimit describes the limit that applies to the action, overrides the resource
+		// and API RateLimit if set.
+		RateLimit *RateLimitDefinition
+		// ETag describes the entity tags computed for the action responses, overrides the
+		// resource ETag if set.
+		ETag *ETagDefinition
+		// CacheControl describes the "Cache-Control" header of the action responses,
+		// overrides the resource CacheControl if set.
+		CacheControl *CacheControlDefinition
+	}
+
+	// DeprecationDefinition describes the deprecation of a resource, an action or an attribute.
+	DeprecationDefinition struct {
+		// Reason explains why the element is deprecated and what should be used instead.
+		Reason string
+		// Sunset is the date after which the element may be removed, zero if not set.
+		Sunset time.Time
+	}
+
+	// PaginationDefinition describes the query string parameters used to paginate the results
+	// of an action.
+	PaginationDefinition struct {
+		// CursorParam is the name of the parameter that identifies the page to retrieve.
+		CursorParam string
+		// LimitParam is the name of the parameter that sets the maximum number of items per
+		// page.
+		LimitParam string
 	}
 
 	// FileServerDefinition defines an endpoint that servers static assets.
@@ -335,6 +410,8 @@ type (
 		NonZeroAttributes map[string]bool
 		// DSLFunc contains the initialization DSL. This is used for user types.
 		DSLFunc func()
+		// Deprecation describes the deprecation of the attribute if any.
+		Deprecation *DeprecationDefinition
 	}
 
 	// ContainerDefinition defines a generic container definition that contains attributes.
@@ -612,6 +689,15 @@ func (a *APIDefinition) MediaTypeWithIdentifier(id string) *MediaTypeDefinition
 	return nil
 }
 
+// ErrorResponseMedia returns the media type of the error responses rendered by the service:
+// ProblemMedia if the API renders problem details documents, ErrorMedia otherwise.
+func (a *APIDefinition) ErrorResponseMedia() *MediaTypeDefinition {
+	if a.ProblemDetails {
+		return ProblemMedia
+	}
+	return ErrorMedia
+}
+
 // IterateResources calls the given iterator passing in each resource sorted in alphabetical order.
 // Iteration stops if an iterator returns an error and in this case IterateResources returns that
 // error.
@@ -1164,6 +1250,60 @@ func (a *AttributeDefinition) IsReadOnly() bool {
 	return false
 }
 
+// SetSensitive marks the attribute as sensitive by setting its "log:redact" metadata.
+func (a *AttributeDefinition) SetSensitive() {
+	if a.Metadata == nil {
+		a.Metadata = map[string][]string{}
+	}
+	a.Metadata["log:redact"] = nil
+}
+
+// IsSensitive returns true if the attribute holds sensitive data that must not be logged, that
+// is if it has the "log:redact" metadata (set using the SetSensitive method).
+func (a *AttributeDefinition) IsSensitive() bool {
+	_, ok := a.Metadata["log:redact"]
+	return ok
+}
+
+// HasSensitive returns true if the attribute or any of its child attributes, recursively, is
+// sensitive.
+func (a *AttributeDefinition) HasSensitive() bool {
+	return a.hasSensitive(make(map[*AttributeDefinition]bool))
+}
+
+func (a *AttributeDefinition) hasSensitive(seen map[*AttributeDefinition]bool) bool {
+	if a.IsSensitive() {
+		return true
+	}
+	if seen[a] {
+		return false
+	}
+	seen[a] = true
+	switch actual := a.Type.(type) {
+	case Object:
+		for _, catt := range actual {
+			if catt.hasSensitive(seen) {
+				return true
+			}
+		}
+	case *Union:
+		for _, catt := range actual.Alternatives {
+			if catt.hasSensitive(seen) {
+				return true
+			}
+		}
+	case *Array:
+		return actual.ElemType.hasSensitive(seen)
+	case *Hash:
+		return actual.KeyType.hasSensitive(seen) || actual.ElemType.hasSensitive(seen)
+	case *UserTypeDefinition:
+		return actual.AttributeDefinition.hasSensitive(seen)
+	case *MediaTypeDefinition:
+		return actual.AttributeDefinition.hasSensitive(seen)
+	}
+	return false
+}
+
 func (a *AttributeDefinition) arrayExample(rand *RandomGenerator, seen []string) interface{} {
 	ary := a.Type.ToArray()
 	ln := newExampleGenerator(a, rand).ExampleLength()
@@ -1549,6 +1689,63 @@ func (a *ActionDefinition) CanonicalScheme() string {
 	return "http"
 }
 
+// EffectiveDeprecation returns the deprecation that applies to the action, either defined on
+// the action or inherited from its resource, nil if the action is not deprecated.
+func (a *ActionDefinition) EffectiveDeprecation() *DeprecationDefinition {
+	if a.Deprecation != nil {
+		return a.Deprecation
+	}
+	if a.Parent != nil {
+		return a.Parent.Deprecation
+	}
+	return nil
+}
+
+// EffectiveConsumes returns the decoding definitions that apply to the action, either defined
+// on the action, its resource or the API.
+func (a *ActionDefinition) EffectiveConsumes() []*EncodingDefinition {
+	if len(a.Consumes) > 0 {
+		return a.Consumes
+	}
+	if a.Parent != nil && len(a.Parent.Consumes) > 0 {
+		return a.Parent.Consumes
+	}
+	return Design.Consumes
+}
+
+// EffectiveProduces returns the encoding definitions that apply to the action, either defined
+// on the action, its resource or the API.
+func (a *ActionDefinition) EffectiveProduces() []*EncodingDefinition {
+	if len(a.Produces) > 0 {
+		return a.Produces
+	}
+	if a.Parent != nil && len(a.Parent.Produces) > 0 {
+		return a.Parent.Produces
+	}
+	return Design.Produces
+}
+
+// OverridesConsumes returns true if the action or its resource define the mime types the action
+// consumes instead of relying on the API definitions.
+func (a *ActionDefinition) OverridesConsumes() bool {
+	return len(a.Consumes) > 0 || a.Parent != nil && len(a.Parent.Consumes) > 0
+}
+
+// OverridesProduces returns true if the action or its resource define the mime types the action
+// produces instead of relying on the API definitions.
+func (a *ActionDefinition) OverridesProduces() bool {
+	return len(a.Produces) > 0 || a.Parent != nil && len(a.Parent.Produces) > 0
+}
+
+// SunsetHeader returns the value of the "Sunset" HTTP header (RFC 8594) corresponding to the
+// deprecation sunset date, an empty string if there is none.
+func (d *DeprecationDefinition) SunsetHeader() string {
+	if d.Sunset.IsZero() {
+		return ""
+	}
+	return d.Sunset.UTC().Format(http.TimeFormat)
+}
+
 // EffectiveSchemes return the URL schemes that apply to the action. Looks recursively into action
 // resource, parent resources and API.
 func (a *ActionDefinition) EffectiveSchemes() []string {
@@ -1605,6 +1802,9 @@ func (a *ActionDefinition) Finalize() {
 	a.mergeResponses()
 	a.initImplicitParams()
 	a.initQueryParams()
+	a.initPaginationHeaders()
+	a.initETagHeaders()
+	a.initCacheHeaders()
 }
 
 // UserTypes returns all the user types used by the action payload and parameters.
@@ -1759,6 +1959,29 @@ func (a *ActionDefinition) initQueryParams() {
 	}
 }
 
+// initPaginationHeaders documents the "Link" header on the success responses of paginated
+// actions.
+func (a *ActionDefinition) initPaginationHeaders() {
+	if a.Pagination == nil {
+		return
+	}
+	for _, resp := range a.Responses {
+		if resp.Status < 200 || resp.Status > 299 {
+			continue
+		}
+		if resp.Headers == nil {
+			resp.Headers = &AttributeDefinition{Type: Object{}}
+		}
+		headers := resp.Headers.Type.ToObject()
+		if _, ok := headers["Link"]; !ok {
+			headers["Link"] = &AttributeDefinition{
+				Type:        String,
+				Description: "Links to the next and previous pages of results as defined in RFC 8288",
+			}
+		}
+	}
+}
+
 // Context returns the generic definition name used in error messages.
 func (f *FileServerDefinition) Context() string {
 	suffix := fmt.Sprintf("file server %s", f.FilePath)
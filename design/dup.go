@@ -50,6 +50,7 @@ func (d *dupper) DupAttribute(att *AttributeDefinition) *AttributeDefinition {
 		View:              att.View,
 		DSLFunc:           att.DSLFunc,
 		Example:           att.Example,
+		Deprecation:       att.Deprecation,
 	}
 	return &dup
 }
@@ -67,6 +68,12 @@ func (d *dupper) DupType(t DataType) DataType {
 			res[n] = d.DupAttribute(att)
 		}
 		return res
+	case *Union:
+		alts := make(Object, len(actual.Alternatives))
+		for n, att := range actual.Alternatives {
+			alts[n] = d.DupAttribute(att)
+		}
+		return &Union{Alternatives: alts}
 	case *Hash:
 		return &Hash{
 			KeyType:  d.DupAttribute(actual.KeyType),
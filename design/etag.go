@@ -0,0 +1,108 @@
+package design
+
+import "github.com/goadesign/goa/dslengine"
+
+// ETagDefinition describes the entity tags computed for the responses of an action.
+type ETagDefinition struct {
+	// Weak is true if the entity tags are weak, false if they are strong.
+	Weak bool
+}
+
+// EffectiveETag returns the entity tag definition that applies to the action, either defined on
+// the action or inherited from its resource, nil if the action does not compute entity tags.
+// Streaming actions do not inherit the resource definition.
+func (a *ActionDefinition) EffectiveETag() *ETagDefinition {
+	if a.ETag != nil {
+		return a.ETag
+	}
+	if a.Parent != nil && a.Stream == nil {
+		return a.Parent.ETag
+	}
+	return nil
+}
+
+// HasPreconditions returns true if the action computes entity tags and has routes that modify
+// resources, that is routes that use the PUT, PATCH or DELETE methods. Such actions accept the
+// "If-Match" request header.
+func (a *ActionDefinition) HasPreconditions() bool {
+	return a.EffectiveETag() != nil && a.hasVerb("PUT", "PATCH", "DELETE")
+}
+
+// hasVerb returns true if one of the action routes uses one of the given HTTP methods.
+func (a *ActionDefinition) hasVerb(verbs ...string) bool {
+	for _, r := range a.Routes {
+		for _, v := range verbs {
+			if r.Verb == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateETag checks that streaming actions do not compute entity tags and that actions that
+// accept the "If-Match" header use strong tags.
+func (a *ActionDefinition) validateETag() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if a.ETag != nil && a.Stream != nil {
+		verr.Add(a, "ETag cannot be used on streaming actions")
+	}
+	if a.HasPreconditions() && a.EffectiveETag().Weak {
+		verr.Add(a, "If-Match preconditions require strong entity tags, use ETag instead of WeakETag")
+	}
+	return verr.AsError()
+}
+
+// initETagHeaders documents the "ETag" header on the success responses of actions that compute
+// entity tags, the "If-None-Match" request header and the 304 response of the actions that use
+// the GET method and the "If-Match" request header and the 412 response of the actions that
+// modify resources.
+func (a *ActionDefinition) initETagHeaders() {
+	if a.EffectiveETag() == nil {
+		return
+	}
+	for _, resp := range a.Responses {
+		if resp.Status < 200 || resp.Status > 299 {
+			continue
+		}
+		if resp.Headers == nil {
+			resp.Headers = &AttributeDefinition{Type: Object{}}
+		}
+		headers := resp.Headers.Type.ToObject()
+		if _, ok := headers["ETag"]; !ok {
+			headers["ETag"] = &AttributeDefinition{
+				Type:        String,
+				Description: "Entity tag of the response as defined in RFC 7232",
+			}
+		}
+	}
+	if a.hasVerb("GET") {
+		a.initConditional("If-None-Match", NotModified,
+			"Entity tags of the representations held by the client, the response is 304 Not Modified if one matches")
+	}
+	if a.HasPreconditions() {
+		a.initConditional("If-Match", PreconditionFailed,
+			"Entity tags one of which must match the current representation, the response is 412 Precondition Failed otherwise")
+	}
+}
+
+// initConditional adds the given optional request header and default response to the action
+// unless already defined.
+func (a *ActionDefinition) initConditional(header, response, description string) {
+	if a.Headers == nil {
+		a.Headers = &AttributeDefinition{Type: Object{}}
+	}
+	headers := a.Headers.Type.ToObject()
+	if _, ok := headers[header]; !ok {
+		headers[header] = &AttributeDefinition{Type: String, Description: description}
+	}
+	if _, ok := a.Responses[response]; ok {
+		return
+	}
+	if dr, ok := Design.DefaultResponses[response]; ok {
+		if a.Responses == nil {
+			a.Responses = make(map[string]*ResponseDefinition)
+		}
+		a.Responses[response] = dr.Dup()
+	}
+}
@@ -0,0 +1,79 @@
+package design
+
+import (
+	"time"
+
+	"github.com/goadesign/goa/dslengine"
+)
+
+// RateLimitKeyKind identifies how requests are grouped to compute rate limits.
+type RateLimitKeyKind int
+
+const (
+	// RateLimitKeyIP groups requests by client IP address.
+	RateLimitKeyIP RateLimitKeyKind = iota + 1
+	// RateLimitKeyHeader groups requests by the value of a request header.
+	RateLimitKeyHeader
+)
+
+type (
+	// RateLimitDefinition describes the maximum number of requests a client may make during a
+	// period of time.
+	RateLimitDefinition struct {
+		// Limit is the maximum number of requests allowed during Period.
+		Limit int
+		// Period is the duration over which Limit applies.
+		Period time.Duration
+		// Key identifies the clients the limit applies to.
+		Key *RateLimitKeyDefinition
+		// Scope identifies the requests that share the limit: empty for limits defined on
+		// the API, the resource name for limits defined on a resource and the resource
+		// name followed by a dot and the action name for limits defined on an action.
+		Scope string
+	}
+
+	// RateLimitKeyDefinition describes how requests are grouped to compute rate limits.
+	RateLimitKeyDefinition struct {
+		// Kind is the kind of key.
+		Kind RateLimitKeyKind
+		// Name is the name of the request header used with RateLimitKeyHeader.
+		Name string
+	}
+)
+
+// Context returns the generic definition name used in error messages.
+func (r *RateLimitDefinition) Context() string {
+	if r.Scope == "" {
+		return "API rate limit"
+	}
+	return "rate limit of " + r.Scope
+}
+
+// Validate checks the limit and period are positive and the key is complete.
+func (r *RateLimitDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if r.Limit <= 0 {
+		verr.Add(r, "limit must be greater than 0, got %d", r.Limit)
+	}
+	if r.Period <= 0 {
+		verr.Add(r, "period must be greater than 0, got %s", r.Period)
+	}
+	if r.Key == nil {
+		verr.Add(r, "missing key")
+	} else if r.Key.Kind == RateLimitKeyHeader && r.Key.Name == "" {
+		verr.Add(r, "key header name cannot be empty")
+	}
+	return verr.AsError()
+}
+
+// EffectiveRateLimit returns the rate limit that applies to the action, either defined on the
+// action or inherited from its resource or from the API, nil if the action is not rate limited.
+func (a *ActionDefinition) EffectiveRateLimit() *RateLimitDefinition {
+	if a.RateLimit != nil {
+		return a.RateLimit
+	}
+	if a.Parent != nil && a.Parent.RateLimit != nil {
+		return a.Parent.RateLimit
+	}
+	return Design.RateLimit
+}
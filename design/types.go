@@ -106,6 +106,14 @@ type (
 	// HashVal is the value of a hash used to specify the default value.
 	HashVal map[interface{}]interface{}
 
+	// Union is the type for values that hold exactly one of a set of named alternatives.
+	// A union value is represented as a JSON object with a single key, the key is the
+	// name of the alternative and the value is the alternative value.
+	Union struct {
+		// Alternatives lists the union alternatives indexed by name.
+		Alternatives Object
+	}
+
 	// UserTypeDefinition is the type for user defined types that are not media types
 	// (e.g. payload types).
 	UserTypeDefinition struct {
@@ -165,6 +173,8 @@ const (
 	MediaTypeKind
 	// FileKind represents a file.
 	FileKind
+	// UnionKind represents a value that holds exactly one of a set of alternatives.
+	UnionKind
 )
 
 const (
@@ -528,6 +538,65 @@ func (h *Hash) MakeMap(m map[interface{}]interface{}) interface{} {
 	return hash.Interface()
 }
 
+// Kind implements DataKind.
+func (u *Union) Kind() Kind { return UnionKind }
+
+// Name returns the type name.
+func (u *Union) Name() string { return "union" }
+
+// IsPrimitive returns false.
+func (u *Union) IsPrimitive() bool { return false }
+
+// HasAttributes returns true.
+func (u *Union) HasAttributes() bool { return true }
+
+// IsObject returns true, unions are represented as objects with a single key.
+func (u *Union) IsObject() bool { return true }
+
+// IsArray returns false.
+func (u *Union) IsArray() bool { return false }
+
+// IsHash returns false.
+func (u *Union) IsHash() bool { return false }
+
+// ToObject returns the union alternatives.
+func (u *Union) ToObject() Object { return u.Alternatives }
+
+// ToArray returns nil.
+func (u *Union) ToArray() *Array { return nil }
+
+// ToHash returns nil.
+func (u *Union) ToHash() *Hash { return nil }
+
+// CanHaveDefault returns false.
+func (u *Union) CanHaveDefault() bool { return false }
+
+// IsCompatible returns true if val is a map or a struct.
+func (u *Union) IsCompatible(val interface{}) bool {
+	k := reflect.TypeOf(val).Kind()
+	return k == reflect.Map || k == reflect.Struct
+}
+
+// GenerateExample returns a random value of one of the union alternatives.
+func (u *Union) GenerateExample(r *RandomGenerator, seen []string) interface{} {
+	names := u.Names()
+	if len(names) == 0 {
+		return nil
+	}
+	n := names[r.Int()%len(names)]
+	return map[string]interface{}{n: u.Alternatives[n].Type.GenerateExample(r, seen)}
+}
+
+// Names returns the names of the union alternatives sorted in alphabetical order.
+func (u *Union) Names() []string {
+	names := make([]string, 0, len(u.Alternatives))
+	for n := range u.Alternatives {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // AttributeIterator is the type of the function given to IterateAttributes.
 type AttributeIterator func(string, *AttributeDefinition) error
 
@@ -587,6 +656,8 @@ func UserTypes(dt DataType) map[string]*UserTypeDefinition {
 			return nil
 		}
 		return types
+	case *Union:
+		return UserTypes(actual.Alternatives)
 	case *UserTypeDefinition:
 		types := map[string]*UserTypeDefinition{actual.TypeName: actual}
 		actual.Walk(collect(types))
@@ -1061,6 +1132,12 @@ func walk(at *AttributeDefinition, walker func(*AttributeDefinition) error, seen
 				return err
 			}
 		}
+	case *Union:
+		for _, cat := range actual.Alternatives {
+			if err := walk(cat, walker, seen); err != nil {
+				return err
+			}
+		}
 	case *UserTypeDefinition:
 		return walkUt(actual)
 	case *MediaTypeDefinition:
@@ -1084,7 +1161,7 @@ func toReflectType(dtype DataType) reflect.Type {
 		return reflect.TypeOf("")
 	case DateTimeKind:
 		return reflect.TypeOf(time.Time{})
-	case ObjectKind, UserTypeKind, MediaTypeKind:
+	case ObjectKind, UserTypeKind, MediaTypeKind, UnionKind:
 		return reflect.TypeOf(map[string]interface{}{})
 	case ArrayKind:
 		return reflect.SliceOf(toReflectType(dtype.ToArray().ElemType.Type))
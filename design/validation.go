@@ -131,11 +131,9 @@ func (a *APIDefinition) Validate() error {
 		verr.Merge(r.Validate())
 		return nil
 	})
-	for _, dec := range a.Consumes {
-		verr.Merge(dec.Validate())
-	}
-	for _, enc := range a.Produces {
-		verr.Merge(enc.Validate())
+	validateEncodings(verr, a.Consumes, a.Produces)
+	if a.RateLimit != nil {
+		verr.Merge(a.RateLimit.Validate())
 	}
 
 	err := verr.AsError()
@@ -146,6 +144,16 @@ func (a *APIDefinition) Validate() error {
 	return err
 }
 
+// validateEncodings validates the given decoding and encoding definitions.
+func validateEncodings(verr *dslengine.ValidationErrors, consumes, produces []*EncodingDefinition) {
+	for _, dec := range consumes {
+		verr.Merge(dec.Validate())
+	}
+	for _, enc := range produces {
+		verr.Merge(enc.Validate())
+	}
+}
+
 func (a *APIDefinition) validateRoutes(verr *dslengine.ValidationErrors, routes []*routeInfo) {
 	for _, route := range routes {
 		for _, other := range routes {
@@ -228,6 +236,13 @@ func (r *ResourceDefinition) Validate() *dslengine.ValidationErrors {
 	for _, origin := range r.Origins {
 		verr.Merge(origin.Validate())
 	}
+	validateEncodings(verr, r.Consumes, r.Produces)
+	if r.RateLimit != nil {
+		verr.Merge(r.RateLimit.Validate())
+	}
+	if r.CacheControl != nil {
+		verr.Merge(r.CacheControl.Validate())
+	}
 	return verr.AsError()
 }
 
@@ -350,6 +365,14 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	if a.Parent == nil {
 		verr.Add(a, "missing parent resource")
 	}
+	verr.Merge(a.validateStream())
+	verr.Merge(a.validatePagination())
+	verr.Merge(a.validateETag())
+	verr.Merge(a.validateCache())
+	validateEncodings(verr, a.Consumes, a.Produces)
+	if a.RateLimit != nil {
+		verr.Merge(a.RateLimit.Validate())
+	}
 	if a.Params != nil {
 		for n, p := range a.Params.Type.ToObject() {
 			if p.Type.IsPrimitive() {
@@ -373,6 +396,55 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	return verr.AsError()
 }
 
+// validateStream checks that streaming actions do not use websockets and stream a media type
+// with a default view.
+func (a *ActionDefinition) validateStream() *dslengine.ValidationErrors {
+	if a.Stream == nil {
+		return nil
+	}
+	verr := new(dslengine.ValidationErrors)
+	if a.WebSocket() {
+		verr.Add(a, "Action cannot both stream server-sent events and use websockets")
+	}
+	if _, ok := a.Stream.Views[DefaultView]; !ok {
+		verr.Add(a, "Stream media type %s must define a default view", a.Stream.Identifier)
+	}
+	return verr.AsError()
+}
+
+// validatePagination checks that the pagination parameters are query string parameters with
+// valid types.
+func (a *ActionDefinition) validatePagination() *dslengine.ValidationErrors {
+	p := a.Pagination
+	if p == nil {
+		return nil
+	}
+	verr := new(dslengine.ValidationErrors)
+	if p.CursorParam == "" || p.LimitParam == "" || p.CursorParam == p.LimitParam {
+		verr.Add(a, "Pagination requires distinct non empty cursor and limit parameter names")
+		return verr
+	}
+	params := a.AllParams().Type.ToObject()
+	for _, r := range a.Routes {
+		for _, n := range r.Params() {
+			if n == p.CursorParam || n == p.LimitParam {
+				verr.Add(a, "Pagination parameter %s cannot be a path parameter", n)
+			}
+		}
+	}
+	if c, ok := params[p.CursorParam]; !ok {
+		verr.Add(a, "Pagination cursor %s must be a query string parameter", p.CursorParam)
+	} else if c.Type != String && c.Type != Integer {
+		verr.Add(a, "Pagination cursor %s must be a string or an integer", p.CursorParam)
+	}
+	if l, ok := params[p.LimitParam]; !ok {
+		verr.Add(a, "Pagination limit %s must be a query string parameter", p.LimitParam)
+	} else if l.Type != Integer {
+		verr.Add(a, "Pagination limit %s must be an integer", p.LimitParam)
+	}
+	return verr.AsError()
+}
+
 // Validate checks the file server is properly initialized.
 func (f *FileServerDefinition) Validate() *dslengine.ValidationErrors {
 	verr := new(dslengine.ValidationErrors)
@@ -436,6 +508,8 @@ func (a *ActionDefinition) ValidateParams() *dslengine.ValidationErrors {
 			verr.Add(a, `parameter %s cannot be an object, only action payloads may be of type object`, n)
 		} else if p.Type.Kind() == HashKind {
 			verr.Add(a, `parameter %s cannot be a hash, only action payloads may be of type hash`, n)
+		} else if p.Type.Kind() == UnionKind {
+			verr.Add(a, `parameter %s cannot be a union, only action payloads may contain unions`, n)
 		}
 		ctx := fmt.Sprintf("parameter %s", n)
 		verr.Merge(p.Validate(ctx, a))
@@ -1,12 +1,18 @@
 package goa
 
 import (
+	"context"
 	"encoding/gob"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -102,6 +108,52 @@ func NewHTTPDecoder() *HTTPDecoder {
 	}
 }
 
+// EncodingMuxHandler returns a MuxHandler that makes the service encode the responses of h with
+// encoder and decode its request bodies with decoder instead of the service Encoder and Decoder.
+// Either may be nil in which case the corresponding service encoder or decoder is used. This
+// function is intended for the controller generated code of actions that define their own
+// Consumes or Produces.
+func EncodingMuxHandler(h MuxHandler, encoder *HTTPEncoder, decoder *HTTPDecoder) MuxHandler {
+	return func(rw http.ResponseWriter, req *http.Request, params url.Values) {
+		ctx := req.Context()
+		if encoder != nil {
+			ctx = WithEncoder(ctx, encoder)
+		}
+		if decoder != nil {
+			ctx = WithDecoder(ctx, decoder)
+		}
+		h(rw, req.WithContext(ctx), params)
+	}
+}
+
+// WithEncoder creates a context with the given response encoder. Service.Send uses the encoder
+// instead of the service Encoder.
+func WithEncoder(ctx context.Context, encoder *HTTPEncoder) context.Context {
+	return context.WithValue(ctx, encoderKey, encoder)
+}
+
+// ContextEncoder extracts the response encoder from the given context, nil if there is none.
+func ContextEncoder(ctx context.Context) *HTTPEncoder {
+	if e := ctx.Value(encoderKey); e != nil {
+		return e.(*HTTPEncoder)
+	}
+	return nil
+}
+
+// WithDecoder creates a context with the given request body decoder. Service.DecodeRequest uses
+// the decoder of the request context instead of the service Decoder.
+func WithDecoder(ctx context.Context, decoder *HTTPDecoder) context.Context {
+	return context.WithValue(ctx, decoderKey, decoder)
+}
+
+// ContextDecoder extracts the request body decoder from the given context, nil if there is none.
+func ContextDecoder(ctx context.Context) *HTTPDecoder {
+	if d := ctx.Value(decoderKey); d != nil {
+		return d.(*HTTPDecoder)
+	}
+	return nil
+}
+
 // Decode uses registered Decoders to unmarshal a body based on the contentType.
 func (decoder *HTTPDecoder) Decode(v interface{}, body io.Reader, contentType string) error {
 	now := time.Now()
@@ -183,27 +235,25 @@ func (p *decoderPool) Put(d Decoder) {
 }
 
 // Encode uses the registered encoders and given content type to marshal and write the given value
-// using the given writer.
+// using the given writer. The encoder is selected by negotiating the content type with the
+// accept header value, see Negotiate. Encode sets the Content-Type header of resp to the
+// negotiated content type if resp is a http.ResponseWriter and the header is not already set.
 func (encoder *HTTPEncoder) Encode(v interface{}, resp io.Writer, accept string) error {
 	now := time.Now()
-	if accept == "" {
-		accept = "*/*"
-	}
-	var contentType string
-	for _, t := range encoder.contentTypes {
-		if accept == "*/*" || accept == t {
-			contentType = accept
-			break
-		}
+	contentType, err := encoder.Negotiate(accept)
+	if err != nil {
+		return err
 	}
 	defer MeasureSince([]string{"goa", "encode", contentType}, now)
 	p := encoder.pools[contentType]
-	if p == nil && contentType != "*/*" {
-		p = encoder.pools["*/*"]
-	}
 	if p == nil {
 		return fmt.Errorf("No encoder registered for %s and no default encoder", contentType)
 	}
+	if rw, ok := resp.(http.ResponseWriter); ok && rw.Header().Get("Content-Type") == "" {
+		if ct := encoder.ContentType(contentType); ct != "" {
+			rw.Header().Set("Content-Type", ct)
+		}
+	}
 
 	// the encoderPool will handle whether or not a pool is actually in use
 	e := p.Get(resp)
@@ -215,6 +265,84 @@ func (encoder *HTTPEncoder) Encode(v interface{}, resp io.Writer, accept string)
 	return nil
 }
 
+// Negotiate returns the registered content type that best matches the given Accept header value
+// as described in RFC 7231 section 5.3.2. Media ranges with a quality value of 0 are excluded,
+// wildcards such as "application/*" match all the corresponding registered content types and
+// structured syntax suffixes match their base type (e.g. "application/vnd.goa.error+json" matches
+// "application/json"). Negotiate returns "*/*" if the default encoder should be used, that is if
+// the header is empty or only accepts content types through the "*/*" media range and a default
+// encoder is registered. Negotiate returns an error created with ErrNotAcceptable if no registered
+// content type is acceptable.
+func (encoder *HTTPEncoder) Negotiate(accept string) (string, error) {
+	ranges := parseAccept(accept)
+	_, hasDefault := encoder.pools["*/*"]
+	var (
+		best     string
+		bestQ    float64
+		bestSpec = -1
+	)
+	for _, ct := range encoder.contentTypes {
+		if ct == "*/*" {
+			continue
+		}
+		q, spec := acceptQuality(ranges, ct)
+		if q > bestQ || (q == bestQ && q > 0 && spec > bestSpec) {
+			best, bestQ, bestSpec = ct, q, spec
+		}
+	}
+	if best != "" && bestSpec > 0 {
+		return best, nil
+	}
+	if hasDefault && encoder.acceptsDefault(ranges) {
+		return "*/*", nil
+	}
+	if best != "" {
+		return best, nil
+	}
+	available := make([]string, 0, len(encoder.contentTypes))
+	for _, ct := range encoder.contentTypes {
+		if ct != "*/*" {
+			available = append(available, ct)
+		}
+	}
+	return "", NotAcceptableError(accept, available)
+}
+
+// ContentType returns the value of the Content-Type header of responses encoded with the encoder
+// registered for the given content type as returned by Negotiate. The content type of the default
+// encoder is the first content type registered with the same encoder function if any, ContentType
+// returns an empty string if there is none.
+func (encoder *HTTPEncoder) ContentType(contentType string) string {
+	if contentType != "*/*" {
+		return contentType
+	}
+	def, ok := encoder.pools["*/*"]
+	if !ok {
+		return ""
+	}
+	fn := reflect.ValueOf(def.fn).Pointer()
+	for _, ct := range encoder.contentTypes {
+		if ct != "*/*" && reflect.ValueOf(encoder.pools[ct].fn).Pointer() == fn {
+			return ct
+		}
+	}
+	return ""
+}
+
+// acceptsDefault returns true if the given media ranges accept any content type and do not
+// exclude the content type of the default encoder.
+func (encoder *HTTPEncoder) acceptsDefault(ranges []acceptRange) bool {
+	if q, _ := acceptQuality(ranges, "*/*"); q == 0 {
+		return false
+	}
+	if ct := encoder.ContentType("*/*"); ct != "" {
+		if q, _ := acceptQuality(ranges, ct); q == 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // Register sets a specific encoder to be used for the specified content types. If an encoder is
 // already registered, it is overwritten.
 func (encoder *HTTPEncoder) Register(f EncoderFunc, contentTypes ...string) {
@@ -224,14 +352,80 @@ func (encoder *HTTPEncoder) Register(f EncoderFunc, contentTypes ...string) {
 		if err != nil {
 			mediaType = contentType
 		}
+		if _, ok := encoder.pools[mediaType]; !ok {
+			// Keep the registration order to break ties during negotiation
+			encoder.contentTypes = append(encoder.contentTypes, mediaType)
+		}
 		encoder.pools[mediaType] = p
 	}
+}
+
+// acceptRange is a media range listed in an Accept header.
+type acceptRange struct {
+	typ, subtype string
+	params       int
+	q            float64
+}
+
+// parseAccept parses the value of an Accept header. Invalid media ranges are ignored. An empty
+// value is equivalent to "*/*".
+func parseAccept(accept string) []acceptRange {
+	if strings.TrimSpace(accept) == "" {
+		return []acceptRange{{typ: "*", subtype: "*", q: 1}}
+	}
+	var ranges []acceptRange
+	for _, elem := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(elem))
+		if err != nil {
+			continue
+		}
+		parts := strings.SplitN(mediaType, "/", 2)
+		if len(parts) != 2 || (parts[0] == "*" && parts[1] != "*") {
+			continue
+		}
+		r := acceptRange{typ: parts[0], subtype: parts[1], params: len(params), q: 1}
+		if qv, ok := params["q"]; ok {
+			r.params--
+			q, err := strconv.ParseFloat(qv, 64)
+			if err != nil || q < 0 || q > 1 {
+				continue
+			}
+			r.q = q
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
 
-	// Rebuild a unique index of registered content encoders to be used in EncodeResponse
-	encoder.contentTypes = make([]string, 0, len(encoder.pools))
-	for contentType := range encoder.pools {
-		encoder.contentTypes = append(encoder.contentTypes, contentType)
+// acceptQuality returns the quality value given to the content type by the most specific media
+// range that matches it together with the specificity of the range: 0 for "*/*", 1 for "type/*",
+// 2 for "type/subtype" and 3 for "type/subtype" with parameters. The returned quality value is 0
+// if no range matches.
+func acceptQuality(ranges []acceptRange, contentType string) (float64, int) {
+	typ, subtype := contentType, ""
+	if i := strings.Index(contentType, "/"); i >= 0 {
+		typ, subtype = contentType[:i], contentType[i+1:]
+	}
+	q, spec := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.typ == "*":
+			s = 0
+		case r.typ != typ:
+		case r.subtype == "*":
+			s = 1
+		case r.subtype == subtype, strings.HasSuffix(r.subtype, "+"+subtype):
+			s = 2
+			if r.params > 0 {
+				s = 3
+			}
+		}
+		if s > spec {
+			q, spec = r.q, s
+		}
 	}
+	return q, spec
 }
 
 // newEncodePool checks to see if the EncoderFactory returns reusable encoders and if so, creates
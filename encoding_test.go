@@ -0,0 +1,70 @@
+package goa_test
+
+import (
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTPEncoder", func() {
+	var encoder *goa.HTTPEncoder
+
+	BeforeEach(func() {
+		encoder = goa.NewHTTPEncoder()
+		encoder.Register(goa.NewJSONEncoder, "application/json")
+		encoder.Register(goa.NewXMLEncoder, "application/xml", "text/xml")
+		encoder.Register(goa.NewJSONEncoder, "*/*")
+	})
+
+	Describe("Negotiate", func() {
+		cases := []struct{ desc, accept, expected string }{
+			{"with no header", "", "*/*"},
+			{"with any type", "*/*", "*/*"},
+			{"with an exact match", "application/xml", "application/xml"},
+			{"with media type parameters", "application/xml; charset=utf-8", "application/xml"},
+			{"with quality values", "application/json;q=0.5, application/xml", "application/xml"},
+			{"with a type wildcard", "text/*", "text/xml"},
+			{"with a specific type and a fallback", "text/html, application/json;q=0.9, */*;q=0.1", "application/json"},
+			{"with a structured syntax suffix", "application/vnd.goa.error+json", "application/json"},
+			{"with an excluded default", "application/json;q=0, */*", "application/xml"},
+			{"with a more specific exclusion", "application/*, application/json;q=0", "application/xml"},
+		}
+		for _, c := range cases {
+			c := c
+			It("selects the best content type "+c.desc, func() {
+				ct, err := encoder.Negotiate(c.accept)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(ct).Should(Equal(c.expected))
+			})
+		}
+
+		It("fails when no registered content type is acceptable", func() {
+			_, err := encoder.Negotiate("text/html, application/xml;q=0, application/json;q=0")
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(406))
+		})
+
+		It("fails when the default encoder is excluded", func() {
+			_, err := encoder.Negotiate("*/*;q=0")
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("Encode", func() {
+		It("sets the negotiated content type", func() {
+			rw := httptest.NewRecorder()
+			Ω(encoder.Encode(map[string]int{"a": 1}, rw, "*/*")).ShouldNot(HaveOccurred())
+			Ω(rw.Header().Get("Content-Type")).Should(Equal("application/json"))
+			Ω(rw.Body.String()).Should(Equal("{\"a\":1}\n"))
+		})
+
+		It("does not override an existing content type", func() {
+			rw := httptest.NewRecorder()
+			rw.Header().Set("Content-Type", "application/vnd.goa.error")
+			Ω(encoder.Encode(1, rw, "")).ShouldNot(HaveOccurred())
+			Ω(rw.Header().Get("Content-Type")).Should(Equal("application/vnd.goa.error"))
+		})
+	})
+})
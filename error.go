@@ -73,6 +73,21 @@ var (
 	// handler but not the HTTP method.
 	ErrMethodNotAllowed = NewErrorClass("method_not_allowed", 405)
 
+	// ErrNotAcceptable is the error returned when none of the content types listed in the
+	// request Accept header can be produced by the registered encoders.
+	ErrNotAcceptable = NewErrorClass("not_acceptable", 406)
+
+	// ErrPreconditionFailed is the error returned to requests whose preconditions such as
+	// "If-Match" do not hold.
+	ErrPreconditionFailed = NewErrorClass("precondition_failed", 412)
+
+	// ErrUnsupportedEncoding is the error returned to requests whose body is compressed with a
+	// content coding the service does not support.
+	ErrUnsupportedEncoding = NewErrorClass("unsupported_encoding", 415)
+
+	// ErrTooManyRequests is the error returned to requests that exceed a rate limit.
+	ErrTooManyRequests = NewErrorClass("too_many_requests", 429)
+
 	// ErrInternal is the class of error used for uncaught errors.
 	ErrInternal = NewErrorClass("internal", 500)
 )
@@ -122,6 +137,8 @@ type (
 		Detail string `json:"detail" yaml:"detail" xml:"detail" form:"detail"`
 		// Meta contains additional key/value pairs useful to clients.
 		Meta map[string]interface{} `json:"meta,omitempty" yaml:"meta,omitempty" xml:"-" form:"meta,omitempty"`
+
+		messages []*errorMessage // Messages making up Detail, used for translation
 	}
 )
 
@@ -153,46 +170,59 @@ func NewErrorClass(code string, status int) ErrorClass {
 			}
 			meta[fmt.Sprintf("%v", k)] = v
 		}
-		return &ErrorResponse{ID: newErrorID(), Code: code, Status: status, Detail: msg, Meta: meta}
+		args := make(map[string]interface{}, len(meta))
+		for k, v := range meta {
+			args[k] = v
+		}
+		return &ErrorResponse{
+			ID:       newErrorID(),
+			Code:     code,
+			Status:   status,
+			Detail:   msg,
+			Meta:     meta,
+			messages: []*errorMessage{{key: code, detail: msg, args: args}},
+		}
 	}
 }
 
 // MissingPayloadError is the error produced when a request is missing a required payload.
 func MissingPayloadError() error {
-	return ErrInvalidRequest("missing required payload")
+	return withMessageKey(ErrInvalidRequest("missing required payload"), MsgMissingPayload)
 }
 
 // InvalidParamTypeError is the error produced when the type of a parameter does not match the type
 // defined in the design.
 func InvalidParamTypeError(name string, val interface{}, expected string) error {
 	msg := fmt.Sprintf("invalid value %#v for parameter %#v, must be a %s", val, name, expected)
-	return ErrInvalidRequest(msg, "param", name, "value", val, "expected", expected)
+	err := ErrInvalidRequest(msg, "param", name, "value", val, "expected", expected)
+	return withMessageKey(err, MsgInvalidParamType)
 }
 
 // MissingParamError is the error produced for requests that are missing path or querystring
 // parameters.
 func MissingParamError(name string) error {
 	msg := fmt.Sprintf("missing required parameter %#v", name)
-	return ErrInvalidRequest(msg, "name", name)
+	return withMessageKey(ErrInvalidRequest(msg, "name", name), MsgMissingParam)
 }
 
 // InvalidAttributeTypeError is the error produced when the type of payload field does not match
 // the type defined in the design.
 func InvalidAttributeTypeError(ctx string, val interface{}, expected string) error {
 	msg := fmt.Sprintf("type of %s must be %s but got value %#v", ctx, expected, val)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", val, "expected", expected)
+	err := ErrInvalidRequest(msg, "attribute", ctx, "value", val, "expected", expected)
+	return withMessageKey(err, MsgInvalidAttributeType)
 }
 
 // MissingAttributeError is the error produced when a request payload is missing a required field.
 func MissingAttributeError(ctx, name string) error {
 	msg := fmt.Sprintf("attribute %#v of %s is missing and required", name, ctx)
-	return ErrInvalidRequest(msg, "attribute", name, "parent", ctx)
+	return withMessageKey(ErrInvalidRequest(msg, "attribute", name, "parent", ctx), MsgMissingAttribute)
 }
 
 // MissingHeaderError is the error produced when a request is missing a required header.
 func MissingHeaderError(name string) error {
 	msg := fmt.Sprintf("missing required HTTP header %#v", name)
-	return ErrInvalidRequest(msg, "name", name)
+	return withMessageKey(ErrInvalidRequest(msg, "name", name), MsgMissingHeader)
 }
 
 // InvalidEnumValueError is the error produced when the value of a parameter or payload field does
@@ -203,50 +233,63 @@ func InvalidEnumValueError(ctx string, val interface{}, allowed []interface{}) e
 		elems[i] = fmt.Sprintf("%#v", a)
 	}
 	msg := fmt.Sprintf("value of %s must be one of %s but got value %#v", ctx, strings.Join(elems, ", "), val)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", val, "expected", strings.Join(elems, ", "))
+	err := ErrInvalidRequest(msg, "attribute", ctx, "value", val, "expected", strings.Join(elems, ", "))
+	return withMessageKey(err, MsgInvalidEnumValue)
 }
 
 // InvalidFormatError is the error produced when the value of a parameter or payload field does not
 // match the format validation defined in the design.
 func InvalidFormatError(ctx, target string, format Format, formatError error) error {
 	msg := fmt.Sprintf("%s must be formatted as a %s but got value %#v, %s", ctx, format, target, formatError.Error())
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "expected", format, "error", formatError.Error())
+	err := ErrInvalidRequest(msg, "attribute", ctx, "value", target, "expected", format, "error", formatError.Error())
+	return withMessageKey(err, MsgInvalidFormat)
 }
 
 // InvalidPatternError is the error produced when the value of a parameter or payload field does
 // not match the pattern validation defined in the design.
 func InvalidPatternError(ctx, target string, pattern string) error {
 	msg := fmt.Sprintf("%s must match the regexp %#v but got value %#v", ctx, pattern, target)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "regexp", pattern)
+	err := ErrInvalidRequest(msg, "attribute", ctx, "value", target, "regexp", pattern)
+	return withMessageKey(err, MsgInvalidPattern)
 }
 
 // InvalidRangeError is the error produced when the value of a parameter or payload field does
 // not match the range validation defined in the design. value may be a int or a float64.
 func InvalidRangeError(ctx string, target interface{}, value interface{}, min bool) error {
-	comp := "greater than or equal to"
+	comp, key := "greater than or equal to", MsgInvalidMinimum
 	if !min {
-		comp = "less than or equal to"
+		comp, key = "less than or equal to", MsgInvalidMaximum
 	}
 	msg := fmt.Sprintf("%s must be %s %v but got value %#v", ctx, comp, value, target)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "comp", comp, "expected", value)
+	err := ErrInvalidRequest(msg, "attribute", ctx, "value", target, "comp", comp, "expected", value)
+	return withMessageKey(err, key)
 }
 
 // InvalidLengthError is the error produced when the value of a parameter or payload field does
 // not match the length validation defined in the design.
 func InvalidLengthError(ctx string, target interface{}, ln, value int, min bool) error {
-	comp := "greater than or equal to"
+	comp, key := "greater than or equal to", MsgInvalidMinLength
 	if !min {
-		comp = "less than or equal to"
+		comp, key = "less than or equal to", MsgInvalidMaxLength
 	}
 	msg := fmt.Sprintf("length of %s must be %s %d but got value %#v (len=%d)", ctx, comp, value, target, ln)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "len", ln, "comp", comp, "expected", value)
+	err := ErrInvalidRequest(msg, "attribute", ctx, "value", target, "len", ln, "comp", comp, "expected", value)
+	return withMessageKey(err, key)
+}
+
+// InvalidUnionError is the error produced when a union payload field does not hold exactly one of
+// the alternatives defined in the design.
+func InvalidUnionError(ctx string, count int, alternatives []string) error {
+	msg := fmt.Sprintf("%s must hold exactly one of %s but got %d", ctx, strings.Join(alternatives, ", "), count)
+	err := ErrInvalidRequest(msg, "attribute", ctx, "count", count, "expected", strings.Join(alternatives, ", "))
+	return withMessageKey(err, MsgInvalidUnion)
 }
 
 // NoAuthMiddleware is the error produced when goa is unable to lookup a auth middleware for a
 // security scheme defined in the design.
 func NoAuthMiddleware(schemeName string) error {
 	msg := fmt.Sprintf("Auth middleware for security scheme %s is not mounted", schemeName)
-	return ErrNoAuthMiddleware(msg, "scheme", schemeName)
+	return withMessageKey(ErrNoAuthMiddleware(msg, "scheme", schemeName), MsgNoAuthMiddleware)
 }
 
 // MethodNotAllowedError is the error produced to requests that match the path of a registered
@@ -257,7 +300,32 @@ func MethodNotAllowedError(method string, allowed []string) error {
 		plural = " one of"
 	}
 	msg := fmt.Sprintf("Method %s must be%s %s", method, plural, strings.Join(allowed, ", "))
-	return ErrMethodNotAllowed(msg, "method", method, "allowed", strings.Join(allowed, ", "))
+	err := ErrMethodNotAllowed(msg, "method", method, "allowed", strings.Join(allowed, ", "))
+	return withMessageKey(err, MsgMethodNotAllowed)
+}
+
+// NotAcceptableError is the error produced when none of the content types listed in the request
+// Accept header can be produced by the registered encoders.
+func NotAcceptableError(accept string, available []string) error {
+	msg := fmt.Sprintf("Accept header %#v does not match any of %s", accept, strings.Join(available, ", "))
+	err := ErrNotAcceptable(msg, "accept", accept, "available", strings.Join(available, ", "))
+	return withMessageKey(err, MsgNotAcceptable)
+}
+
+// PreconditionFailedError is the error produced when the given request header precondition does
+// not hold.
+func PreconditionFailedError(header string) error {
+	msg := fmt.Sprintf("precondition %s failed", header)
+	err := ErrPreconditionFailed(msg, "header", header)
+	return withMessageKey(err, MsgPreconditionFailed)
+}
+
+// TooManyRequestsError is the error produced when a request exceeds the given rate limit.
+// retryAfter is the number of seconds after which the client may retry.
+func TooManyRequestsError(limit int, retryAfter int64) error {
+	msg := fmt.Sprintf("rate limit of %d requests exceeded, retry in %d seconds", limit, retryAfter)
+	err := ErrTooManyRequests(msg, "limit", limit, "retry_after", retryAfter)
+	return withMessageKey(err, MsgTooManyRequests)
 }
 
 // Error returns the error occurrence details.
@@ -275,6 +343,14 @@ func (e *ErrorResponse) ResponseStatus() int { return e.Status }
 // Token is the unique error occurrence identifier.
 func (e *ErrorResponse) Token() string { return e.ID }
 
+// parts returns the messages making up the error detail.
+func (e *ErrorResponse) parts() []*errorMessage {
+	if len(e.messages) == 0 {
+		return []*errorMessage{{detail: e.Detail}}
+	}
+	return e.messages
+}
+
 // MergeErrors updates an error by merging another into it. It first converts other into a
 // ServiceError if not already one - producing an internal error in that case. The merge algorithm
 // is:
@@ -322,6 +398,7 @@ func MergeErrors(err, other error) error {
 		e.Status = 400
 		e.Code = "bad_request"
 	}
+	e.messages = append(e.parts(), o.parts()...)
 	e.Detail = e.Detail + "; " + o.Detail
 
 	if e.Meta == nil && len(o.Meta) > 0 {
@@ -224,6 +224,26 @@ var _ = Describe("InvalidPatternError", func() {
 	})
 })
 
+var _ = Describe("InvalidUnionError", func() {
+	var valErr error
+	ctx := "ctx"
+	count := 2
+	alternatives := []string{"email", "sms"}
+
+	JustBeforeEach(func() {
+		valErr = InvalidUnionError(ctx, count, alternatives)
+	})
+
+	It("creates a http error", func() {
+		Ω(valErr).ShouldNot(BeNil())
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Detail).Should(ContainSubstring(ctx))
+		Ω(err.Detail).Should(ContainSubstring("email, sms"))
+		Ω(err.Detail).Should(ContainSubstring("got 2"))
+	})
+})
+
 var _ = Describe("InvalidRangeError", func() {
 	var valErr error
 	var value interface{}
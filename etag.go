@@ -0,0 +1,95 @@
+package goa
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"strings"
+)
+
+// NewETag returns the entity tag of the given response body. The tag is the quoted hex encoding
+// of the first 16 bytes of the body SHA-256 checksum, prefixed with "W/" if weak is true.
+func NewETag(body []byte, weak bool) string {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	if weak {
+		etag = "W/" + etag
+	}
+	return etag
+}
+
+// MatchETag returns true if the value of an "If-Match" or "If-None-Match" request header matches
+// the given entity tag. header is either "*", which matches any tag, or a comma separated list of
+// entity tags. Strong comparison (used by "If-Match") requires both tags to be strong and
+// identical, weak comparison (used by "If-None-Match") ignores the "W/" prefix of both tags.
+// MatchETag returns false if etag is empty, that is if the resource does not exist.
+func MatchETag(header, etag string, strong bool) bool {
+	if etag == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	if strong && strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			if strong {
+				continue
+			}
+			tag = tag[2:]
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckIfMatch returns an error created with PreconditionFailedError if the request has an
+// "If-Match" header that does not match etag, the current entity tag of the resource or an empty
+// string if the resource does not exist. Actions that modify resources call CheckIfMatch before
+// making any change so that clients do not overwrite changes made concurrently by other clients.
+func CheckIfMatch(ctx context.Context, etag string) error {
+	req := ContextRequest(ctx)
+	if req == nil {
+		return nil
+	}
+	header := req.Header.Get("If-Match")
+	if header == "" || MatchETag(header, etag, true) {
+		return nil
+	}
+	return PreconditionFailedError("If-Match")
+}
+
+// ETag returns the entity tag of the response body v would produce if it was sent with Send. The
+// value is encoded with the encoder negotiated from the request "Accept" header.
+func (service *Service) ETag(ctx context.Context, v interface{}, weak bool) (string, error) {
+	var accept string
+	if req := ContextRequest(ctx); req != nil {
+		accept = req.Header.Get("Accept")
+	}
+	var buf bytes.Buffer
+	if err := service.encoder(ctx).Encode(v, &buf, accept); err != nil {
+		return "", err
+	}
+	return NewETag(buf.Bytes(), weak), nil
+}
+
+// CheckIfMatch calls CheckIfMatch with the entity tag of current, the current representation of
+// the resource. current is nil if the resource does not exist.
+func (service *Service) CheckIfMatch(ctx context.Context, current interface{}) error {
+	var etag string
+	if v := reflect.ValueOf(current); v.IsValid() && (v.Kind() != reflect.Ptr || !v.IsNil()) {
+		var err error
+		if etag, err = service.ETag(ctx, current, false); err != nil {
+			return err
+		}
+	}
+	return CheckIfMatch(ctx, etag)
+}
@@ -0,0 +1,113 @@
+package goa_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewETag", func() {
+	It("computes strong and weak tags", func() {
+		etag := goa.NewETag([]byte("body"), false)
+		Ω(etag).Should(MatchRegexp(`^"[0-9a-f]{32}"$`))
+		Ω(goa.NewETag([]byte("body"), true)).Should(Equal("W/" + etag))
+		Ω(goa.NewETag([]byte("other"), false)).ShouldNot(Equal(etag))
+	})
+})
+
+var _ = Describe("MatchETag", func() {
+	It("uses strong comparison", func() {
+		Ω(goa.MatchETag(`"a", "b"`, `"b"`, true)).Should(BeTrue())
+		Ω(goa.MatchETag(`W/"b"`, `"b"`, true)).Should(BeFalse())
+		Ω(goa.MatchETag(`"b"`, `W/"b"`, true)).Should(BeFalse())
+	})
+
+	It("uses weak comparison", func() {
+		Ω(goa.MatchETag(`W/"b"`, `"b"`, false)).Should(BeTrue())
+		Ω(goa.MatchETag(`"a"`, `W/"b"`, false)).Should(BeFalse())
+	})
+
+	It("matches any existing resource with *", func() {
+		Ω(goa.MatchETag("*", `"b"`, true)).Should(BeTrue())
+		Ω(goa.MatchETag("*", "", true)).Should(BeFalse())
+	})
+})
+
+var _ = Describe("CheckIfMatch", func() {
+	var ifMatch string
+	var ctx context.Context
+
+	JustBeforeEach(func() {
+		req := httptest.NewRequest("PUT", "/", nil)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		ctx = goa.NewContext(nil, httptest.NewRecorder(), req, nil)
+	})
+
+	Context("without If-Match header", func() {
+		BeforeEach(func() { ifMatch = "" })
+
+		It("succeeds", func() {
+			Ω(goa.CheckIfMatch(ctx, `"a"`)).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("with a If-Match header", func() {
+		BeforeEach(func() { ifMatch = `"a"` })
+
+		It("succeeds if the tags match", func() {
+			Ω(goa.CheckIfMatch(ctx, `"a"`)).ShouldNot(HaveOccurred())
+		})
+
+		It("fails with a 412 error if the tags differ", func() {
+			err := goa.CheckIfMatch(ctx, `"b"`)
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(412))
+		})
+	})
+})
+
+var _ = Describe("Service ETag", func() {
+	It("computes the tag of the encoded value", func() {
+		service := goa.New("test")
+		service.Encoder.Register(goa.NewJSONEncoder, "application/json")
+		ctx := goa.NewContext(nil, httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), nil)
+		var buf bytes.Buffer
+		json.NewEncoder(&buf).Encode(map[string]int{"id": 1})
+		etag, err := service.ETag(ctx, map[string]int{"id": 1}, false)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(etag).Should(Equal(goa.NewETag(buf.Bytes(), false)))
+	})
+})
+
+var _ = Describe("Service CheckIfMatch", func() {
+	type bottle struct{ ID int }
+	var service *goa.Service
+	var ctx context.Context
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		req := httptest.NewRequest("PUT", "/", nil)
+		etag, _ := service.ETag(goa.NewContext(nil, httptest.NewRecorder(), req, nil), &bottle{ID: 1}, false)
+		req.Header.Set("If-Match", etag)
+		ctx = goa.NewContext(nil, httptest.NewRecorder(), req, nil)
+	})
+
+	It("compares the header with the tag of the current representation", func() {
+		Ω(service.CheckIfMatch(ctx, &bottle{ID: 1})).ShouldNot(HaveOccurred())
+		Ω(service.CheckIfMatch(ctx, &bottle{ID: 2})).Should(HaveOccurred())
+	})
+
+	It("fails if the resource does not exist", func() {
+		var b *bottle
+		Ω(service.CheckIfMatch(ctx, b)).Should(HaveOccurred())
+		Ω(service.CheckIfMatch(ctx, nil)).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,76 @@
+package goa
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ErrEventStreamClosed is the error returned when sending an event to a closed event stream.
+var ErrEventStreamClosed = errors.New("event stream is closed")
+
+// EventStream writes server-sent events to a HTTP response. Events are serialized to JSON and
+// written as "data" frames using the "text/event-stream" content type, the response is flushed
+// after each event so that clients receive them right away.
+type EventStream struct {
+	resp    *ResponseData
+	started bool
+	closed  bool
+}
+
+// NewEventStream creates an event stream that writes to the given response.
+func NewEventStream(resp *ResponseData) *EventStream {
+	return &EventStream{resp: resp}
+}
+
+// Send writes the given event to the stream and flushes the response.
+func (s *EventStream) Send(event interface{}) error {
+	if s.closed {
+		return ErrEventStreamClosed
+	}
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	s.start()
+	frame := make([]byte, 0, len(b)+8)
+	frame = append(frame, "data: "...)
+	frame = append(frame, b...)
+	frame = append(frame, '\n', '\n')
+	if _, err := s.resp.Write(frame); err != nil {
+		return err
+	}
+	s.flush()
+	return nil
+}
+
+// Close ends the stream. Close writes the response headers if no event was sent. Sending events
+// after the stream is closed returns ErrEventStreamClosed.
+func (s *EventStream) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.start()
+	s.flush()
+	s.closed = true
+	return nil
+}
+
+// start writes the response headers the first time it is called.
+func (s *EventStream) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+	h := s.resp.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	s.resp.WriteHeader(http.StatusOK)
+}
+
+// flush sends any buffered data to the client if the underlying writer supports it.
+func (s *EventStream) flush() {
+	if f, ok := s.resp.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
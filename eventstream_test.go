@@ -0,0 +1,47 @@
+package goa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EventStream", func() {
+	var rw *httptest.ResponseRecorder
+	var stream *goa.EventStream
+
+	BeforeEach(func() {
+		req, err := http.NewRequest("GET", "/events", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = httptest.NewRecorder()
+		ctx := goa.NewContext(context.Background(), rw, req, nil)
+		stream = goa.NewEventStream(goa.ContextResponse(ctx))
+	})
+
+	It("writes events as data frames and flushes", func() {
+		Ω(stream.Send(map[string]int{"n": 1})).ShouldNot(HaveOccurred())
+		Ω(stream.Send(map[string]int{"n": 2})).ShouldNot(HaveOccurred())
+		Ω(rw.Code).Should(Equal(http.StatusOK))
+		Ω(rw.Header().Get("Content-Type")).Should(Equal("text/event-stream"))
+		Ω(rw.Header().Get("Cache-Control")).Should(Equal("no-cache"))
+		Ω(rw.Header()).ShouldNot(HaveKey("Connection"))
+		Ω(rw.Flushed).Should(BeTrue())
+		Ω(rw.Body.String()).Should(Equal("data: {\"n\":1}\n\ndata: {\"n\":2}\n\n"))
+	})
+
+	It("writes the headers when closed without events", func() {
+		Ω(stream.Close()).ShouldNot(HaveOccurred())
+		Ω(rw.Code).Should(Equal(http.StatusOK))
+		Ω(rw.Header().Get("Content-Type")).Should(Equal("text/event-stream"))
+		Ω(rw.Body.Len()).Should(Equal(0))
+	})
+
+	It("rejects events sent after close", func() {
+		Ω(stream.Close()).ShouldNot(HaveOccurred())
+		Ω(stream.Send("late")).Should(Equal(goa.ErrEventStreamClosed))
+	})
+})
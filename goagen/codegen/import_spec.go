@@ -60,6 +60,12 @@ func AttributeImports(att *design.AttributeDefinition, imports []*ImportSpec, se
 			return nil
 		})
 		return imports
+	case *design.Union:
+		t.Alternatives.IterateAttributes(func(n string, t *design.AttributeDefinition) error {
+			imports = appendImports(imports, AttributeImports(t, imports, seen))
+			return nil
+		})
+		return imports
 	case *design.Array:
 		return appendImports(imports, AttributeImports(t.ElemType, imports, seen))
 	case *design.Hash:
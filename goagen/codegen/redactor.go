@@ -0,0 +1,139 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/goadesign/goa/design"
+)
+
+// RedactCode produces the body of the Redact method of the public Go type generated for the given
+// attribute, receiver is the name of the method receiver. The method returns a copy of the
+// receiver where the sensitive strings are replaced with goa.RedactedValue and the other
+// sensitive values are cleared. Child attributes whose types are user or media types are redacted
+// using their own Redact method. RedactCode returns the empty string if the attribute has no
+// sensitive child attribute.
+func RedactCode(att *design.AttributeDefinition, receiver string) string {
+	if !att.HasSensitive() {
+		return ""
+	}
+	code := redactAssign(att, "res", false, 1)
+	if code == "" {
+		return ""
+	}
+	return fmt.Sprintf("\tres := %s\n%s\n\treturn res", receiver, code)
+}
+
+// redactAssign produces the code that redacts the value held by target. The code assigns a
+// redacted copy to target so that the original value is not modified. nilable indicates whether
+// the Go type of target is a pointer.
+func redactAssign(att *design.AttributeDefinition, target string, nilable bool, depth int) string {
+	if att.IsSensitive() {
+		return redactMask(att, target, nilable, depth)
+	}
+	if !att.HasSensitive() {
+		return ""
+	}
+	switch actual := att.Type.(type) {
+	case *design.UserTypeDefinition, *design.MediaTypeDefinition:
+		if att.Type.IsPrimitive() {
+			return redactMask(att, target, nilable, depth)
+		}
+		return fmt.Sprintf("%sif %s != nil {\n%s%s = %s.Redact().(%s)\n%s}", Tabs(depth),
+			target, Tabs(depth+1), target, target, GoTypeRef(att.Type, nil, 0, false), Tabs(depth))
+	case design.Object:
+		return redactObject(att, actual, target, depth)
+	case *design.Union:
+		return redactObject(att, actual.Alternatives, target, depth)
+	case *design.Array:
+		v, i := fmt.Sprintf("v%d", depth), fmt.Sprintf("i%d", depth)
+		elem := redactAssign(actual.ElemType, fmt.Sprintf("%s[%s]", v, i), isNilable(actual.ElemType), depth+2)
+		if elem == "" {
+			return ""
+		}
+		return fmt.Sprintf("%sif %s != nil {\n%s%s := append(%s[:0:0], %s...)\n%sfor %s := range %s {\n%s\n%s}\n%s%s = %s\n%s}",
+			Tabs(depth), target,
+			Tabs(depth+1), v, target, target,
+			Tabs(depth+1), i, v,
+			elem,
+			Tabs(depth+1),
+			Tabs(depth+1), target, v,
+			Tabs(depth))
+	case *design.Hash:
+		v, k, e := fmt.Sprintf("v%d", depth), fmt.Sprintf("k%d", depth), fmt.Sprintf("e%d", depth)
+		elem := redactAssign(actual.ElemType, e, isNilable(actual.ElemType), depth+2)
+		if elem == "" {
+			return ""
+		}
+		return fmt.Sprintf("%sif %s != nil {\n%s%s := make(%s, len(%s))\n%sfor %s, %s := range %s {\n%s\n%s%s[%s] = %s\n%s}\n%s%s = %s\n%s}",
+			Tabs(depth), target,
+			Tabs(depth+1), v, GoTypeRef(att.Type, nil, 0, false), target,
+			Tabs(depth+1), k, e, target,
+			elem,
+			Tabs(depth+2), v, k, e,
+			Tabs(depth+1),
+			Tabs(depth+1), target, v,
+			Tabs(depth))
+	}
+	return ""
+}
+
+// redactObject produces the code that redacts the struct pointed to by target.
+func redactObject(att *design.AttributeDefinition, obj design.Object, target string, depth int) string {
+	var (
+		buf bytes.Buffer
+		v   = fmt.Sprintf("v%d", depth)
+	)
+	obj.IterateAttributes(func(n string, catt *design.AttributeDefinition) error {
+		nilable := catt.Type.IsObject() || catt.Type.IsArray() || catt.Type.IsHash() ||
+			att.IsPrimitivePointer(n) || att.IsInterface(n)
+		field := fmt.Sprintf("%s.%s", v, GoifyAtt(catt, n, true))
+		if code := redactAssign(catt, field, nilable, depth+1); code != "" {
+			buf.WriteString("\n")
+			buf.WriteString(code)
+		}
+		return nil
+	})
+	if buf.Len() == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%sif %s != nil {\n%s%s := *%s%s\n%s%s = &%s\n%s}",
+		Tabs(depth), target,
+		Tabs(depth+1), v, target, buf.String(),
+		Tabs(depth+1), target, v,
+		Tabs(depth))
+}
+
+// redactMask produces the code that masks the sensitive value held by target.
+func redactMask(att *design.AttributeDefinition, target string, nilable bool, depth int) string {
+	if typ, ok := att.Metadata["struct:field:type"]; ok && len(typ) > 0 {
+		return fmt.Sprintf("%s%s = *new(%s)", Tabs(depth), target, typ[0])
+	}
+	if att.Type.Kind() == design.StringKind {
+		if nilable {
+			return fmt.Sprintf("%sif %s != nil {\n%sredacted := goa.RedactedValue\n%s%s = &redacted\n%s}",
+				Tabs(depth), target, Tabs(depth+1), Tabs(depth+1), target, Tabs(depth))
+		}
+		return fmt.Sprintf("%s%s = goa.RedactedValue", Tabs(depth), target)
+	}
+	if nilable {
+		return fmt.Sprintf("%s%s = nil", Tabs(depth), target)
+	}
+	var zero string
+	switch att.Type.Kind() {
+	case design.BooleanKind:
+		zero = "false"
+	case design.IntegerKind, design.NumberKind:
+		zero = "0"
+	default:
+		zero = GoNativeType(att.Type) + "{}"
+	}
+	return fmt.Sprintf("%s%s = %s", Tabs(depth), target, zero)
+}
+
+// isNilable returns true if the Go type of the elements of arrays and hashes with the given
+// element attribute is nilable.
+func isNilable(elem *design.AttributeDefinition) bool {
+	return elem.Type.IsObject() || elem.Type.IsArray() || elem.Type.IsHash() ||
+		elem.Type.Kind() == design.AnyKind
+}
@@ -0,0 +1,151 @@
+package codegen_test
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Redact code generation", func() {
+	var (
+		att  *design.AttributeDefinition
+		code string
+	)
+
+	sensitive := func(t design.DataType) *design.AttributeDefinition {
+		a := &design.AttributeDefinition{Type: t}
+		a.SetSensitive()
+		return a
+	}
+
+	JustBeforeEach(func() {
+		code = codegen.RedactCode(att, "ut")
+	})
+
+	Context("given an object without sensitive attribute", func() {
+		BeforeEach(func() {
+			att = &design.AttributeDefinition{
+				Type: design.Object{
+					"foo": &design.AttributeDefinition{Type: design.String},
+				},
+			}
+		})
+
+		It("produces no code", func() {
+			Ω(code).Should(BeEmpty())
+		})
+	})
+
+	Context("given an object with sensitive primitive attributes", func() {
+		BeforeEach(func() {
+			att = &design.AttributeDefinition{
+				Type: design.Object{
+					"name":     &design.AttributeDefinition{Type: design.String},
+					"password": sensitive(design.String),
+					"pin":      sensitive(design.Integer),
+					"token":    sensitive(design.String),
+				},
+				Validation: &dslengine.ValidationDefinition{Required: []string{"password"}},
+			}
+		})
+
+		It("masks the sensitive attributes", func() {
+			Ω(code).Should(Equal(primitiveRedactCode))
+		})
+	})
+
+	Context("given an object with an array of sensitive elements and a user type", func() {
+		BeforeEach(func() {
+			ut := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{"secret": sensitive(design.String)},
+				},
+				TypeName: "Creds",
+			}
+			att = &design.AttributeDefinition{
+				Type: design.Object{
+					"keys":  &design.AttributeDefinition{Type: &design.Array{ElemType: sensitive(design.String)}},
+					"creds": &design.AttributeDefinition{Type: ut},
+				},
+			}
+		})
+
+		It("redacts the array elements and the user type", func() {
+			Ω(code).Should(Equal(nestedRedactCode))
+		})
+	})
+
+	Context("given an object with a union with a sensitive alternative", func() {
+		BeforeEach(func() {
+			att = &design.AttributeDefinition{
+				Type: design.Object{
+					"credential": &design.AttributeDefinition{
+						Type: &design.Union{
+							Alternatives: design.Object{
+								"user":     &design.AttributeDefinition{Type: design.String},
+								"password": sensitive(design.String),
+							},
+						},
+					},
+				},
+			}
+		})
+
+		It("detects the sensitive alternative", func() {
+			Ω(att.HasSensitive()).Should(BeTrue())
+		})
+
+		It("redacts the sensitive alternative", func() {
+			Ω(code).Should(Equal(unionRedactCode))
+		})
+	})
+})
+
+const (
+	primitiveRedactCode = `	res := ut
+	if res != nil {
+		v1 := *res
+		v1.Password = goa.RedactedValue
+		v1.Pin = nil
+		if v1.Token != nil {
+			redacted := goa.RedactedValue
+			v1.Token = &redacted
+		}
+		res = &v1
+	}
+	return res`
+
+	nestedRedactCode = `	res := ut
+	if res != nil {
+		v1 := *res
+		if v1.Creds != nil {
+			v1.Creds = v1.Creds.Redact().(*Creds)
+		}
+		if v1.Keys != nil {
+			v2 := append(v1.Keys[:0:0], v1.Keys...)
+			for i2 := range v2 {
+				v2[i2] = goa.RedactedValue
+			}
+			v1.Keys = v2
+		}
+		res = &v1
+	}
+	return res`
+
+	unionRedactCode = `	res := ut
+	if res != nil {
+		v1 := *res
+		if v1.Credential != nil {
+			v2 := *v1.Credential
+			if v2.Password != nil {
+				redacted := goa.RedactedValue
+				v2.Password = &redacted
+			}
+			v1.Credential = &v2
+		}
+		res = &v1
+	}
+	return res`
+)
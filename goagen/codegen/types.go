@@ -92,6 +92,8 @@ func GoTypeDef(ds design.DataStructure, tabs int, jsonTags, private bool) string
 		return fmt.Sprintf("map[%s]%s", keyDef, elemDef)
 	case design.Object:
 		return goTypeDefObject(actual, def, tabs, jsonTags, private)
+	case *design.Union:
+		return goTypeDefObject(actual.Alternatives, def, tabs, jsonTags, private)
 	case *design.UserTypeDefinition:
 		return GoTypeName(actual, actual.AllRequired(), tabs, private)
 	case *design.MediaTypeDefinition:
@@ -205,6 +207,8 @@ func GoTypeName(t design.DataType, required []string, tabs int, private bool) st
 			att.Validation.Merge(requiredVal)
 		}
 		return GoTypeDef(att, tabs, false, private)
+	case *design.Union:
+		return GoTypeDef(&design.AttributeDefinition{Type: actual}, tabs, false, private)
 	case *design.Hash:
 		return fmt.Sprintf(
 			"map[%s]%s",
@@ -249,7 +253,7 @@ func GoNativeType(t design.DataType) string {
 		}
 	case *design.Array:
 		return "[]" + GoNativeType(actual.ElemType.Type)
-	case design.Object:
+	case design.Object, *design.Union:
 		return "map[string]interface{}"
 	case *design.Hash:
 		return fmt.Sprintf("map[%s]%s", GoNativeType(actual.KeyType.Type), GoNativeType(actual.ElemType.Type))
@@ -18,6 +18,7 @@ var (
 	minMaxValT   *template.Template
 	lengthValT   *template.Template
 	requiredValT *template.Template
+	unionValT    *template.Template
 )
 
 //  init instantiates the templates.
@@ -49,6 +50,9 @@ func init() {
 	if requiredValT, err = template.New("required").Funcs(fm).Parse(requiredValTmpl); err != nil {
 		panic(err)
 	}
+	if unionValT, err = template.New("union").Funcs(fm).Parse(unionValTmpl); err != nil {
+		panic(err)
+	}
 }
 
 // Validator is the code generator for the 'Validate' type methods.
@@ -230,6 +234,14 @@ func (v *Validator) recurse(att *design.AttributeDefinition, nonzero, required,
 			buf.WriteString(validation)
 			first = false
 		}
+		if u, ok := att.Type.(*design.Union); ok {
+			if !first {
+				buf.WriteByte('\n')
+			} else {
+				first = false
+			}
+			buf.WriteString(unionValidationCode(u, target, context, depth))
+		}
 		o.IterateAttributes(func(n string, catt *design.AttributeDefinition) error {
 			validation := v.recurseAttribute(att, catt, n, target, context, depth, private)
 			if validation != "" {
@@ -427,6 +439,18 @@ func validationsCode(att *design.AttributeDefinition, data map[string]interface{
 	return
 }
 
+// unionValidationCode produces Go code that checks that exactly one of the union alternatives
+// is set.
+func unionValidationCode(u *design.Union, target, context string, depth int) string {
+	return RunTemplate(unionValT, map[string]interface{}{
+		"union":   u,
+		"names":   u.Names(),
+		"target":  target,
+		"context": context,
+		"depth":   depth,
+	})
+}
+
 // renderInteger renders a max or min value properly, taking into account
 // overflows due to casting from a float value.
 func renderInteger(f float64) string {
@@ -531,6 +555,16 @@ const (
 {{ if .isPointer }}{{ tabs $depth }}}
 {{ end }}{{ tabs .depth }}}`
 
+	unionValTmpl = `{{ tabs .depth }}{
+{{ tabs .depth }}	count := 0
+{{ range .names }}{{ $att := index $.union.Alternatives . }}{{ tabs $.depth }}	if {{ $.target }}.{{ goifyAtt $att . true }} != nil {
+{{ tabs $.depth }}		count++
+{{ tabs $.depth }}	}
+{{ end }}{{ tabs .depth }}	if count != 1 {
+{{ tabs .depth }}		err = goa.MergeErrors(err, goa.InvalidUnionError(` + "`" + `{{ .context }}` + "`" + `, count, []string{ {{- range $i, $n := .names }}{{ if $i }}, {{ end }}{{ printf "%q" $n }}{{ end -}} }))
+{{ tabs .depth }}	}
+{{ tabs .depth }}}`
+
 	requiredValTmpl = `{{ $att := index $.attribute.Type.ToObject .required }}{{/*
 */}}{{ if and (not $.private) (eq $att.Type.Kind 4) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == "" {
 {{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{  .required  }}"))
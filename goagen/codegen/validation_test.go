@@ -281,6 +281,24 @@ var _ = Describe("validation code generation", func() {
 
 			})
 
+			Context("of embedded union", func() {
+				BeforeEach(func() {
+					validation = nil
+					attType = design.Object{
+						"foo": &design.AttributeDefinition{
+							Type: &design.Union{Alternatives: design.Object{
+								"email": &design.AttributeDefinition{Type: design.String},
+								"sms":   &design.AttributeDefinition{Type: design.String},
+							}},
+						},
+					}
+				})
+
+				It("checks exactly one alternative is set", func() {
+					Ω(code).Should(Equal(unionValCode))
+				})
+			})
+
 			Context("of required user type attribute with no validation", func() {
 				var ut *design.UserTypeDefinition
 
@@ -458,6 +476,21 @@ const (
 		}
 	}`
 
+	unionValCode = `	if val.Foo != nil {
+		{
+			count := 0
+			if val.Foo.Email != nil {
+				count++
+			}
+			if val.Foo.Sms != nil {
+				count++
+			}
+			if count != 1 {
+				err = goa.MergeErrors(err, goa.InvalidUnionError(` + "`" + `context.foo` + "`" + `, count, []string{"email", "sms"}))
+			}
+		}
+	}`
+
 	utCode = `	if val.Foo == nil {
 		err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`context`" + `, "foo"))
 	}`
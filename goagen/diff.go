@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	gendiff "github.com/goadesign/goa/goagen/gen_diff"
+	"github.com/goadesign/goa/goagen/meta"
+)
+
+// runDiff compares the design package given via the "design" flag with either the base design
+// package or the same package at the given git revision. It prints the breaking changes and
+// returns an error if there is any.
+func runDiff(designPkg, basePkg, rev string, asJSON, debug bool) error {
+	if designPkg == "" {
+		return fmt.Errorf("missing design package flag")
+	}
+	if (basePkg == "") == (rev == "") {
+		return fmt.Errorf("exactly one of the base and rev flags must be specified")
+	}
+	tmpDir, err := ioutil.TempDir("", "goagen-diff")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	head, err := snapshotDesign(designPkg, filepath.Join(tmpDir, "head"), debug)
+	if err != nil {
+		return err
+	}
+	var base *gendiff.Snapshot
+	if rev != "" {
+		base, err = snapshotRevision(designPkg, rev, tmpDir, debug)
+	} else {
+		base, err = snapshotDesign(basePkg, filepath.Join(tmpDir, "base"), debug)
+	}
+	if err != nil {
+		return err
+	}
+
+	changes := gendiff.Compare(base, head)
+	if asJSON {
+		err = gendiff.WriteJSON(os.Stdout, changes)
+	} else {
+		err = gendiff.WriteText(os.Stdout, changes)
+	}
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 {
+		return fmt.Errorf("%d breaking change(s) found", len(changes))
+	}
+	return nil
+}
+
+// snapshotDesign runs the gen_diff generator against the given design package and loads the
+// resulting snapshot.
+func snapshotDesign(designPkg, outDir string, debug bool) (*gendiff.Snapshot, error) {
+	gen, err := meta.NewGenerator(
+		"gendiff.Generate",
+		[]*codegen.ImportSpec{codegen.SimpleImport("github.com/goadesign/goa/goagen/gen_diff")},
+		map[string]string{"out": outDir, "design": designPkg, "debug": strconv.FormatBool(debug)},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := gen.Generate(); err != nil {
+		return nil, err
+	}
+	return gendiff.LoadSnapshot(filepath.Join(outDir, gendiff.SnapshotFile))
+}
+
+// snapshotRevision snapshots the design package at the given git revision. In module mode the
+// revision is checked out in a git worktree and the generator is built from the worktree so that
+// the go command loads the design package and its dependencies from the revision. In GOPATH mode
+// the revision is exported to a temporary GOPATH workspace.
+func snapshotRevision(designPkg, rev, tmpDir string, debug bool) (*gendiff.Snapshot, error) {
+	outDir := filepath.Join(tmpDir, "base")
+	gomod, err := exec.Command("go", "env", "GOMOD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run go env: %s", err)
+	}
+	if mod := strings.TrimSpace(string(gomod)); mod == "" || mod == os.DevNull {
+		gopath, err := exportRevision(designPkg, rev, filepath.Join(tmpDir, "gopath"))
+		if err != nil {
+			return nil, err
+		}
+		prev := os.Getenv("GOPATH")
+		if prev != "" {
+			gopath += string(os.PathListSeparator) + prev
+		}
+		os.Setenv("GOPATH", gopath)
+		defer os.Setenv("GOPATH", prev)
+		return snapshotDesign(designPkg, outDir, debug)
+	}
+	dir, cleanup, err := checkoutRevision(designPkg, rev, filepath.Join(tmpDir, "worktree"))
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chdir(dir); err != nil {
+		return nil, err
+	}
+	defer os.Chdir(wd)
+	return snapshotDesign(designPkg, outDir, debug)
+}
+
+// checkoutRevision checks out the given revision of the git repository containing the current
+// directory in a new worktree rooted at dir. It returns the directory of the worktree that
+// corresponds to the current directory and a function that removes the worktree. The design
+// package must belong to the main module as the other modules are not loaded from the worktree.
+func checkoutRevision(designPkg, rev, dir string) (string, func(), error) {
+	out, err := exec.Command("go", "list", "-f", "{{if .Module}}{{.Module.Main}}{{end}}", designPkg).Output()
+	if err != nil || strings.TrimSpace(string(out)) != "true" {
+		return "", nil, fmt.Errorf("the rev flag requires design package %s to belong to the main module, use the base flag instead", designPkg)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", nil, err
+	}
+	if wd, err = filepath.EvalSymlinks(wd); err != nil {
+		return "", nil, err
+	}
+	out, err = exec.Command("git", "-C", wd, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("current directory is not in a git repository: %s", err)
+	}
+	top, err := filepath.EvalSymlinks(strings.TrimSpace(string(out)))
+	if err != nil {
+		return "", nil, err
+	}
+	rel, err := filepath.Rel(top, wd)
+	if err != nil {
+		return "", nil, err
+	}
+	cmd := exec.Command("git", "-C", top, "worktree", "add", "--detach", dir, rev)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", nil, fmt.Errorf("failed to check out revision %s: %s\n%s", rev, err, out)
+	}
+	cleanup := func() {
+		exec.Command("git", "-C", top, "worktree", "remove", "--force", dir).Run()
+	}
+	return filepath.Join(dir, rel), cleanup, nil
+}
+
+// exportRevision extracts the content of the git repository containing the design package at
+// the given revision into a new GOPATH workspace rooted at dir. It returns dir.
+func exportRevision(designPkg, rev, dir string) (string, error) {
+	pkgDir, err := codegen.PackageSourcePath(designPkg)
+	if err != nil {
+		return "", fmt.Errorf("invalid design package import path: %s", err)
+	}
+	out, err := exec.Command("git", "-C", pkgDir, "rev-parse", "--show-toplevel", "--show-prefix").Output()
+	if err != nil {
+		return "", fmt.Errorf("design package %s is not in a git repository: %s", designPkg, err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	top, prefix := lines[0], ""
+	if len(lines) > 1 {
+		prefix = strings.TrimSuffix(lines[1], "/")
+	}
+	repoPkg := designPkg
+	if prefix != "" {
+		if !strings.HasSuffix(designPkg, "/"+prefix) {
+			return "", fmt.Errorf("cannot compute repository import path of design package %s", designPkg)
+		}
+		repoPkg = strings.TrimSuffix(designPkg, "/"+prefix)
+	}
+	repoDir := filepath.Join(dir, "src", filepath.FromSlash(repoPkg))
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		return "", err
+	}
+	archive := filepath.Join(dir, "rev.tar")
+	cmd := exec.Command("git", "-C", top, "archive", "--format=tar", "-o", archive, rev)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to export revision %s: %s\n%s", rev, err, out)
+	}
+	cmd = exec.Command("tar", "-xf", archive, "-C", repoDir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to extract revision %s: %s\n%s", rev, err, out)
+	}
+	return dir, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const (
+	diffDesignV1 = `package design
+
+import . "github.com/goadesign/goa/design/apidsl"
+
+var _ = API("diff", func() {})
+
+var _ = Resource("bottle", func() {
+	Action("show", func() {
+		Routing(GET("/bottles/:id"))
+		Response("OK")
+	})
+	Action("list", func() {
+		Routing(GET("/bottles"))
+		Response("OK")
+	})
+})
+`
+
+	diffDesignV2 = `package design
+
+import . "github.com/goadesign/goa/design/apidsl"
+
+var _ = API("diff", func() {})
+
+var _ = Resource("bottle", func() {
+	Action("show", func() {
+		Routing(GET("/bottles/:id"))
+		Response("OK")
+	})
+})
+`
+)
+
+func TestRunDiffRevision(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	_, file, _, _ := runtime.Caller(0)
+	goaDir := filepath.Dir(filepath.Dir(file))
+	if _, err := os.Stat(filepath.Join(goaDir, "go.mod")); err != nil {
+		t.Skip("goa is not built in module mode")
+	}
+	dir, err := ioutil.TempDir("", "goagen-diff-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	gomod := "module example.com/diff\n\nrequire github.com/goadesign/goa v1.4.3\n\nreplace github.com/goadesign/goa => " + goaDir + "\n"
+	if content, err := ioutil.ReadFile(filepath.Join(goaDir, "go.mod")); err == nil {
+		// The replace directives of dependencies are ignored, copy the goa ones.
+		for _, line := range strings.Split(string(content), "\n") {
+			if strings.HasPrefix(line, "replace ") {
+				gomod += line + "\n"
+			}
+		}
+	}
+	writeFile(t, filepath.Join(dir, "go.mod"), gomod)
+	if sum, err := ioutil.ReadFile(filepath.Join(goaDir, "go.sum")); err == nil {
+		writeFile(t, filepath.Join(dir, "go.sum"), string(sum))
+	}
+	writeFile(t, filepath.Join(dir, "design", "design.go"), diffDesignV1)
+	git(t, dir, "init", "-q")
+	git(t, dir, "add", "-A")
+	git(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "v1")
+	writeFile(t, filepath.Join(dir, "design", "design.go"), diffDesignV2)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	err = runDiff("example.com/diff/design", "", "HEAD", true, false)
+	if err == nil || !strings.Contains(err.Error(), "1 breaking change(s) found") {
+		t.Errorf("got error %v, expected the removed action to be reported", err)
+	}
+	if out, _ := exec.Command("git", "-C", dir, "worktree", "list").Output(); strings.Count(string(out), "\n") != 1 {
+		t.Errorf("got worktrees:\n%s\nexpected the revision worktree to be removed", out)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %s\n%s", strings.Join(args, " "), err, out)
+	}
+}
@@ -166,3 +166,20 @@ func serialize(byfn map[string][]*design.EncodingDefinition, first string) []*de
 	}
 	return res
 }
+
+// BuildActionEncoders builds the template data needed to render the encoders and decoders of the
+// given action. encoders is nil if the action relies on the API Produces, decoders is nil if it
+// relies on the API Consumes.
+func BuildActionEncoders(a *design.ActionDefinition) (encoders, decoders []*EncoderTemplateData, err error) {
+	if a.OverridesProduces() {
+		if encoders, err = BuildEncoders(a.EffectiveProduces(), true); err != nil {
+			return nil, nil, err
+		}
+	}
+	if a.OverridesConsumes() {
+		if decoders, err = BuildEncoders(a.EffectiveConsumes(), false); err != nil {
+			return nil, nil, err
+		}
+	}
+	return encoders, decoders, nil
+}
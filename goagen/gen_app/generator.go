@@ -207,6 +207,9 @@ func (g *Generator) generateContexts() (err error) {
 				API:          g.API,
 				DefaultPkg:   g.Target,
 				Security:     a.Security,
+				Stream:       a.Stream,
+				Pagination:   a.Pagination,
+				ETag:         a.HasPreconditions(),
 			}
 			return ctxWr.Execute(&ctxData)
 		})
@@ -241,6 +244,9 @@ func (g *Generator) generateControllers() (err error) {
 		codegen.SimpleImport("context"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.SimpleImport("github.com/goadesign/goa/cors"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware/cache"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware/ratelimit"),
 		codegen.SimpleImport("regexp"),
 		codegen.SimpleImport("strconv"),
 		codegen.SimpleImport("time"),
@@ -261,6 +267,7 @@ func (g *Generator) generateControllers() (err error) {
 	for _, data := range decoders {
 		encoderImports[data.PackagePath] = true
 	}
+	g.addActionEncoderImports(encoderImports)
 	var packagePaths []string
 	for packagePath := range encoderImports {
 		if packagePath != "github.com/goadesign/goa" {
@@ -280,7 +287,7 @@ func (g *Generator) generateControllers() (err error) {
 
 	g.genfiles = append(g.genfiles, ctlFile)
 	var controllersData []*ControllerTemplateData
-	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
 		// Create file servers for all directory file servers that serve index.html.
 		fileServers := r.FileServers
 		for _, fs := range r.FileServers {
@@ -304,23 +311,17 @@ func (g *Generator) generateControllers() (err error) {
 			PreflightPaths: r.PreflightPaths(),
 			FileServers:    fileServers,
 		}
-		r.IterateActions(func(a *design.ActionDefinition) error {
-			context := fmt.Sprintf("%s%sContext", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
-			unmarshal := fmt.Sprintf("unmarshal%s%sPayload", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
-			action := map[string]interface{}{
-				"Name":             codegen.Goify(a.Name, true),
-				"DesignName":       a.Name,
-				"Routes":           a.Routes,
-				"Context":          context,
-				"Unmarshal":        unmarshal,
-				"Payload":          a.Payload,
-				"PayloadOptional":  a.PayloadOptional,
-				"PayloadMultipart": a.PayloadMultipart,
-				"Security":         a.Security,
+		err := r.IterateActions(func(a *design.ActionDefinition) error {
+			action, err := actionData(a)
+			if err != nil {
+				return err
 			}
 			data.Actions = append(data.Actions, action)
 			return nil
 		})
+		if err != nil {
+			return err
+		}
 		if len(data.Actions) > 0 || len(data.FileServers) > 0 {
 			data.Encoders = encoders
 			data.Decoders = decoders
@@ -329,10 +330,60 @@ func (g *Generator) generateControllers() (err error) {
 		}
 		return nil
 	})
+	if err != nil {
+		return
+	}
 	err = ctlWr.Execute(controllersData)
 	return
 }
 
+// actionData builds the template data needed to render the code that mounts the given action.
+func actionData(a *design.ActionDefinition) (map[string]interface{}, error) {
+	context := fmt.Sprintf("%s%sContext", codegen.Goify(a.Name, true), codegen.Goify(a.Parent.Name, true))
+	unmarshal := fmt.Sprintf("unmarshal%s%sPayload", codegen.Goify(a.Name, true), codegen.Goify(a.Parent.Name, true))
+	encoders, decoders, err := BuildActionEncoders(a)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"Name":             codegen.Goify(a.Name, true),
+		"DesignName":       a.Name,
+		"Routes":           a.Routes,
+		"Context":          context,
+		"Unmarshal":        unmarshal,
+		"Payload":          a.Payload,
+		"PayloadOptional":  a.PayloadOptional,
+		"PayloadMultipart": a.PayloadMultipart,
+		"Security":         a.Security,
+		"Deprecation":      a.EffectiveDeprecation(),
+		"RateLimit":        a.EffectiveRateLimit(),
+		"ETag":             a.EffectiveETag(),
+		"Cache":            cacheCode(a),
+		"Encoders":         encoders,
+		"Decoders":         decoders,
+	}, nil
+}
+
+// addActionEncoderImports records the packages of the encoders and decoders of the actions that
+// override the API Consumes or Produces.
+func (g *Generator) addActionEncoderImports(imports map[string]bool) {
+	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.OverridesProduces() {
+				for _, enc := range normalizeEncodingDefinitions(a.EffectiveProduces()) {
+					imports[enc.PackagePath] = true
+				}
+			}
+			if a.OverridesConsumes() {
+				for _, enc := range normalizeEncodingDefinitions(a.EffectiveConsumes()) {
+					imports[enc.PackagePath] = true
+				}
+			}
+			return nil
+		})
+	})
+}
+
 // generateControllers iterates through the API resources and generates the low level
 // controllers.
 func (g *Generator) generateSecurity() (err error) {
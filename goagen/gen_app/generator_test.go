@@ -245,6 +245,39 @@ var _ = Describe("Generate", func() {
 			})
 		})
 
+		Context("with a cached secured action", func() {
+			BeforeEach(func() {
+				scheme := &design.SecuritySchemeDefinition{SchemeName: "jwt", Kind: design.JWTSecurityKind}
+				design.Design.SecuritySchemes = []*design.SecuritySchemeDefinition{scheme}
+				get := design.Design.Resources["Widget"].Actions["get"]
+				get.Metadata = dslengine.MetadataDefinition{design.CacheTTLMetadata: {"1m"}}
+				get.Security = &design.SecurityDefinition{Scheme: scheme}
+			})
+
+			It("caches the responses per credentials", func() {
+				Ω(genErr).Should(BeNil())
+
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(`h = cache.New(time.Minute, cache.WithCredentials("header", "Authorization"), cache.WithScope("Widget.get"))(h)`))
+			})
+
+			Context("using an API key sent in a query string parameter", func() {
+				BeforeEach(func() {
+					scheme := design.Design.SecuritySchemes[0]
+					scheme.Kind, scheme.In, scheme.Name = design.APIKeySecurityKind, "query", "key"
+				})
+
+				It("caches the responses per API key", func() {
+					Ω(genErr).Should(BeNil())
+
+					content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(string(content)).Should(ContainSubstring(`h = cache.New(time.Minute, cache.WithCredentials("query", "key"), cache.WithScope("Widget.get"))(h)`))
+				})
+			})
+		})
+
 		Context("with a multipart payload", func() {
 			BeforeEach(func() {
 				elemTypeInt := &design.AttributeDefinition{Type: design.Integer}
@@ -412,7 +445,7 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		}
 		return ctrl.Get(rctx)
 	}
-	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, nil))
+	goa.HandleRoute(service.Mux, "GET", "/:id", &goa.Route{Controller: "Widget", Action: "get"}, ctrl.MuxHandler("get", h, nil))
 	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
 }
 `
@@ -478,7 +511,7 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		}
 		return ctrl.Get(rctx)
 	}
-	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload))
+	goa.HandleRoute(service.Mux, "GET", "/:id", &goa.Route{Controller: "Widget", Action: "get"}, ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload))
 	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
 }
 
@@ -515,7 +548,7 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		}
 		return ctrl.Get(rctx)
 	}
-	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload))
+	goa.HandleRoute(service.Mux, "GET", "/:id", &goa.Route{Controller: "Widget", Action: "get"}, ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload))
 	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
 }
 
@@ -554,7 +587,7 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		}
 		return ctrl.Get(rctx)
 	}
-	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload))
+	goa.HandleRoute(service.Mux, "GET", "/:id", &goa.Route{Controller: "Widget", Action: "get"}, ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload))
 	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
 }
 
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 	"text/template"
+	"time"
 
 	"sort"
 
@@ -85,13 +86,16 @@ type (
 		API          *design.APIDefinition
 		DefaultPkg   string
 		Security     *design.SecurityDefinition
+		Stream       *design.MediaTypeDefinition
+		Pagination   *design.PaginationDefinition
+		ETag         bool // true if the action accepts the "If-Match" header
 	}
 
 	// ControllerTemplateData contains the information required to generate an action handler.
 	ControllerTemplateData struct {
 		API            *design.APIDefinition          // API definition
 		Resource       string                         // Lower case plural resource name, e.g. "bottles"
-		Actions        []map[string]interface{}       // Array of actions, each action has keys "Name", "DesignName", "Routes", "Context" and "Unmarshal"
+		Actions        []map[string]interface{}       // Array of actions, each action has keys "Name", "DesignName", "Routes", "Context", "Unmarshal", "Encoders" and "Decoders"
 		FileServers    []*design.FileServerDefinition // File servers
 		Encoders       []*EncoderTemplateData         // Encoder data
 		Decoders       []*EncoderTemplateData         // Decoder data
@@ -233,13 +237,20 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			fn := template.FuncMap{
 				"finalizeCode":   w.Finalizer.Code,
 				"validationCode": w.Validator.Code,
+				"redactCode":     codegen.RedactCode,
 			}
 			if err := w.ExecuteTemplate("payload", payloadT, fn, data); err != nil {
 				return err
 			}
 		}
 	}
+	if err := w.executeHelpers(data); err != nil {
+		return err
+	}
 	return data.IterateResponses(func(resp *design.ResponseDefinition) error {
+		if data.Stream != nil && resp.MediaType == design.EventStreamMediaType {
+			return nil
+		}
 		respData := map[string]interface{}{
 			"Context":  data,
 			"Response": resp,
@@ -293,6 +304,34 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 	})
 }
 
+// executeHelpers writes the helpers used by streaming actions to send events, by paginated
+// actions to link to other pages and by actions that modify resources to check "If-Match"
+// preconditions.
+func (w *ContextsWriter) executeHelpers(data *ContextTemplateData) error {
+	if data.Stream != nil {
+		projected, _, err := data.Stream.Project(design.DefaultView)
+		if err != nil {
+			return err
+		}
+		streamData := map[string]interface{}{
+			"Context":   data,
+			"Projected": projected,
+		}
+		if err := w.ExecuteTemplate("stream", ctxStreamT, nil, streamData); err != nil {
+			return err
+		}
+	}
+	if data.Pagination != nil {
+		if err := w.ExecuteTemplate("pagination", ctxPageT, nil, data); err != nil {
+			return err
+		}
+	}
+	if data.ETag {
+		return w.ExecuteTemplate("ifmatch", ctxIfMatchT, nil, data)
+	}
+	return nil
+}
+
 // NewControllersWriter returns a handlers code writer.
 // Handlers provide the glue between the underlying request data and the user controller.
 func NewControllersWriter(filename string) (*ControllersWriter, error) {
@@ -307,6 +346,79 @@ func NewControllersWriter(filename string) (*ControllersWriter, error) {
 	}, nil
 }
 
+// HasEncodings returns true if any of the controller actions defines its own encoders or decoders.
+func (d *ControllerTemplateData) HasEncodings() bool {
+	for _, a := range d.Actions {
+		encoders, _ := a["Encoders"].([]*EncoderTemplateData)
+		decoders, _ := a["Decoders"].([]*EncoderTemplateData)
+		if len(encoders) > 0 || len(decoders) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimitCode returns the code that creates the middleware enforcing the given rate limit.
+func rateLimitCode(rl *design.RateLimitDefinition) string {
+	code := fmt.Sprintf("ratelimit.New(%d, %s", rl.Limit, durationCode(rl.Period))
+	if rl.Key != nil && rl.Key.Kind == design.RateLimitKeyHeader {
+		code += fmt.Sprintf(", ratelimit.WithKey(ratelimit.ByHeader(%q))", rl.Key.Name)
+	}
+	if rl.Scope != "" {
+		code += fmt.Sprintf(", ratelimit.WithScope(%q)", rl.Scope)
+	}
+	return code + ")"
+}
+
+// cacheCode returns the code that creates the middleware caching the responses of the given
+// action and setting their "Cache-Control" header, an empty string if the action does not need
+// it. The responses of secured actions vary on the "Authorization" header.
+func cacheCode(a *design.ActionDefinition) string {
+	ttl := a.CacheTTL()
+	cc := a.EffectiveCacheControl()
+	if ttl == 0 && cc == nil {
+		return ""
+	}
+	code := "cache.New(" + durationCode(ttl)
+	if cc != nil {
+		code += fmt.Sprintf(", cache.WithCacheControl(%q)", cc.Value())
+	}
+	if a.Security != nil {
+		in, name := "header", "Authorization"
+		if s := a.Security.Scheme; s.In != "" && s.Name != "" {
+			in, name = s.In, s.Name
+		}
+		code += fmt.Sprintf(", cache.WithCredentials(%q, %q)", in, name)
+	}
+	return code + fmt.Sprintf(", cache.WithScope(%q))", a.Parent.Name+"."+a.Name)
+}
+
+// durationCode returns the Go expression of the given duration using the largest unit that
+// divides it.
+func durationCode(d time.Duration) string {
+	if d == 0 {
+		return "0"
+	}
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+	}
+	for _, u := range units {
+		if d%u.unit == 0 {
+			if d == u.unit {
+				return u.name
+			}
+			return fmt.Sprintf("%d * %s", d/u.unit, u.name)
+		}
+	}
+	return fmt.Sprintf("time.Duration(%d)", d)
+}
+
 // WriteInitService writes the initService function
 func (w *ControllersWriter) WriteInitService(encoders, decoders []*EncoderTemplateData) error {
 	ctx := map[string]interface{}{
@@ -326,7 +438,7 @@ func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
 		if err := w.ExecuteTemplate("controller", ctrlT, nil, d); err != nil {
 			return err
 		}
-		if err := w.ExecuteTemplate("mount", mountT, nil, d); err != nil {
+		if err := w.ExecuteTemplate("mount", mountT, template.FuncMap{"rateLimitCode": rateLimitCode}, d); err != nil {
 			return err
 		}
 		if len(d.Origins) > 0 {
@@ -393,7 +505,7 @@ func NewMediaTypesWriter(filename string) (*MediaTypesWriter, error) {
 func (w *MediaTypesWriter) Execute(mt *design.MediaTypeDefinition) error {
 	var (
 		mLinks *design.UserTypeDefinition
-		fn     = template.FuncMap{"validationCode": w.Validator.Code}
+		fn     = template.FuncMap{"validationCode": w.Validator.Code, "redactCode": codegen.RedactCode}
 	)
 	err := mt.IterateViews(func(view *design.ViewDefinition) error {
 		p, links, err := mt.Project(view.Name)
@@ -435,6 +547,7 @@ func (w *UserTypesWriter) Execute(t *design.UserTypeDefinition) error {
 	fn := template.FuncMap{
 		"finalizeCode":   w.Finalizer.Code,
 		"validationCode": w.Validator.Code,
+		"redactCode":     codegen.RedactCode,
 	}
 	return w.ExecuteTemplate("types", userTypeT, fn, t)
 }
@@ -512,6 +625,7 @@ type {{ .Name }} struct {
 {{ end }}{{ end }}{{ end }}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{/*
 */}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Params.IsPrimitivePointer $name) }}*{{ end }}{{ gotyperef .Type nil 0 false }}
 {{ end }}{{ end }}{{ if .Payload }}	Payload {{ gotyperef .Payload nil 0 false }}
+{{ end }}{{ if .Stream }}	stream *goa.EventStream
 {{ end }}}
 `
 	// coerceT generates the code that coerces the generic deserialized
@@ -678,6 +792,49 @@ func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}(r {{ gotyperef .Proje
 	}
 {{ end }}	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
 }
+`
+
+	// ctxStreamT generates the helpers used to stream server-sent events.
+	// template input: map[string]interface{}
+	ctxStreamT = `// Send sends an event to the {{ .Context.ResourceName }} {{ .Context.ActionName }} action event stream.
+// The first call writes the response headers with status code 200 and the "text/event-stream"
+// content type.
+func (ctx *{{ .Context.Name }}) Send(event {{ gotyperef .Projected .Projected.AllRequired 0 false }}) error {
+	if ctx.stream == nil {
+		ctx.stream = goa.NewEventStream(ctx.ResponseData)
+	}
+	return ctx.stream.Send(event)
+}
+
+// Close ends the {{ .Context.ResourceName }} {{ .Context.ActionName }} action event stream.
+func (ctx *{{ .Context.Name }}) Close() error {
+	if ctx.stream == nil {
+		ctx.stream = goa.NewEventStream(ctx.ResponseData)
+	}
+	return ctx.stream.Close()
+}
+`
+
+	// ctxPageT generates the helper used by paginated actions to link to other pages.
+	// template input: *ContextTemplateData
+	ctxPageT = `// SetPageLinks sets the "Link" response header to the URLs of the next and previous pages of
+// results of the {{ .ResourceName }} {{ .ActionName }} action. Empty cursors omit the corresponding links.
+func (ctx *{{ .Name }}) SetPageLinks(next, prev string) {
+	if links := goa.PageLinks(ctx.RequestData.URL, "{{ .Pagination.CursorParam }}", next, prev); links != "" {
+		ctx.ResponseData.Header().Set("Link", links)
+	}
+}
+`
+
+	// ctxIfMatchT generates the helper used by actions that modify resources to check the
+	// "If-Match" request header.
+	// template input: *ContextTemplateData
+	ctxIfMatchT = `// CheckIfMatch returns a 412 Precondition Failed error if the request "If-Match" header does not
+// match the entity tag of current, the current representation of the resource or nil if the
+// resource does not exist. Call CheckIfMatch before modifying the resource.
+func (ctx *{{ .Name }}) CheckIfMatch(current interface{}) error {
+	return ctx.Service.CheckIfMatch(ctx, current)
+}
 `
 
 	// ctxTRespT generates the response helpers for responses with overridden types.
@@ -738,6 +895,11 @@ func (payload {{ gotyperef .Payload .Payload.AllRequired 0 false }}) Validate()
 {{ $validation }}
 	return
 }{{ end }}
+{{ $redaction := redactCode .Payload.AttributeDefinition "payload" }}{{ if $redaction }}
+// Redact returns a copy of the payload whose sensitive attributes are masked so that it may be logged.
+func (payload {{ gotyperef .Payload .Payload.AllRequired 0 false }}) Redact() interface{} {
+{{ $redaction }}
+}{{ end }}
 `
 	// ctrlT generates the controller interface for a given resource.
 	// template input: *ControllerTemplateData
@@ -776,11 +938,16 @@ func initService(service *goa.Service) {
 func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Controller) {
 	initService(service)
 	var h goa.Handler
-{{ $res := .Resource }}{{ if .Origins }}{{ range .PreflightPaths }}{{/*
-*/}}	service.Mux.Handle("OPTIONS", {{ printf "%q" . }}, ctrl.MuxHandler("preflight", handle{{ $res }}Origin(cors.HandlePreflight()), nil))
+{{ if .HasEncodings }}	var enc *goa.HTTPEncoder
+	var dec *goa.HTTPDecoder
+{{ end }}{{ $res := .Resource }}{{ if .Origins }}{{ range .PreflightPaths }}{{/*
+*/}}	goa.HandleRoute(service.Mux, "OPTIONS", {{ printf "%q" . }}, &goa.Route{Controller: {{ printf "%q" $res }}, Action: "preflight"}, ctrl.MuxHandler("preflight", handle{{ $res }}Origin(cors.HandlePreflight()), nil))
 {{ end }}{{ end }}{{ range .Actions }}{{ $action := . }}
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
-		// Check if there was an error loading the request
+{{ with .Deprecation }}		// Advertise the deprecation of the action
+		rw.Header().Set("Deprecation", "true")
+{{ if .SunsetHeader }}		rw.Header().Set("Sunset", {{ printf "%q" .SunsetHeader }})
+{{ end }}{{ end }}		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
 			return err
 		}
@@ -797,15 +964,27 @@ func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Co
 {{ end }}		}
 {{ end }}		return ctrl.{{ .Name }}(rctx)
 	}
-{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
+{{ with .Cache }}	h = {{ . }}(h)
+{{ end }}{{ with .ETag }}	h = middleware.{{ if .Weak }}Weak{{ end }}ETag()(h)
+{{ end }}{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
+{{ end }}{{ with .RateLimit }}	h = {{ rateLimitCode . }}(h)
 {{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
-{{ end }}{{ range .Routes }}	service.Mux.Handle("{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
+{{ end }}{{ if or .Encoders .Decoders }}	// Setup the action encoders and decoders
+{{ if .Encoders }}	enc = goa.NewHTTPEncoder()
+{{ range .Encoders }}	enc.Register({{ .PackageName }}.{{ .Function }}, "{{ join .MIMETypes "\", \"" }}")
+{{ end }}{{ range .Encoders }}{{ if .Default }}	enc.Register({{ .PackageName }}.{{ .Function }}, "*/*")
+{{ end }}{{ end }}{{ else }}	enc = nil
+{{ end }}{{ if .Decoders }}	dec = goa.NewHTTPDecoder()
+{{ range .Decoders }}	dec.Register({{ .PackageName }}.{{ .Function }}, "{{ join .MIMETypes "\", \"" }}")
+{{ end }}{{ range .Decoders }}{{ if .Default }}	dec.Register({{ .PackageName }}.{{ .Function }}, "*/*")
+{{ end }}{{ end }}{{ else }}	dec = nil
+{{ end }}{{ end }}{{ range .Routes }}	goa.HandleRoute(service.Mux, "{{ .Verb }}", {{ printf "%q" .FullPath }}, &goa.Route{Controller: {{ printf "%q" $res }}, Action: {{ printf "%q" $action.DesignName }}}, {{ if or $action.Encoders $action.Decoders }}goa.EncodingMuxHandler({{ end }}ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}){{ if or $action.Encoders $action.Decoders }}, enc, dec){{ end }})
 	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "route", {{ printf "%q" (printf "%s %s" .Verb .FullPath) }}{{ with $action.Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
 {{ end }}{{ end }}{{ range .FileServers }}
 	h = ctrl.FileHandler({{ printf "%q" .RequestPath }}, {{ printf "%q" .FilePath }})
 {{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
 {{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
-{{ end }}	service.Mux.Handle("GET", "{{ .RequestPath }}", ctrl.MuxHandler("serve", h, nil))
+{{ end }}	goa.HandleRoute(service.Mux, "GET", "{{ .RequestPath }}", &goa.Route{Controller: {{ printf "%q" $res }}, Action: "serve", File: {{ printf "%q" .FilePath }}}, ctrl.MuxHandler("serve", h, nil))
 	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "files", {{ printf "%q" .FilePath }}, "route", {{ printf "%q" (printf "GET %s" .RequestPath) }}{{ with .Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
 {{ end }}}
 `
@@ -897,6 +1076,11 @@ func (mt {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
 }
+{{ end }}{{ $redaction := redactCode .AttributeDefinition "mt" }}{{ if $redaction }}
+// Redact returns a copy of the {{$typeName}} media type instance whose sensitive attributes are masked.
+func (mt {{ gotyperef . .AllRequired 0 false }}) Redact() interface{} {
+{{ $redaction }}
+}
 {{ end }}
 `
 
@@ -908,6 +1092,10 @@ type {{ $typeName }} {{ gotypedef . 0 true false }}
 func (ut {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
+}{{ end }}{{ $redaction := redactCode .AttributeDefinition "ut" }}{{ if $redaction }}
+// Redact returns a copy of the {{$typeName}} type instance whose sensitive attributes are masked.
+func (ut {{ gotyperef . .AllRequired 0 false }}) Redact() interface{} {
+{{ $redaction }}
 }{{ end }}
 `
 
@@ -938,6 +1126,10 @@ type {{ $typeName }} {{ gotypedef . 0 true false }}
 func (ut {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
+}{{ end }}{{ $redaction := redactCode .AttributeDefinition "ut" }}{{ if $redaction }}
+// Redact returns a copy of the {{$typeName}} type instance whose sensitive attributes are masked.
+func (ut {{ gotyperef . .AllRequired 0 false }}) Redact() interface{} {
+{{ $redaction }}
 }{{ end }}
 `
 
@@ -3,6 +3,7 @@ package genapp_test
 import (
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/design/apidsl"
@@ -54,6 +55,9 @@ var _ = Describe("ContextsWriter", func() {
 			var payload *design.UserTypeDefinition
 			var responses map[string]*design.ResponseDefinition
 			var routes []*design.RouteDefinition
+			var stream *design.MediaTypeDefinition
+			var pagination *design.PaginationDefinition
+			var etag bool
 
 			var data *genapp.ContextTemplateData
 
@@ -63,6 +67,9 @@ var _ = Describe("ContextsWriter", func() {
 				payload = nil
 				responses = nil
 				routes = nil
+				stream = nil
+				pagination = nil
+				etag = false
 				data = nil
 			})
 
@@ -78,6 +85,9 @@ var _ = Describe("ContextsWriter", func() {
 					Routes:       routes,
 					API:          design.Design,
 					DefaultPkg:   "",
+					Stream:       stream,
+					Pagination:   pagination,
+					ETag:         etag,
 				}
 			})
 
@@ -180,6 +190,78 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with preconditions", func() {
+				BeforeEach(func() {
+					etag = true
+				})
+
+				It("writes the If-Match helper", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(checkIfMatch))
+				})
+			})
+
+			Context("with pagination", func() {
+				BeforeEach(func() {
+					pagination = &design.PaginationDefinition{CursorParam: "cursor", LimitParam: "limit"}
+				})
+
+				It("writes the page links helper", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(pageLinks))
+				})
+			})
+
+			Context("with a stream media type", func() {
+				BeforeEach(func() {
+					stream = &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{"foo": {Type: design.String}},
+							},
+							TypeName: "Event",
+						},
+						Identifier: "application/vnd.goa.event",
+					}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: stream.AttributeDefinition,
+						Name:                "default",
+						Parent:              stream,
+					}
+					stream.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(stream.Identifier): stream,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{"OK": {
+						Name:      "OK",
+						Status:    200,
+						MediaType: design.EventStreamMediaType,
+					}}
+				})
+
+				It("writes the event stream helpers", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("	stream *goa.EventStream\n}"))
+					Ω(written).Should(ContainSubstring(streamSend))
+					Ω(written).Should(ContainSubstring(streamClose))
+					Ω(written).ShouldNot(ContainSubstring("func (ctx *ListBottleContext) OK("))
+				})
+			})
+
 			Context("with an integer param", func() {
 				var (
 					intParam   *design.AttributeDefinition
@@ -999,11 +1081,21 @@ var _ = Describe("ControllersWriter", func() {
 			var payloads []*design.UserTypeDefinition
 			var encoders, decoders []*genapp.EncoderTemplateData
 			var origins []*design.CORSDefinition
+			var deprecation *design.DeprecationDefinition
+			var actionEncoders []*genapp.EncoderTemplateData
+			var rateLimit *design.RateLimitDefinition
+			var etag *design.ETagDefinition
+			var cacheCode string
 
 			var data []*genapp.ControllerTemplateData
 
 			BeforeEach(func() {
 				multipart = false
+				deprecation = nil
+				actionEncoders = nil
+				rateLimit = nil
+				etag = nil
+				cacheCode = ""
 				actions = nil
 				verbs = nil
 				paths = nil
@@ -1044,6 +1136,11 @@ var _ = Describe("ControllersWriter", func() {
 						"Unmarshal":        unmarshal,
 						"Payload":          payload,
 						"PayloadMultipart": multipart,
+						"Deprecation":      deprecation,
+						"Encoders":         actionEncoders,
+						"RateLimit":        rateLimit,
+						"ETag":             etag,
+						"Cache":            cacheCode,
 					}
 				}
 				if len(as) > 0 {
@@ -1088,6 +1185,119 @@ var _ = Describe("ControllersWriter", func() {
 				})
 			})
 
+			Context("with a deprecated action", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					deprecation = &design.DeprecationDefinition{
+						Reason: "use search",
+						Sunset: time.Date(2020, 6, 30, 0, 0, 0, 0, time.UTC),
+					}
+				})
+
+				It("writes the deprecation headers", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`rw.Header().Set("Deprecation", "true")`))
+					Ω(written).Should(ContainSubstring(`rw.Header().Set("Sunset", "Tue, 30 Jun 2020 00:00:00 GMT")`))
+				})
+			})
+
+			Context("with a rate limited action", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					rateLimit = &design.RateLimitDefinition{
+						Limit:  100,
+						Period: 90 * time.Second,
+						Key:    &design.RateLimitKeyDefinition{Kind: design.RateLimitKeyHeader, Name: "X-API-Key"},
+						Scope:  "bottles.list",
+					}
+				})
+
+				It("applies the rate limit middleware", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`	h = ratelimit.New(100, 90 * time.Second, ratelimit.WithKey(ratelimit.ByHeader("X-API-Key")), ratelimit.WithScope("bottles.list"))(h)`))
+				})
+			})
+
+			Context("with an action that computes entity tags", func() {
+				BeforeEach(func() {
+					actions = []string{"show"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles/:id"}
+					contexts = []string{"ShowBottleContext"}
+					etag = &design.ETagDefinition{Weak: true}
+				})
+
+				It("applies the entity tag middleware", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("	h = middleware.WeakETag()(h)\n"))
+				})
+			})
+
+			Context("with a cached action", func() {
+				BeforeEach(func() {
+					actions = []string{"show"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles/:id"}
+					contexts = []string{"ShowBottleContext"}
+					cacheCode = `cache.New(time.Minute, cache.WithScope("bottles.show"))`
+					etag = &design.ETagDefinition{}
+				})
+
+				It("applies the cache middleware before the entity tag middleware", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`	h = cache.New(time.Minute, cache.WithScope("bottles.show"))(h)
+	h = middleware.ETag()(h)
+`))
+				})
+			})
+
+			Context("with an action that defines its own encoders", func() {
+				BeforeEach(func() {
+					actions = []string{"export"}
+					verbs = []string{"GET"}
+					paths = []string{"/bottles/export"}
+					contexts = []string{"ExportBottleContext"}
+					actionEncoders = []*genapp.EncoderTemplateData{{
+						PackagePath: "github.com/acme/encoding/csv",
+						PackageName: "csv",
+						Function:    "NewEncoder",
+						MIMETypes:   []string{"text/csv"},
+						Default:     true,
+					}}
+				})
+
+				It("mounts the action with its encoders", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(encodingMount))
+				})
+			})
+
 			Context("with actions that take a payload", func() {
 				BeforeEach(func() {
 					actions = []string{"list"}
@@ -1627,6 +1837,64 @@ var _ = Describe("UserTypesWriter", func() {
 					Ω(written).Should(ContainSubstring(userTypeIncludingHash))
 				})
 			})
+
+			Context("with a user type including a sensitive attribute", func() {
+				BeforeEach(func() {
+					password := &design.AttributeDefinition{Type: design.String}
+					password.SetSensitive()
+					attDef = &design.AttributeDefinition{
+						Type: design.Object{
+							"name":     &design.AttributeDefinition{Type: design.String},
+							"password": password,
+						},
+					}
+					typeName = "Credentials"
+				})
+				It("writes the Redact method of the public type", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(userTypeRedact))
+					Ω(written).Should(ContainSubstring("func (ut *credentials) Publicize() *Credentials"))
+					Ω(written).ShouldNot(ContainSubstring("func (ut *credentials) Redact()"))
+				})
+			})
+
+			Context("with a user type including a union", func() {
+				BeforeEach(func() {
+					attDef = &design.AttributeDefinition{
+						Type: design.Object{
+							"channel": &design.AttributeDefinition{
+								Type: &design.Union{
+									Alternatives: design.Object{
+										"email": &design.AttributeDefinition{
+											Type: design.String,
+										},
+										"phone": &design.AttributeDefinition{
+											Type: design.Integer,
+										},
+									},
+								},
+							},
+						},
+						Validation: &dslengine.ValidationDefinition{
+							Required: []string{"channel"},
+						},
+					}
+					typeName = "NotificationPayload"
+				})
+				It("writes the user type including the union", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(userTypeIncludingUnion))
+				})
+			})
 		})
 	})
 })
@@ -1638,6 +1906,38 @@ type ListBottleContext struct {
 	*goa.ResponseData
 	*goa.RequestData
 }
+`
+
+	checkIfMatch = `
+func (ctx *ListBottleContext) CheckIfMatch(current interface{}) error {
+	return ctx.Service.CheckIfMatch(ctx, current)
+}
+`
+
+	pageLinks = `
+func (ctx *ListBottleContext) SetPageLinks(next, prev string) {
+	if links := goa.PageLinks(ctx.RequestData.URL, "cursor", next, prev); links != "" {
+		ctx.ResponseData.Header().Set("Link", links)
+	}
+}
+`
+
+	streamSend = `
+func (ctx *ListBottleContext) Send(event *Event) error {
+	if ctx.stream == nil {
+		ctx.stream = goa.NewEventStream(ctx.ResponseData)
+	}
+	return ctx.stream.Send(event)
+}
+`
+
+	streamClose = `
+func (ctx *ListBottleContext) Close() error {
+	if ctx.stream == nil {
+		ctx.stream = goa.NewEventStream(ctx.ResponseData)
+	}
+	return ctx.stream.Close()
+}
 `
 
 	emptyContextFactory = `
@@ -2495,7 +2795,7 @@ type PublicController interface {
 }
 `
 
-	fileServerOptionsHandler = `service.Mux.Handle("OPTIONS", "/public/star\\*star/*filepath", ctrl.MuxHandler("preflight", handlePublicOrigin(cors.HandlePreflight()), nil))`
+	fileServerOptionsHandler = `goa.HandleRoute(service.Mux, "OPTIONS", "/public/star\\*star/*filepath", &goa.Route{Controller: "Public", Action: "preflight"}, ctrl.MuxHandler("preflight", handlePublicOrigin(cors.HandlePreflight()), nil))`
 
 	simpleController = `// BottlesController is the controller interface for the Bottles actions.
 type BottlesController interface {
@@ -2506,7 +2806,7 @@ type BottlesController interface {
 
 	originsIntegration = `}
 	h = handleBottlesOrigin(h)
-	service.Mux.Handle`
+	goa.HandleRoute(service.Mux`
 
 	originsHandler = `// handleBottlesOrigin applies the CORS response headers corresponding to the origin.
 func handleBottlesOrigin(h goa.Handler) goa.Handler {
@@ -2607,7 +2907,7 @@ func MountBottlesController(service *goa.Service, ctrl BottlesController) {
 		}
 		return ctrl.List(rctx)
 	}
-	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
+	goa.HandleRoute(service.Mux, "GET", "/accounts/:accountID/bottles", &goa.Route{Controller: "Bottles", Action: "list"}, ctrl.MuxHandler("list", h, nil))
 	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
 }
 `
@@ -2628,11 +2928,34 @@ func MountBottlesController(service *goa.Service, ctrl BottlesController) {
 		}
 		return ctrl.List(rctx)
 	}
-	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
+	goa.HandleRoute(service.Mux, "GET", "/accounts/:accountID/bottles", &goa.Route{Controller: "Bottles", Action: "list"}, ctrl.MuxHandler("list", h, nil))
 	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
 }
 `
 
+	encodingMount = `	var enc *goa.HTTPEncoder
+	var dec *goa.HTTPDecoder
+
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		// Check if there was an error loading the request
+		if err := goa.ContextError(ctx); err != nil {
+			return err
+		}
+		// Build the context
+		rctx, err := NewExportBottleContext(ctx, req, service)
+		if err != nil {
+			return err
+		}
+		return ctrl.Export(rctx)
+	}
+	// Setup the action encoders and decoders
+	enc = goa.NewHTTPEncoder()
+	enc.Register(csv.NewEncoder, "text/csv")
+	enc.Register(csv.NewEncoder, "*/*")
+	dec = nil
+	goa.HandleRoute(service.Mux, "GET", "/bottles/export", &goa.Route{Controller: "Bottles", Action: "export"}, goa.EncodingMuxHandler(ctrl.MuxHandler("export", h, nil), enc, dec))
+`
+
 	multiController = `// BottlesController is the controller interface for the Bottles actions.
 type BottlesController interface {
 	goa.Muxer
@@ -2657,7 +2980,7 @@ type BottlesController interface {
 		}
 		return ctrl.List(rctx)
 	}
-	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
+	goa.HandleRoute(service.Mux, "GET", "/accounts/:accountID/bottles", &goa.Route{Controller: "Bottles", Action: "list"}, ctrl.MuxHandler("list", h, nil))
 	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
 
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
@@ -2672,7 +2995,7 @@ type BottlesController interface {
 		}
 		return ctrl.Show(rctx)
 	}
-	service.Mux.Handle("GET", "/accounts/:accountID/bottles/:id", ctrl.MuxHandler("show", h, nil))
+	goa.HandleRoute(service.Mux, "GET", "/accounts/:accountID/bottles/:id", &goa.Route{Controller: "Bottles", Action: "show"}, ctrl.MuxHandler("show", h, nil))
 	service.LogInfo("mount", "ctrl", "Bottles", "action", "Show", "route", "GET /accounts/:accountID/bottles/:id")
 }
 `
@@ -2709,6 +3032,49 @@ type SimplePayload struct {
 }
 `
 
+	userTypeRedact = `// Redact returns a copy of the Credentials type instance whose sensitive attributes are masked.
+func (ut *Credentials) Redact() interface{} {
+	res := ut
+	if res != nil {
+		v1 := *res
+		if v1.Password != nil {
+			redacted := goa.RedactedValue
+			v1.Password = &redacted
+		}
+		res = &v1
+	}
+	return res
+}`
+
+	userTypeIncludingUnion = `// NotificationPayload user type.
+type NotificationPayload struct {
+	Channel *struct {
+		Email *string ` + "`" + `form:"email,omitempty" json:"email,omitempty" yaml:"email,omitempty" xml:"email,omitempty"` + "`" + `
+		Phone *int ` + "`" + `form:"phone,omitempty" json:"phone,omitempty" yaml:"phone,omitempty" xml:"phone,omitempty"` + "`" + `
+	} ` + "`" + `form:"channel" json:"channel" yaml:"channel" xml:"channel"` + "`" + `
+}
+// Validate validates the NotificationPayload type instance.
+func (ut *NotificationPayload) Validate() (err error) {
+	if ut.Channel == nil {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `type` + "`" + `, "channel"))
+	}
+	if ut.Channel != nil {
+		{
+			count := 0
+			if ut.Channel.Email != nil {
+				count++
+			}
+			if ut.Channel.Phone != nil {
+				count++
+			}
+			if count != 1 {
+				err = goa.MergeErrors(err, goa.InvalidUnionError(` + "`" + `type.channel` + "`" + `, count, []string{"email", "phone"}))
+			}
+		}
+	}
+	return
+}`
+
 	userTypeIncludingHash = `// complexPayload user type.
 type complexPayload struct {
 	Misc map[int]*miscPayload ` + "`" + `form:"misc,omitempty" json:"misc,omitempty" yaml:"misc,omitempty" xml:"misc,omitempty"` + "`" + `
@@ -128,6 +128,7 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("io"),
 		codegen.SimpleImport("log"),
 		codegen.SimpleImport("net/url"),
 		codegen.SimpleImport("os"),
@@ -239,7 +240,7 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 				"Action":          action,
 				"Resource":        action.Parent,
 				"Package":         g.Target,
-				"HasMultiContent": len(g.API.Consumes) > 1,
+				"HasMultiContent": len(action.EffectiveConsumes()) > 1,
 			}
 			var err error
 			if action.WebSocket() {
@@ -830,7 +831,7 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 	}
 {{ end }}	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
 	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams .Action.Headers }}{{ $specialTypeResult.Output }}
-	resp, err := c.{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}(ctx, path{{ if .Action.Payload }}, {{/*
+	{{ if .Action.Stream }}stream{{ else }}resp{{ end }}, err := c.{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}(ctx, path{{ if .Action.Payload }}, {{/*
 	*/}}{{ if or .Action.Payload.Type.IsObject .Action.Payload.IsPrimitive }}&{{ end }}payload{{ else }}{{ end }}{{/*
 	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }}{{/*
 	*/}}{{ if and .Action.Payload .HasMultiContent }}, cmd.ContentType{{ end }})
@@ -838,10 +839,22 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 		goa.LogError(ctx, "failed", "err", err)
 		return err
 	}
-
+{{ if .Action.Stream }}	defer stream.Close()
+	for {
+		event, err := stream.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			goa.LogError(ctx, "failed", "err", err)
+			return err
+		}
+		goaclient.HandleEvent(event, cmd.PrettyPrint)
+	}
+{{ else }}
 	goaclient.HandleResponse(c.Client, resp, cmd.PrettyPrint)
 	return nil
-}
+{{ end }}}
 `
 
 // Takes map[string][]*design.ActionDefinition as input
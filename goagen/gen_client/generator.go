@@ -143,6 +143,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 		funcs = template.FuncMap{
 			"add":                func(a, b int) int { return a + b },
 			"cmdFieldType":       cmdFieldType,
+			"decodegotyperef":    decodeGoTypeRef,
 			"defaultPath":        defaultPath,
 			"escapeBackticks":    escapeBackticks,
 			"goify":              codegen.Goify,
@@ -249,6 +250,9 @@ func (g *Generator) generateClient(clientFile string, clientPkg string, funcs te
 	if err != nil {
 		return err
 	}
+	if encoders, decoders, err = g.addActionEncoders(encoders, decoders); err != nil {
+		return err
+	}
 	im := make(map[string]bool)
 	for _, data := range encoders {
 		im[data.PackagePath] = true
@@ -282,18 +286,94 @@ func (g *Generator) generateClient(clientFile string, clientPkg string, funcs te
 
 	// Generate
 	data := struct {
-		API      *design.APIDefinition
-		Encoders []*genapp.EncoderTemplateData
-		Decoders []*genapp.EncoderTemplateData
+		API             *design.APIDefinition
+		Encoders        []*genapp.EncoderTemplateData
+		Decoders        []*genapp.EncoderTemplateData
+		SensitiveFields []string
 	}{
-		API:      g.API,
-		Encoders: encoders,
-		Decoders: decoders,
+		API:             g.API,
+		Encoders:        encoders,
+		Decoders:        decoders,
+		SensitiveFields: sensitiveFields(g.API),
 	}
 	err = clientTmpl.Execute(file, data)
 	return
 }
 
+// sensitiveFields returns the sorted names of the sensitive attributes of the API payloads, user
+// types and media types.
+func sensitiveFields(api *design.APIDefinition) []string {
+	names := make(map[string]bool)
+	seen := make(map[*design.AttributeDefinition]bool)
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		collectSensitive(ut.AttributeDefinition, names, seen)
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		collectSensitive(mt.AttributeDefinition, names, seen)
+		return nil
+	})
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload != nil {
+				collectSensitive(a.Payload.AttributeDefinition, names, seen)
+			}
+			return nil
+		})
+	})
+	fields := make([]string, 0, len(names))
+	for n := range names {
+		fields = append(fields, n)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// collectSensitiveObject records the names of the sensitive attributes of obj recursively.
+func collectSensitiveObject(obj design.Object, names map[string]bool, seen map[*design.AttributeDefinition]bool) {
+	for n, catt := range obj {
+		if catt.IsSensitive() || hasSensitiveElem(catt) {
+			names[n] = true
+		}
+		collectSensitive(catt, names, seen)
+	}
+}
+
+// hasSensitiveElem returns true if att is an array or a hash whose elements are sensitive, the
+// dumped bodies mask the whole array or hash in this case.
+func hasSensitiveElem(att *design.AttributeDefinition) bool {
+	switch actual := att.Type.(type) {
+	case *design.Array:
+		return actual.ElemType.IsSensitive()
+	case *design.Hash:
+		return actual.ElemType.IsSensitive()
+	}
+	return false
+}
+
+// collectSensitive records the names of the sensitive child attributes of att recursively.
+func collectSensitive(att *design.AttributeDefinition, names map[string]bool, seen map[*design.AttributeDefinition]bool) {
+	if att == nil || seen[att] {
+		return
+	}
+	seen[att] = true
+	switch actual := att.Type.(type) {
+	case design.Object:
+		collectSensitiveObject(actual, names, seen)
+	case *design.Union:
+		collectSensitiveObject(actual.Alternatives, names, seen)
+	case *design.Array:
+		collectSensitive(actual.ElemType, names, seen)
+	case *design.Hash:
+		collectSensitive(actual.KeyType, names, seen)
+		collectSensitive(actual.ElemType, names, seen)
+	case *design.UserTypeDefinition:
+		collectSensitive(actual.AttributeDefinition, names, seen)
+	case *design.MediaTypeDefinition:
+		collectSensitive(actual.AttributeDefinition, names, seen)
+	}
+}
+
 func (g *Generator) generateClientResources(pkgDir, clientPkg string, funcs template.FuncMap) error {
 	err := g.API.IterateResources(func(res *design.ResourceDefinition) error {
 		return g.generateResourceClient(pkgDir, res, funcs)
@@ -347,6 +427,8 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("context"),
 		codegen.SimpleImport("golang.org/x/net/websocket"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
 	}
 	title := fmt.Sprintf("%s: %s Resource Client", g.API.Context(), res.Name)
@@ -464,6 +546,9 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		clientsTmpl   = template.Must(template.New("clients").Funcs(funcs).Parse(clientsTmpl))
 		requestsTmpl  = template.Must(template.New("requests").Funcs(funcs).Parse(requestsTmpl))
 		clientsWSTmpl = template.Must(template.New("clientsws").Funcs(funcs).Parse(clientsWSTmpl))
+		streamTmpl    = template.Must(template.New("stream").Funcs(funcs).Parse(clientsStreamTmpl))
+		pagerTmpl     = template.Must(template.New("pager").Funcs(funcs).Parse(clientsPagerTmpl))
+		stream        *design.MediaTypeDefinition
 	)
 	if action.Payload != nil {
 		params = append(params, "payload "+codegen.GoTypeRef(action.Payload, action.Payload.AllRequired(), 1, false))
@@ -493,6 +578,14 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 	if action.Security != nil {
 		signer = codegen.Goify(action.Security.Scheme.SchemeName, true)
 	}
+	if action.Stream != nil {
+		p, _, err := action.Stream.Project(design.DefaultView)
+		if err != nil {
+			return err
+		}
+		stream = p
+	}
+	consumes, accept := action.EffectiveConsumes(), acceptHeader(action)
 	data := struct {
 		Name               string
 		ResourceName       string
@@ -503,12 +596,15 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		HasPayload         bool
 		HasMultiContent    bool
 		DefaultContentType string
+		Accept             string
 		Params             string
 		ParamNames         string
 		CanonicalScheme    string
 		Signer             string
 		QueryParams        []*paramData
 		Headers            []*paramData
+		Stream             *design.MediaTypeDefinition
+		Deprecation        *design.DeprecationDefinition
 	}{
 		Name:               action.Name,
 		ResourceName:       action.Parent.Name,
@@ -517,24 +613,142 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Payload:            action.Payload,
 		PayloadMultipart:   action.PayloadMultipart,
 		HasPayload:         action.Payload != nil,
-		HasMultiContent:    len(design.Design.Consumes) > 1,
-		DefaultContentType: design.Design.Consumes[0].MIMETypes[0],
+		HasMultiContent:    len(consumes) > 1,
+		DefaultContentType: consumes[0].MIMETypes[0],
+		Accept:             accept,
 		Params:             strings.Join(params, ", "),
 		ParamNames:         strings.Join(names, ", "),
 		CanonicalScheme:    action.CanonicalScheme(),
 		Signer:             signer,
 		QueryParams:        queryParams,
 		Headers:            headers,
+		Stream:             stream,
+		Deprecation:        action.EffectiveDeprecation(),
 	}
 	if action.WebSocket() {
 		return clientsWSTmpl.Execute(file, data)
 	}
-	if err := clientsTmpl.Execute(file, data); err != nil {
+	tmpl := clientsTmpl
+	if stream != nil {
+		tmpl = streamTmpl
+	}
+	if err := tmpl.Execute(file, data); err != nil {
 		return err
 	}
+	if action.Pagination != nil && stream == nil {
+		page, err := pageType(action)
+		if err != nil {
+			return err
+		}
+		pagerData := map[string]interface{}{"Action": data, "Page": page}
+		if err := pagerTmpl.Execute(file, pagerData); err != nil {
+			return err
+		}
+	}
 	return requestsTmpl.Execute(file, data)
 }
 
+// pageType returns the projected media type of the success response of a paginated action if
+// any.
+func pageType(action *design.ActionDefinition) (*design.MediaTypeDefinition, error) {
+	for _, resp := range action.Responses {
+		if resp.Status != 200 {
+			continue
+		}
+		mt, ok := resp.Type.(*design.MediaTypeDefinition)
+		if !ok {
+			mt = design.Design.MediaTypeWithIdentifier(resp.MediaType)
+		}
+		if mt == nil {
+			return nil, nil
+		}
+		view := resp.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		return p, err
+	}
+	return nil, nil
+}
+
+// acceptHeader returns the value of the Accept header of the requests made to the given action,
+// an empty string if the action relies on the API Produces.
+func acceptHeader(action *design.ActionDefinition) string {
+	if !action.OverridesProduces() {
+		return ""
+	}
+	var mimeTypes []string
+	for _, enc := range action.EffectiveProduces() {
+		mimeTypes = append(mimeTypes, enc.MIMETypes...)
+	}
+	return strings.Join(mimeTypes, ", ")
+}
+
+// addActionEncoders appends the encoders and decoders used by the actions that override the API
+// Consumes or Produces to the given lists. The client encodes request bodies with the MIME types
+// the actions consume and decodes responses with the MIME types they produce. MIME types that
+// are already registered are skipped.
+func (g *Generator) addActionEncoders(encoders, decoders []*genapp.EncoderTemplateData) ([]*genapp.EncoderTemplateData, []*genapp.EncoderTemplateData, error) {
+	encoded, decoded := registeredMIMETypes(encoders), registeredMIMETypes(decoders)
+	var consumes, produces []*design.EncodingDefinition
+	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.OverridesConsumes() {
+				consumes = append(consumes, counterparts(a.EffectiveConsumes(), true, encoded)...)
+			}
+			if a.OverridesProduces() {
+				produces = append(produces, counterparts(a.EffectiveProduces(), false, decoded)...)
+			}
+			return nil
+		})
+	})
+	extraEncoders, err := genapp.BuildEncoders(consumes, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	extraDecoders, err := genapp.BuildEncoders(produces, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, data := range append(extraEncoders, extraDecoders...) {
+		data.Default = false
+	}
+	return append(encoders, extraEncoders...), append(decoders, extraDecoders...), nil
+}
+
+// registeredMIMETypes returns the MIME types handled by the given encoders or decoders.
+func registeredMIMETypes(data []*genapp.EncoderTemplateData) map[string]bool {
+	mimeTypes := make(map[string]bool)
+	for _, d := range data {
+		for _, m := range d.MIMETypes {
+			mimeTypes[m] = true
+		}
+	}
+	return mimeTypes
+}
+
+// counterparts returns the client encoding definitions corresponding to the given server
+// definitions: the client encodes what the server decodes and vice versa. MIME types listed in
+// registered are skipped and added to registered otherwise.
+func counterparts(defs []*design.EncodingDefinition, encoder bool, registered map[string]bool) []*design.EncodingDefinition {
+	var res []*design.EncodingDefinition
+	for _, def := range defs {
+		for _, m := range def.MIMETypes {
+			if registered[m] {
+				continue
+			}
+			registered[m] = true
+			pkg := def.PackagePath
+			if pkg == design.KnownEncoders[m] {
+				pkg = ""
+			}
+			res = append(res, &design.EncodingDefinition{MIMETypes: []string{m}, PackagePath: pkg, Encoder: encoder})
+		}
+	}
+	return res
+}
+
 // fileServerMethod returns the name of the client method for downloading assets served by the given
 // file server.
 // Note: the implementation opts for generating good names rather than names that are guaranteed to
@@ -960,11 +1174,13 @@ type {{ gotypename .Payload nil 1 false }} {{ gotypedef .Payload 0 true false }}
 `
 
 	typeDecodeTmpl = `{{ $typeName := typeName . }}{{ $funcName := printf "Decode%s" $typeName }}// {{ $funcName }} decodes the {{ $typeName }} instance encoded in resp body.
-func (c *Client) {{ $funcName }}(resp *http.Response) ({{ decodegotyperef . .AllRequired 0 false }}, error) {
-	var decoded {{ decodegotypename . .AllRequired 0 false }}
+{{ if .IsError }}// The body may either be a goa error response or a RFC 7807 problem details document.
+{{ end }}func (c *Client) {{ $funcName }}(resp *http.Response) ({{ decodegotyperef . .AllRequired 0 false }}, error) {
+{{ if .IsError }}	return c.Decoder.DecodeError(resp.Body, resp.Header.Get("Content-Type"))
+{{ else }}	var decoded {{ decodegotypename . .AllRequired 0 false }}
 	err := c.Decoder.Decode(&decoded, resp.Body, resp.Header.Get("Content-Type"))
 	return {{ if .IsObject }}&{{ end }}decoded, err
-}
+{{ end }}}
 `
 
 	pathTmpl = `{{ $funcName := printf "%sPath%s" (goify (printf "%s%s" .Route.Parent.Name (title .Route.Parent.Parent.Name)) true) ((or (and .Index (add .Index 1)) "") | printf "%v") }}{{/*
@@ -981,18 +1197,110 @@ func {{ $funcName }}({{ pathParams .Route }}) string {
 */}}{{ if $desc }}{{ multiComment $desc }}{{ else }}{{/*
 */}}// {{ $funcName }} makes a request to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource{{ end }}
 func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType string{{ end }}) (*http.Response, error) {
+	ctx = goaclient.WithAction(ctx, {{ printf "%q" .ResourceName }}, {{ printf "%q" .Name }})
 	req, err := c.New{{ $funcName }}Request(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType{{ end }})
 	if err != nil {
 		return nil, err
 	}
 	return c.Client.Do(ctx, req)
 }
+`
+
+	clientsStreamTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{ $desc := .Description }}{{/*
+*/}}{{ $streamName := printf "%sStream" $funcName }}// {{ $streamName }} iterates over the server-sent events streamed by the {{ .Name }} action of the {{ .ResourceName }} resource.
+type {{ $streamName }} struct {
+	reader  *goaclient.EventReader
+	decoder *goa.HTTPDecoder
+}
+
+// Next returns the next event sent by the server. Next returns io.EOF once the server closes the stream.
+func (s *{{ $streamName }}) Next() ({{ gotyperef .Stream .Stream.AllRequired 0 false }}, error) {
+	data, err := s.reader.Next()
+	if err != nil {
+		return nil, err
+	}
+	var event {{ gotypename .Stream .Stream.AllRequired 0 false }}
+	if err := s.decoder.Decode(&event, bytes.NewReader(data), "application/json"); err != nil {
+		return nil, err
+	}
+	return {{ if .Stream.IsObject }}&{{ end }}event, nil
+}
+
+// Close closes the stream.
+func (s *{{ $streamName }}) Close() error {
+	return s.reader.Close()
+}
+
+{{ if $desc }}{{ multiComment $desc }}{{ else }}{{/*
+*/}}// {{ $funcName }} makes a request to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource and returns the stream of events sent in the response{{ end }}
+func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType string{{ end }}) (*{{ $streamName }}, error) {
+	ctx = goaclient.WithAction(ctx, {{ printf "%q" .ResourceName }}, {{ printf "%q" .Name }})
+	req, err := c.New{{ $funcName }}Request(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType{{ end }})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Client.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		defer resp.Body.Close()
+		var body string
+		if b, err := ioutil.ReadAll(resp.Body); err == nil && len(b) > 0 {
+			body = ": " + string(b)
+		}
+		return nil, fmt.Errorf("%s%s", resp.Status, body)
+	}
+	return &{{ $streamName }}{reader: goaclient.NewEventReader(resp.Body), decoder: c.Decoder}, nil
+}
+`
+
+	clientsPagerTmpl = `{{ $funcName := goify (printf "%s%s" .Action.Name (title .Action.ResourceName)) true }}{{/*
+*/}}{{ $pagerName := printf "%sPager" $funcName }}// {{ $pagerName }} iterates over the pages of results of the {{ .Action.Name }} action of the {{ .Action.ResourceName }} resource.
+type {{ $pagerName }} struct {
+	pager  *goaclient.Pager
+	client *Client
+}
+
+{{ if .Page }}// Next returns the next page of results. Next returns io.EOF once all the pages have been retrieved.
+func (p *{{ $pagerName }}) Next() ({{ decodegotyperef .Page .Page.AllRequired 0 false }}, error) {
+	resp, err := p.pager.Next()
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		var body string
+		if b, err := ioutil.ReadAll(resp.Body); err == nil && len(b) > 0 {
+			body = ": " + string(b)
+		}
+		return nil, fmt.Errorf("%s%s", resp.Status, body)
+	}
+	return p.client.Decode{{ typeName .Page }}(resp)
+}
+{{ else }}// Next returns the response containing the next page of results. The caller is responsible for
+// closing the response body. Next returns io.EOF once all the pages have been retrieved.
+func (p *{{ $pagerName }}) Next() (*http.Response, error) {
+	return p.pager.Next()
+}
+{{ end }}
+// {{ $funcName }}Pages returns an iterator over all the pages of results of the {{ .Action.Name }} action endpoint of the {{ .Action.ResourceName }} resource.
+// Pages are retrieved by following the "next" links returned by the server.
+func (c *Client) {{ $funcName }}Pages(ctx context.Context, path string{{ if .Action.Params }}, {{ .Action.Params }}{{ end }}{{ if and .Action.HasPayload .Action.HasMultiContent }}, contentType string{{ end }}) (*{{ $pagerName }}, error) {
+	ctx = goaclient.WithAction(ctx, {{ printf "%q" .Action.ResourceName }}, {{ printf "%q" .Action.Name }})
+	req, err := c.New{{ $funcName }}Request(ctx, path{{ if .Action.ParamNames }}, {{ .Action.ParamNames }}{{ end }}{{ if and .Action.HasPayload .Action.HasMultiContent }}, contentType{{ end }})
+	if err != nil {
+		return nil, err
+	}
+	return &{{ $pagerName }}{pager: goaclient.NewPager(ctx, c.Client, req), client: c}, nil
+}
 `
 
 	clientsWSTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{ $desc := .Description }}{{/*
 */}}{{ if $desc }}{{ multiComment $desc }}{{ else }}// {{ $funcName }} establishes a websocket connection to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource{{ end }}
 func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}) (*websocket.Conn, error) {
-	scheme := c.Scheme
+{{ with .Deprecation }}	goa.LogInfo(ctx, "warning: deprecated action", "resource", {{ printf "%q" $.ResourceName }}, "action", {{ printf "%q" $.Name }}{{ if .Reason }}, "reason", {{ printf "%q" .Reason }}{{ end }}{{ if .SunsetHeader }}, "sunset", {{ printf "%q" .SunsetHeader }}{{ end }})
+{{ end }}	scheme := c.Scheme
 	if scheme == "" {
 		scheme = "{{ .CanonicalScheme }}"
 	}
@@ -1067,7 +1375,8 @@ func (c * Client) {{ .Name }}(ctx context.Context, {{ if .DirName }}filename, {{
 	requestsTmpl = `{{ $funcName := goify (printf "New%s%sRequest" (title .Name) (title .ResourceName)) true }}{{/*
 */}}// {{ $funcName }} create the request corresponding to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource.
 func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if .HasPayload }}{{ if .HasMultiContent }}, contentType string{{ end }}{{ end }}) (*http.Request, error) {
-{{ if .HasPayload }}	var body bytes.Buffer
+{{ with .Deprecation }}	goa.LogInfo(ctx, "warning: deprecated action", "resource", {{ printf "%q" $.ResourceName }}, "action", {{ printf "%q" $.Name }}{{ if .Reason }}, "reason", {{ printf "%q" .Reason }}{{ end }}{{ if .SunsetHeader }}, "sunset", {{ printf "%q" .SunsetHeader }}{{ end }})
+{{ end }}{{ if .HasPayload }}	var body bytes.Buffer
 {{ if .PayloadMultipart }}	w := multipart.NewWriter(&body)
 {{ $payload := .Payload.Definition }}
 {{ $o := .Payload.ToObject }}{{ range $name, $att := $o }}{{ if eq $att.Type.Kind 13 }}{{/*
@@ -1164,7 +1473,9 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	header.Set("{{ .Name }}", {{ $tmp }}){{ else }}
 	header.Set("{{ .Name }}", {{ .ValueName }})
 {{ end }}{{ if .CheckNil }}	}{{ end }}
-{{ end }}{{ end }}{{ if .Signer }}	if c.{{ .Signer }}Signer != nil {
+{{ end }}{{ end }}{{ if .Stream }}	req.Header.Set("Accept", "text/event-stream")
+{{ else if .Accept }}	req.Header.Set("Accept", {{ printf "%q" .Accept }})
+{{ end }}{{ if .Signer }}	if c.{{ .Signer }}Signer != nil {
 		if err := c.{{ .Signer }}Signer.Sign(req); err != nil {
 			return nil, err
 		}
@@ -1202,6 +1513,9 @@ func New(c goaclient.Doer) *Client {
 {{ end }}{{ end }}{{ range .Decoders }}{{ if .Default }}{{/*
 */}}	client.Decoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
 {{ end }}{{ end }}
+{{ end }}{{ if .SensitiveFields }}	// Mask the sensitive fields of the dumped bodies
+	client.SensitiveFields = []string{ {{ range .SensitiveFields }}{{ printf "%q" . }}, {{ end }} }
+
 {{ end }}	return client
 }
 
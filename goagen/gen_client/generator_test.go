@@ -377,6 +377,199 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with an action streaming events", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			event := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{"name": &design.AttributeDefinition{Type: design.String}},
+					},
+					TypeName: "Event",
+				},
+				Identifier: "application/vnd.event",
+			}
+			event.Views = map[string]*design.ViewDefinition{"default": {
+				AttributeDefinition: event.AttributeDefinition,
+				Name:                "default",
+				Parent:              event,
+			}}
+			design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+			design.Design = &design.APIDefinition{
+				Name:       "testapi",
+				Consumes:   design.DefaultEncoders,
+				MediaTypes: map[string]*design.MediaTypeDefinition{"application/vnd.event": event},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"watch": {
+								Name:   "watch",
+								Stream: event,
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "/events",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			watchAct := fooRes.Actions["watch"]
+			watchAct.Parent = fooRes
+			watchAct.Routes[0].Parent = watchAct
+		})
+
+		It("generates a client method returning a stream of decoded events", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (s *WatchFooStream) Next() (*Event, error) {"))
+			Ω(content).Should(ContainSubstring("func (c *Client) WatchFoo(ctx context.Context, path string) (*WatchFooStream, error) {"))
+			Ω(content).Should(ContainSubstring(`return &WatchFooStream{reader: goaclient.NewEventReader(resp.Body), decoder: c.Decoder}, nil`))
+			Ω(content).Should(ContainSubstring(`req.Header.Set("Accept", "text/event-stream")`))
+		})
+	})
+
+	Context("with a paginated action", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			o := design.Object{
+				"cursor": &design.AttributeDefinition{Type: design.String},
+				"limit":  &design.AttributeDefinition{Type: design.Integer},
+			}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name: "list",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+								QueryParams: &design.AttributeDefinition{Type: o},
+								Pagination:  &design.PaginationDefinition{CursorParam: "cursor", LimitParam: "limit"},
+								Responses: map[string]*design.ResponseDefinition{
+									"OK": {Name: "OK", Status: 200, MediaType: "text/plain"},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			listAct := fooRes.Actions["list"]
+			listAct.Parent = fooRes
+			listAct.Routes[0].Parent = listAct
+		})
+
+		It("generates a pager walking all the pages", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (p *ListFooPager) Next() (*http.Response, error) {"))
+			Ω(content).Should(ContainSubstring("func (c *Client) ListFooPages(ctx context.Context, path string, cursor *string, limit *int) (*ListFooPager, error) {"))
+			Ω(content).Should(ContainSubstring("return &ListFooPager{pager: goaclient.NewPager(ctx, c.Client, req), client: c}, nil"))
+		})
+	})
+
+	Context("with a deprecated action", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name:        "foo",
+						Deprecation: &design.DeprecationDefinition{Reason: "use bar"},
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name: "show",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("logs a warning when the action is called", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring(`goa.LogInfo(ctx, "warning: deprecated action", "resource", "foo", "action", "show", "reason", "use bar")`))
+		})
+	})
+
+	Context("with an error response", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.Design = &design.APIDefinition{
+				Name:       "testapi",
+				Consumes:   design.DefaultEncoders,
+				MediaTypes: map[string]*design.MediaTypeDefinition{design.ErrorMediaIdentifier: design.ErrorMedia},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name: "show",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+								Responses: map[string]*design.ResponseDefinition{
+									"BadRequest": {
+										Name:      "BadRequest",
+										Status:    400,
+										MediaType: design.ErrorMediaIdentifier,
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("decodes both error responses and problem details documents", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "media_types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) DecodeErrorResponse(resp *http.Response) (*goa.ErrorResponse, error) {"))
+			Ω(content).Should(ContainSubstring(`return c.Decoder.DecodeError(resp.Body, resp.Header.Get("Content-Type"))`))
+		})
+	})
+
 	Context("with an action with multiple routes", func() {
 		BeforeEach(func() {
 			design.Design = &design.APIDefinition{
@@ -566,6 +759,114 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with an action with a sensitive payload attribute", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			password := &design.AttributeDefinition{Type: design.String}
+			password.SetSensitive()
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"login": {
+								Name: "login",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "POST",
+										Path: "",
+									},
+								},
+								Payload: &design.UserTypeDefinition{
+									AttributeDefinition: &design.AttributeDefinition{
+										Type: design.Object{
+											"user":     &design.AttributeDefinition{Type: design.String},
+											"password": password,
+										},
+									},
+									TypeName: "LoginFooPayload",
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			loginAct := fooRes.Actions["login"]
+			loginAct.Parent = fooRes
+			loginAct.Routes[0].Parent = loginAct
+		})
+
+		It("masks the sensitive fields of the dumped bodies", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(c)).Should(ContainSubstring(`client.SensitiveFields = []string{"password"}`))
+		})
+	})
+
+	Context("with an action that defines its own encodings", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			testType := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"param": &design.AttributeDefinition{Type: design.Integer},
+					},
+				},
+				TypeName: "TestType",
+			}
+			design.Design = &design.APIDefinition{
+				Types: map[string]*design.UserTypeDefinition{
+					"TestType": testType,
+				},
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"import": {
+								Name: "import",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "POST",
+										Path: "",
+									},
+								},
+								Payload:  testType,
+								Consumes: []*design.EncodingDefinition{{MIMETypes: []string{"application/xml"}}},
+								Produces: []*design.EncodingDefinition{{MIMETypes: []string{"application/xml", "application/json"}, Encoder: true}},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			importAct := fooRes.Actions["import"]
+			importAct.Parent = fooRes
+			importAct.Routes[0].Parent = importAct
+		})
+
+		It("sets the request Content-Type and Accept headers", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`header.Set("Content-Type", "application/xml")`))
+			Ω(content).Should(ContainSubstring(`req.Header.Set("Accept", "application/xml, application/json")`))
+			Ω(content).ShouldNot(ContainSubstring(`contentType string`))
+		})
+
+		It("registers the action encoder", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`client.Encoder.Register(goa.NewXMLEncoder, "application/xml")`))
+		})
+	})
+
 	Context("with a multipartform action with a user type payload", func() {
 		BeforeEach(func() {
 			codegen.TempCount = 0
@@ -0,0 +1,291 @@
+package gendiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// Change kinds reported by Compare.
+const (
+	// ActionRemoved indicates that an action was removed.
+	ActionRemoved = "action_removed"
+	// RouteRemoved indicates that a route was removed from an action.
+	RouteRemoved = "route_removed"
+	// PayloadRequired indicates that an optional or missing request payload became required.
+	PayloadRequired = "payload_required"
+	// RequiredAdded indicates that a request attribute became required.
+	RequiredAdded = "required_added"
+	// EnumNarrowed indicates that values were removed from an enum validation.
+	EnumNarrowed = "enum_narrowed"
+	// ValidationNarrowed indicates that a validation was added or made stricter.
+	ValidationNarrowed = "validation_narrowed"
+	// ResponseRemoved indicates that a response status code was removed from an action.
+	ResponseRemoved = "response_removed"
+	// AttributeRemoved indicates that an attribute was removed from a response body.
+	AttributeRemoved = "attribute_removed"
+	// TypeChanged indicates that the type of an attribute changed.
+	TypeChanged = "type_changed"
+)
+
+type (
+	// Change describes a single breaking change.
+	Change struct {
+		// Kind is the kind of change, one of the constants defined in this package.
+		Kind string `json:"kind"`
+		// Action is the "resource#action" identifier of the impacted action.
+		Action string `json:"action"`
+		// Location is the path to the impacted element within the action, e.g.
+		// "payload.name" or "response 200.body.id".
+		Location string `json:"location,omitempty"`
+		// Message describes the change.
+		Message string `json:"message"`
+	}
+
+	// comparer accumulates the changes found while comparing two snapshots.
+	comparer struct {
+		action  string
+		changes []*Change
+	}
+)
+
+// Compare returns the breaking changes introduced by head compared to base sorted by action and
+// location.
+func Compare(base, head *Snapshot) []*Change {
+	var changes []*Change
+	for _, name := range sortedActions(base.Actions) {
+		c := &comparer{action: name}
+		if h, ok := head.Actions[name]; ok {
+			c.compareAction(base.Actions[name], h)
+		} else {
+			c.add(ActionRemoved, "", "action was removed")
+		}
+		changes = append(changes, c.changes...)
+	}
+	return changes
+}
+
+// WriteText writes a human readable report of the changes to w.
+func WriteText(w io.Writer, changes []*Change) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "No breaking change found.")
+		return err
+	}
+	for _, c := range changes {
+		loc := c.Action
+		if c.Location != "" {
+			loc += " " + c.Location
+		}
+		if _, err := fmt.Fprintf(w, "BREAKING %s: %s\n", loc, c.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes a JSON report of the changes to w.
+func WriteJSON(w io.Writer, changes []*Change) error {
+	if changes == nil {
+		changes = []*Change{}
+	}
+	report := struct {
+		Breaking int       `json:"breaking"`
+		Changes  []*Change `json:"changes"`
+	}{len(changes), changes}
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
+
+func (c *comparer) add(kind, location, format string, args ...interface{}) {
+	c.changes = append(c.changes, &Change{
+		Kind:     kind,
+		Action:   c.action,
+		Location: location,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *comparer) compareAction(base, head *Action) {
+	for _, r := range base.Routes {
+		if !contains(head.Routes, r) {
+			c.add(RouteRemoved, "", "route %s was removed", r)
+		}
+	}
+	c.compareRequest("params", base.Params, head.Params)
+	c.compareRequest("headers", base.Headers, head.Headers)
+	if head.PayloadRequired && (base.Payload == nil || !base.PayloadRequired) {
+		c.add(PayloadRequired, "payload", "request payload is now required")
+	}
+	c.compareRequest("payload", base.Payload, head.Payload)
+	for _, code := range sortedResponses(base.Responses) {
+		h, ok := head.Responses[code]
+		loc := "response " + code
+		if !ok {
+			c.add(ResponseRemoved, loc, "response with status %s was removed", code)
+			continue
+		}
+		c.compareResponse(loc+".body", base.Responses[code].Body, h.Body)
+	}
+}
+
+// compareRequest compares attributes sent by clients: new required attributes and stricter
+// validations break existing clients.
+func (c *comparer) compareRequest(loc string, base, head *Attribute) {
+	if head == nil {
+		return
+	}
+	if base == nil {
+		for _, r := range head.Required {
+			c.add(RequiredAdded, loc+"."+r, "attribute %s is now required", r)
+		}
+		return
+	}
+	if !c.compareType(loc, base, head) {
+		return
+	}
+	c.compareValidations(loc, base, head)
+	for _, r := range head.Required {
+		if !contains(base.Required, r) {
+			c.add(RequiredAdded, loc+"."+r, "attribute %s is now required", r)
+		}
+	}
+	for _, n := range sortedFields(base.Fields) {
+		if h, ok := head.Fields[n]; ok {
+			c.compareRequest(loc+"."+n, base.Fields[n], h)
+		}
+	}
+	c.compareRequest(loc+"[key]", base.Key, head.Key)
+	c.compareRequest(loc+"[*]", base.Elem, head.Elem)
+}
+
+// compareResponse compares attributes received by clients: removed attributes and type changes
+// break existing clients.
+func (c *comparer) compareResponse(loc string, base, head *Attribute) {
+	if base == nil {
+		return
+	}
+	if head == nil {
+		c.add(AttributeRemoved, loc, "response body was removed")
+		return
+	}
+	if !c.compareType(loc, base, head) {
+		return
+	}
+	for _, n := range sortedFields(base.Fields) {
+		h, ok := head.Fields[n]
+		if !ok {
+			c.add(AttributeRemoved, loc+"."+n, "attribute %s was removed", n)
+			continue
+		}
+		c.compareResponse(loc+"."+n, base.Fields[n], h)
+	}
+	c.compareResponse(loc+"[key]", base.Key, head.Key)
+	c.compareResponse(loc+"[*]", base.Elem, head.Elem)
+}
+
+// compareType records a change if the types of base and head differ and returns true if they
+// are identical.
+func (c *comparer) compareType(loc string, base, head *Attribute) bool {
+	if base.Type != head.Type {
+		c.add(TypeChanged, loc, "type changed from %s to %s", base.Type, head.Type)
+		return false
+	}
+	return !base.Recursive && !head.Recursive
+}
+
+func (c *comparer) compareValidations(loc string, base, head *Attribute) {
+	c.compareEnum(loc, base, head)
+	c.compareConstraints(loc, base, head)
+}
+
+func (c *comparer) compareEnum(loc string, base, head *Attribute) {
+	if len(head.Enum) == 0 {
+		return
+	}
+	if len(base.Enum) == 0 {
+		c.add(EnumNarrowed, loc, "enum validation was added")
+		return
+	}
+	var removed []interface{}
+	for _, v := range base.Enum {
+		if !containsValue(head.Enum, v) {
+			removed = append(removed, v)
+		}
+	}
+	if len(removed) > 0 {
+		c.add(EnumNarrowed, loc, "enum values %v were removed", removed)
+	}
+}
+
+func (c *comparer) compareConstraints(loc string, base, head *Attribute) {
+	if head.Format != "" && head.Format != base.Format {
+		c.add(ValidationNarrowed, loc, "format changed from %q to %q", base.Format, head.Format)
+	}
+	if head.Pattern != "" && head.Pattern != base.Pattern {
+		c.add(ValidationNarrowed, loc, "pattern changed from %q to %q", base.Pattern, head.Pattern)
+	}
+	if head.Minimum != nil && (base.Minimum == nil || *head.Minimum > *base.Minimum) {
+		c.add(ValidationNarrowed, loc, "minimum raised to %v", *head.Minimum)
+	}
+	if head.Maximum != nil && (base.Maximum == nil || *head.Maximum < *base.Maximum) {
+		c.add(ValidationNarrowed, loc, "maximum lowered to %v", *head.Maximum)
+	}
+	if head.MinLength != nil && (base.MinLength == nil || *head.MinLength > *base.MinLength) {
+		c.add(ValidationNarrowed, loc, "minimum length raised to %d", *head.MinLength)
+	}
+	if head.MaxLength != nil && (base.MaxLength == nil || *head.MaxLength < *base.MaxLength) {
+		c.add(ValidationNarrowed, loc, "maximum length lowered to %d", *head.MaxLength)
+	}
+}
+
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+// containsValue compares values loaded from JSON as well as values taken from a design.
+func containsValue(vals []interface{}, val interface{}) bool {
+	for _, v := range vals {
+		if reflect.DeepEqual(v, val) || fmt.Sprintf("%v", v) == fmt.Sprintf("%v", val) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedActions(m map[string]*Action) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedResponses(m map[string]*Response) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedFields(m map[string]*Attribute) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
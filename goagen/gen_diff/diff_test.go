@@ -0,0 +1,266 @@
+package gendiff_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	gendiff "github.com/goadesign/goa/goagen/gen_diff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// snapshot runs the given design DSL and returns the resulting snapshot.
+func snapshot(dsl func()) *gendiff.Snapshot {
+	dslengine.Reset()
+	ProjectedMediaTypes = make(MediaTypeRoot)
+	dsl()
+	Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	s, err := gendiff.NewSnapshot(Design)
+	Ω(err).ShouldNot(HaveOccurred())
+	return s
+}
+
+// design returns a design DSL whose details can be tweaked with the given options.
+func design(o designOptions) func() {
+	return func() {
+		API("test", func() {})
+		mt := MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("id", Integer)
+				Attribute("name", String)
+				if !o.noVintage {
+					Attribute("vintage", Integer)
+				}
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("name")
+				if !o.noVintage {
+					Attribute("vintage")
+				}
+			})
+		})
+		Resource("bottle", func() {
+			BasePath("/bottles")
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Params(func() {
+					if o.stringID {
+						Param("id", String)
+					} else {
+						Param("id", Integer)
+					}
+				})
+				Response(OK, mt)
+				if !o.noNotFound {
+					Response(NotFound)
+				}
+			})
+			Action("create", func() {
+				Routing(POST(""))
+				if !o.noPut {
+					Routing(PUT(""))
+				}
+				Payload(func() {
+					Attribute("name", String, func() {
+						if o.maxLength > 0 {
+							MaxLength(o.maxLength)
+						} else {
+							MaxLength(100)
+						}
+					})
+					Attribute("color", String, func() {
+						if o.narrowEnum {
+							Enum("red", "white")
+						} else {
+							Enum("red", "white", "rose")
+						}
+					})
+					Required("name")
+					if o.requireColor {
+						Required("color")
+					}
+				})
+				Response(Created)
+			})
+			if !o.noDelete {
+				Action("delete", func() {
+					Routing(DELETE("/:id"))
+					Response(NoContent)
+				})
+			}
+		})
+	}
+}
+
+type designOptions struct {
+	noVintage, stringID, noNotFound, noPut, narrowEnum, requireColor, noDelete bool
+	maxLength                                                                  int
+}
+
+var _ = Describe("Compare", func() {
+	var base, head designOptions
+	var changes []*gendiff.Change
+
+	BeforeEach(func() {
+		base = designOptions{}
+		head = designOptions{}
+	})
+
+	JustBeforeEach(func() {
+		b := snapshot(design(base))
+		h := snapshot(design(head))
+		changes = gendiff.Compare(b, h)
+	})
+
+	Context("with identical designs", func() {
+		It("does not report any change", func() {
+			Ω(changes).Should(BeEmpty())
+		})
+	})
+
+	Context("with backward compatible changes", func() {
+		BeforeEach(func() {
+			base = designOptions{noDelete: true, narrowEnum: true, maxLength: 50, requireColor: true}
+		})
+
+		It("does not report any change", func() {
+			Ω(changes).Should(BeEmpty())
+		})
+	})
+
+	Context("with a removed action", func() {
+		BeforeEach(func() {
+			head.noDelete = true
+		})
+
+		It("reports the removed action", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Kind).Should(Equal(gendiff.ActionRemoved))
+			Ω(changes[0].Action).Should(Equal("bottle#delete"))
+		})
+	})
+
+	Context("with a removed route", func() {
+		BeforeEach(func() {
+			head.noPut = true
+		})
+
+		It("reports the removed route", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Kind).Should(Equal(gendiff.RouteRemoved))
+			Ω(changes[0].Message).Should(ContainSubstring("PUT /bottles"))
+		})
+	})
+
+	Context("with a new required payload attribute", func() {
+		BeforeEach(func() {
+			head.requireColor = true
+		})
+
+		It("reports the required attribute", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Kind).Should(Equal(gendiff.RequiredAdded))
+			Ω(changes[0].Location).Should(Equal("payload.color"))
+		})
+	})
+
+	Context("with narrowed validations", func() {
+		BeforeEach(func() {
+			head.narrowEnum = true
+			head.maxLength = 50
+		})
+
+		It("reports the narrowed enum and max length", func() {
+			Ω(changes).Should(HaveLen(2))
+			Ω(changes[0].Kind).Should(Equal(gendiff.EnumNarrowed))
+			Ω(changes[0].Location).Should(Equal("payload.color"))
+			Ω(changes[0].Message).Should(ContainSubstring("rose"))
+			Ω(changes[1].Kind).Should(Equal(gendiff.ValidationNarrowed))
+			Ω(changes[1].Location).Should(Equal("payload.name"))
+		})
+	})
+
+	Context("with a removed response", func() {
+		BeforeEach(func() {
+			head.noNotFound = true
+		})
+
+		It("reports the removed response", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Kind).Should(Equal(gendiff.ResponseRemoved))
+			Ω(changes[0].Location).Should(Equal("response 404"))
+		})
+	})
+
+	Context("with a removed response attribute", func() {
+		BeforeEach(func() {
+			head.noVintage = true
+		})
+
+		It("reports the removed attribute", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Kind).Should(Equal(gendiff.AttributeRemoved))
+			Ω(changes[0].Location).Should(Equal("response 200.body.vintage"))
+		})
+	})
+
+	Context("with a changed type", func() {
+		BeforeEach(func() {
+			head.stringID = true
+		})
+
+		It("reports the type change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Kind).Should(Equal(gendiff.TypeChanged))
+			Ω(changes[0].Location).Should(Equal("params.id"))
+		})
+	})
+})
+
+var _ = Describe("Generator", func() {
+	var outDir string
+
+	BeforeEach(func() {
+		var err error
+		outDir, err = ioutil.TempDir("", "gendiff")
+		Ω(err).ShouldNot(HaveOccurred())
+		dslengine.Reset()
+		design(designOptions{})()
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+	})
+
+	It("writes a snapshot that can be loaded back", func() {
+		g := gendiff.NewGenerator(gendiff.API(Design), gendiff.OutDir(outDir))
+		files, err := g.Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(files).Should(Equal([]string{filepath.Join(outDir, gendiff.SnapshotFile)}))
+
+		loaded, err := gendiff.LoadSnapshot(files[0])
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(gendiff.Compare(loaded, snapshot(design(designOptions{})))).Should(BeEmpty())
+		Ω(gendiff.Compare(snapshot(design(designOptions{})), loaded)).Should(BeEmpty())
+	})
+})
+
+var _ = Describe("WriteJSON", func() {
+	It("writes the number of breaking changes", func() {
+		var buf bytes.Buffer
+		changes := []*gendiff.Change{{Kind: gendiff.ActionRemoved, Action: "bottle#delete", Message: "action was removed"}}
+		Ω(gendiff.WriteJSON(&buf, changes)).ShouldNot(HaveOccurred())
+		var report map[string]interface{}
+		Ω(json.Unmarshal(buf.Bytes(), &report)).ShouldNot(HaveOccurred())
+		Ω(report["breaking"]).Should(BeEquivalentTo(1))
+		Ω(report["changes"]).Should(HaveLen(1))
+	})
+})
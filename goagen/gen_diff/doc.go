@@ -0,0 +1,10 @@
+/*
+Package gendiff provides a generator that snapshots the client facing parts of an API design and
+the logic that compares two snapshots to detect breaking changes.
+
+The generator writes the snapshot of the design in the file "design.json". The "goagen diff"
+command runs the generator against two versions of a design package (or two git revisions of the
+same package) and reports the breaking changes: removed actions or routes, new required request
+attributes, narrowed enums or validations, removed response codes and changed types.
+*/
+package gendiff
@@ -0,0 +1,13 @@
+package gendiff_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenDiff(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenDiff Suite")
+}
@@ -0,0 +1,230 @@
+package gendiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+type (
+	// Snapshot is a serializable description of the parts of an API design that clients depend
+	// on. Snapshots of two versions of a design are compared to detect breaking changes.
+	Snapshot struct {
+		// Name is the API name.
+		Name string `json:"name"`
+		// Actions lists the API actions indexed by "resource#action".
+		Actions map[string]*Action `json:"actions"`
+	}
+
+	// Action describes the request and responses of a single action.
+	Action struct {
+		// Routes lists the action routes formatted as "VERB path".
+		Routes []string `json:"routes"`
+		// Params describes the path and query string parameters.
+		Params *Attribute `json:"params,omitempty"`
+		// Headers describes the request headers.
+		Headers *Attribute `json:"headers,omitempty"`
+		// Payload describes the request body.
+		Payload *Attribute `json:"payload,omitempty"`
+		// PayloadRequired is true if the request body is required.
+		PayloadRequired bool `json:"payloadRequired,omitempty"`
+		// Responses lists the action responses indexed by HTTP status code.
+		Responses map[string]*Response `json:"responses"`
+	}
+
+	// Response describes a single action response.
+	Response struct {
+		// MediaType is the response media type identifier if any.
+		MediaType string `json:"mediaType,omitempty"`
+		// Body describes the response body if any.
+		Body *Attribute `json:"body,omitempty"`
+	}
+
+	// Attribute describes a data structure together with its validations.
+	Attribute struct {
+		// Type is the name of the attribute type, e.g. "string", "array" or "object".
+		Type string `json:"type"`
+		// TypeName is the name of the user or media type if any.
+		TypeName string `json:"typeName,omitempty"`
+		// Recursive is true if the attribute refers to a user type that is already being
+		// described by a parent attribute. Fields are omitted in this case.
+		Recursive bool `json:"recursive,omitempty"`
+		// Fields lists the child attributes of objects and unions indexed by name.
+		Fields map[string]*Attribute `json:"fields,omitempty"`
+		// Elem describes the elements of arrays and hashes.
+		Elem *Attribute `json:"elem,omitempty"`
+		// Key describes the keys of hashes.
+		Key *Attribute `json:"key,omitempty"`
+		// Required lists the names of the required fields.
+		Required []string `json:"required,omitempty"`
+		// Enum lists the accepted values.
+		Enum []interface{} `json:"enum,omitempty"`
+		// Format is the format validation.
+		Format string `json:"format,omitempty"`
+		// Pattern is the regular expression validation.
+		Pattern string `json:"pattern,omitempty"`
+		// Minimum is the minimum value validation.
+		Minimum *float64 `json:"minimum,omitempty"`
+		// Maximum is the maximum value validation.
+		Maximum *float64 `json:"maximum,omitempty"`
+		// MinLength is the minimum length validation.
+		MinLength *int `json:"minLength,omitempty"`
+		// MaxLength is the maximum length validation.
+		MaxLength *int `json:"maxLength,omitempty"`
+	}
+)
+
+// NewSnapshot creates a snapshot of the given API definition.
+func NewSnapshot(api *design.APIDefinition) (*Snapshot, error) {
+	s := &Snapshot{Name: api.Name, Actions: make(map[string]*Action)}
+	err := api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			action, err := newAction(api, a)
+			if err != nil {
+				return err
+			}
+			s.Actions[fmt.Sprintf("%s#%s", r.Name, a.Name)] = action
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadSnapshot reads the snapshot serialized in the file at the given path.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("invalid design snapshot %s: %s", path, err)
+	}
+	return &s, nil
+}
+
+func newAction(api *design.APIDefinition, a *design.ActionDefinition) (*Action, error) {
+	action := &Action{
+		Params:    newAttribute(a.AllParams(), nil),
+		Headers:   newAttribute(actionHeaders(a), nil),
+		Responses: make(map[string]*Response),
+	}
+	for _, r := range a.Routes {
+		action.Routes = append(action.Routes, fmt.Sprintf("%s %s", r.Verb, r.FullPath()))
+	}
+	sort.Strings(action.Routes)
+	if a.Payload != nil {
+		action.Payload = newAttribute(&design.AttributeDefinition{Type: a.Payload}, nil)
+		action.PayloadRequired = !a.PayloadOptional
+	}
+	err := a.IterateResponses(func(r *design.ResponseDefinition) error {
+		resp := &Response{MediaType: r.MediaType}
+		if mt, ok := api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]; ok {
+			view := r.ViewName
+			if view == "" {
+				view = design.DefaultView
+			}
+			p, _, err := mt.Project(view)
+			if err != nil {
+				return err
+			}
+			resp.Body = newAttribute(&design.AttributeDefinition{Type: p}, nil)
+		} else if r.Type != nil {
+			resp.Body = newAttribute(&design.AttributeDefinition{Type: r.Type}, nil)
+		}
+		action.Responses[strconv.Itoa(r.Status)] = resp
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+// actionHeaders returns an attribute describing both the resource and action headers.
+func actionHeaders(a *design.ActionDefinition) *design.AttributeDefinition {
+	headers := make(design.Object)
+	var required []string
+	a.IterateHeaders(func(name string, isRequired bool, h *design.AttributeDefinition) error {
+		headers[name] = h
+		if isRequired {
+			required = append(required, name)
+		}
+		return nil
+	})
+	if len(headers) == 0 {
+		return nil
+	}
+	att := &design.AttributeDefinition{Type: headers}
+	if len(required) > 0 {
+		att.Validation = &dslengine.ValidationDefinition{Required: required}
+	}
+	return att
+}
+
+// newAttribute describes the given attribute. seen lists the user types being described by
+// the parent attributes and is used to break infinite recursions.
+func newAttribute(att *design.AttributeDefinition, seen []string) *Attribute {
+	if att == nil || att.Type == nil {
+		return nil
+	}
+	a := &Attribute{Type: att.Type.Name()}
+	if v := att.Validation; v != nil {
+		a.Enum = v.Values
+		a.Format = v.Format
+		a.Pattern = v.Pattern
+		a.Minimum = v.Minimum
+		a.Maximum = v.Maximum
+		a.MinLength = v.MinLength
+		a.MaxLength = v.MaxLength
+	}
+	t := att.Type
+	switch actual := t.(type) {
+	case *design.MediaTypeDefinition:
+		a.TypeName = actual.TypeName
+		t = actual.Type
+		att = actual.AttributeDefinition
+	case *design.UserTypeDefinition:
+		a.TypeName = actual.TypeName
+		t = actual.Type
+		att = actual.AttributeDefinition
+	}
+	if a.TypeName != "" {
+		for _, s := range seen {
+			if s == a.TypeName {
+				a.Recursive = true
+				return a
+			}
+		}
+		seen = append(seen, a.TypeName)
+	}
+	if t == nil {
+		return a
+	}
+	a.Type = t.Name()
+	switch actual := t.(type) {
+	case *design.Array:
+		a.Elem = newAttribute(actual.ElemType, seen)
+	case *design.Hash:
+		a.Key = newAttribute(actual.KeyType, seen)
+		a.Elem = newAttribute(actual.ElemType, seen)
+	default:
+		if o := t.ToObject(); o != nil {
+			a.Fields = make(map[string]*Attribute, len(o))
+			for n, f := range o {
+				a.Fields[n] = newAttribute(f, seen)
+			}
+			a.Required = att.AllRequired()
+			sort.Strings(a.Required)
+		}
+	}
+	return a
+}
@@ -0,0 +1,11 @@
+/*
+Package genimport provides a generator that creates a design package from an existing Swagger 2.0
+or OpenAPI 3 specification.
+
+The definitions (or component schemas) are mapped to types and media types, the paths to resources
+and actions and the validations to the corresponding attribute DSL. The parts of the specification
+that cannot be represented with the design language are written as TODO comments in the generated
+code. The generated package is meant to be reviewed and edited before being used to generate the
+service code.
+*/
+package genimport
@@ -0,0 +1,1146 @@
+package genimport
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+type (
+	// importer writes the design DSL corresponding to a specification.
+	importer struct {
+		spec *spec
+		// types lists the user types indexed by component schema name.
+		types map[string]*userType
+		// typeNames lists the component schema names of the user types in order.
+		typeNames []string
+		// extra lists the user types created for inline schemas that cannot be described
+		// inline in the DSL such as array elements and response bodies.
+		extra []*userType
+		// varNames records the Go variable names already in use.
+		varNames map[string]bool
+		// deps lists the user types referred to by each user type.
+		deps map[string]map[string]bool
+		// statuses maps HTTP status codes to the goa response names.
+		statuses map[int]string
+		// security lists the Go variable names of the supported security schemes.
+		security map[string]string
+		// current is the component schema name of the user type being written if any.
+		current string
+	}
+
+	// userType describes a Type or MediaType definition.
+	userType struct {
+		// ref is the component schema name, empty for types created for inline schemas.
+		ref string
+		// name is the type name.
+		name string
+		// varName is the name of the Go variable holding the definition.
+		varName string
+		// identifier is the media type identifier, empty for types.
+		identifier string
+		// schema describes the type.
+		schema *schema
+		// byName is true if the type must be referred to by name to avoid initialization
+		// cycles.
+		byName bool
+		// builtin is true if the type is described by a media type of the design package and
+		// thus is not written.
+		builtin bool
+	}
+)
+
+var (
+	// problemMembers lists the members of the RFC 7807 problem details documents.
+	problemMembers = map[string]bool{"type": true, "title": true, "status": true, "detail": true, "instance": true}
+
+	// pathParamRegex matches the OpenAPI path parameters.
+	pathParamRegex = regexp.MustCompile(`{([^}]+)}`)
+
+	// dslPackages lists the packages dot imported by the generated design.
+	dslPackages = []string{"github.com/goadesign/goa/design", "github.com/goadesign/goa/design/apidsl"}
+
+	// dslNames lists the identifiers exported by the dot imported packages, the generated
+	// variables must not conflict with them. The list is fixed so that the generated design does
+	// not depend on the goa sources available when running the generator.
+	dslNames = []string{
+		"API", "APIDefinition", "APIKeySecurity", "APIKeySecurityKind", "Accepted",
+		"AccessCodeFlow", "Action", "ActionDefinition", "ActionIterator", "Any", "AnyKind",
+		"ApplicationFlow", "Array", "ArrayKind", "ArrayOf", "ArrayVal", "Attribute",
+		"AttributeDefinition", "AttributeIterator", "Attributes", "BadGateway", "BadRequest",
+		"BasePath", "BasicAuthSecurity", "BasicAuthSecurityKind", "Boolean", "BooleanKind",
+		"ByFilePath", "ByHeader", "ByIP", "CONNECT", "CORSDefinition", "CacheControl",
+		"CacheControlDefinition", "CacheTTLMetadata", "CanonicalActionName", "CanonicalIdentifier",
+		"CollectionOf", "Conflict", "Consumes", "Contact", "ContactDefinition",
+		"ContainerDefinition", "ContentType", "Continue", "Created", "Credentials", "DELETE",
+		"DataStructure", "DataType", "DateTime", "DateTimeKind", "Default", "DefaultDecoders",
+		"DefaultEncoders", "DefaultMedia", "DefaultView", "Deprecated", "DeprecationDefinition",
+		"Description", "Design", "Docs", "DocsDefinition", "Dup", "DupAtt", "ETag",
+		"ETagDefinition", "Email", "EncodingDefinition", "Enum", "ErrorMedia",
+		"ErrorMediaIdentifier", "EventStreamMediaType", "Example", "ExpectationFailed", "Expose",
+		"ExtractWildcards", "File", "FileKind", "FileServerDefinition", "FileServerIterator",
+		"Files", "Forbidden", "Format", "Found", "Function", "GET", "GatewayTimeout",
+		"GeneratedMediaTypes", "GobContentTypes", "Gone", "HEAD", "HTTPVersionNotSupported",
+		"HasFile", "HasKnownEncoder", "Hash", "HashKind", "HashOf", "HashVal", "Header",
+		"HeaderIterator", "Headers", "HealthChecks", "HealthDefinition", "Host", "ImplicitFlow",
+		"Integer", "IntegerKind", "InternalServerError", "JSONContentTypes", "JWTSecurity",
+		"JWTSecurityKind", "Kind", "KnownEncoderFunctions", "KnownEncoders", "LengthRequired",
+		"License", "LicenseDefinition", "Link", "LinkDefinition", "Links", "MaxAge", "MaxLength",
+		"Maximum", "Media", "MediaType", "MediaTypeDefinition", "MediaTypeIterator",
+		"MediaTypeKind", "MediaTypeRoot", "Member", "Metadata", "MethodNotAllowed", "Methods",
+		"MinLength", "Minimum", "MovedPermanently", "MultipartForm", "MultipleChoices", "Name",
+		"NewAPIDefinition", "NewMediaTypeDefinition", "NewRandomGenerator", "NewResourceDefinition",
+		"NewUserTypeDefinition", "NoContent", "NoExample", "NoSecurity", "NoSecurityKind",
+		"NonAuthoritativeInfo", "NotAcceptable", "NotFound", "NotImplemented", "NotModified",
+		"Number", "NumberKind", "OAuth2Security", "OAuth2SecurityKind", "OK", "OPTIONS", "Object",
+		"ObjectKind", "OneOf", "OptionalPayload", "Origin", "PATCH", "POST", "PUT", "Package",
+		"Paginated", "PaginationDefinition", "Param", "Params", "Parent", "PartialContent",
+		"PasswordFlow", "Pattern", "Payload", "PaymentRequired", "PreconditionFailed", "Primitive",
+		"ProblemDetails", "ProblemMedia", "ProblemMediaIdentifier", "Produces",
+		"ProjectedMediaTypes", "ProxyAuthRequired", "Query", "RandomGenerator", "RateLimit",
+		"RateLimitDefinition", "RateLimitKeyDefinition", "RateLimitKeyHeader", "RateLimitKeyIP",
+		"RateLimitKeyKind", "ReadOnly", "Reference", "RequestEntityTooLarge", "RequestTimeout",
+		"RequestURITooLong", "RequestedRangeNotSatisfiable", "Required", "ResetContent", "Resource",
+		"ResourceDefinition", "ResourceIterator", "Response", "ResponseDefinition",
+		"ResponseIterator", "ResponseTemplate", "ResponseTemplateDefinition", "RouteDefinition",
+		"Routing", "Scheme", "Scope", "Security", "SecurityDefinition", "SecuritySchemeDefinition",
+		"SecuritySchemeKind", "SeeOther", "Sensitive", "ServiceUnavailable", "Status", "Stream",
+		"String", "StringKind", "SupportedValidationFormats", "SwitchingProtocols", "TRACE",
+		"Teapot", "TemporaryRedirect", "TermsOfService", "Title", "TokenURL", "Trait", "Type",
+		"TypeName", "URL", "UUID", "UUIDKind", "Unauthorized", "Union", "UnionKind",
+		"UnprocessableEntity", "UnsupportedMediaType", "UseProxy", "UseTrait", "UserTypeDefinition",
+		"UserTypeIterator", "UserTypeKind", "UserTypes", "Version", "View", "ViewDefinition",
+		"ViewIterator", "WeakETag", "WildcardRegex", "XMLContentTypes",
+	}
+)
+
+// newImporter initializes an importer for the given specification.
+func newImporter(s *spec) *importer {
+	imp := &importer{
+		spec:     s,
+		types:    make(map[string]*userType),
+		varNames: make(map[string]bool),
+		deps:     make(map[string]map[string]bool),
+		statuses: make(map[int]string),
+		security: make(map[string]string),
+	}
+	for n, r := range design.NewAPIDefinition().DefaultResponses {
+		imp.statuses[r.Status] = n
+		imp.varNames[n] = true
+	}
+	for _, n := range dslNames {
+		imp.varNames[n] = true
+	}
+	return imp
+}
+
+// Write returns the formatted source code of the design package.
+func (imp *importer) Write(pkg, source string) ([]byte, error) {
+	imp.analyze()
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Package %s contains the API design imported from %s.\n", pkg, source)
+	fmt.Fprintf(&buf, "// Review the TODO comments for the parts of the specification that could not be imported.\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	buf.WriteString("import (\n")
+	for _, path := range dslPackages {
+		fmt.Fprintf(&buf, "\t. %q\n", path)
+	}
+	buf.WriteString(")\n\n")
+	var sec bytes.Buffer
+	imp.writeSecuritySchemes(&sec)
+	imp.writeAPI(&buf)
+	buf.Write(sec.Bytes())
+	imp.writeResources(&buf)
+	for _, n := range imp.typeNames {
+		imp.writeUserType(&buf, imp.types[n])
+	}
+	// writing user types may create more extra types
+	for i := 0; i < len(imp.extra); i++ {
+		imp.writeUserType(&buf, imp.extra[i])
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated design: %s\n%s", err, buf.String())
+	}
+	return code, nil
+}
+
+// analyze creates the user types for the component schemas describing objects and computes how
+// they must be referred to.
+func (imp *importer) analyze() {
+	mediaTypes := make(map[string]bool)
+	imp.eachOperation(func(_, _ string, o *operation) {
+		for _, code := range o.Responses.Names {
+			sc, _ := jsonSchema(imp.response(o.Responses.Map[code]).Content)
+			if sc == nil {
+				continue
+			}
+			if sc.Items != nil {
+				sc = sc.Items
+			}
+			if sc.Ref != "" {
+				mediaTypes[refName(sc.Ref)] = true
+			}
+		}
+	})
+	schemas := imp.spec.Components.Schemas
+	for _, n := range schemas.Names {
+		sc := schemas.Map[n]
+		if !imp.isObject(sc) {
+			continue
+		}
+		ut := &userType{ref: n, name: codegen.Goify(n, true), schema: sc}
+		if mediaTypes[n] && imp.isProblem(sc) {
+			ut.identifier = design.ProblemMediaIdentifier
+			ut.varName = "ProblemMedia"
+			ut.builtin = true
+		} else if mediaTypes[n] {
+			ut.identifier = "application/vnd." + codegen.KebabCase(n)
+			ut.varName = imp.varName(ut.name + "Media")
+		} else {
+			ut.varName = imp.varName(ut.name)
+		}
+		imp.types[n] = ut
+		imp.typeNames = append(imp.typeNames, n)
+	}
+	for _, n := range imp.typeNames {
+		deps := make(map[string]bool)
+		imp.collectDeps(imp.types[n].schema, deps, make(map[*schema]bool))
+		imp.deps[n] = deps
+	}
+}
+
+// collectDeps records the user types referred to by sc in deps.
+func (imp *importer) collectDeps(sc *schema, deps map[string]bool, seen map[*schema]bool) {
+	if sc == nil || seen[sc] {
+		return
+	}
+	seen[sc] = true
+	if sc.Ref != "" {
+		n := refName(sc.Ref)
+		if _, ok := imp.types[n]; ok {
+			deps[n] = true
+			return
+		}
+		imp.collectDeps(imp.spec.Components.Schemas.Map[n], deps, seen)
+		return
+	}
+	for _, p := range sc.Properties.Map {
+		imp.collectDeps(p, deps, seen)
+	}
+	for _, s := range sc.AllOf {
+		imp.collectDeps(s, deps, seen)
+	}
+	imp.collectDeps(sc.Items, deps, seen)
+	imp.collectDeps(sc.additionalProperties(), deps, seen)
+}
+
+// reaches returns true if the user type from refers to the user type to directly or indirectly.
+func (imp *importer) reaches(from, to string, seen map[string]bool) bool {
+	if seen[from] {
+		return false
+	}
+	seen[from] = true
+	for d := range imp.deps[from] {
+		if d == to || imp.reaches(d, to, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// varName returns a unique Go variable name derived from name.
+func (imp *importer) varName(name string) string {
+	v := name
+	for i := 2; imp.varNames[v]; i++ {
+		v = fmt.Sprintf("%s%d", name, i)
+	}
+	imp.varNames[v] = true
+	return v
+}
+
+// isObject returns true if the schema describes an object with known properties.
+func (imp *importer) isObject(sc *schema) bool {
+	if sc == nil || sc.Ref != "" || len(sc.OneOf) > 0 || len(sc.AnyOf) > 0 {
+		return false
+	}
+	if len(sc.AllOf) > 0 || len(sc.Properties.Names) > 0 {
+		return true
+	}
+	for _, t := range sc.types() {
+		if t == "object" {
+			return sc.additionalProperties() == nil
+		}
+	}
+	return false
+}
+
+// isProblem returns true if sc describes an object whose properties are all problem details
+// members. Responses with such bodies use the design package ProblemMedia media type.
+func (imp *importer) isProblem(sc *schema) bool {
+	sc = imp.flatten(sc)
+	if len(sc.Properties.Names) == 0 || sc.additionalProperties() != nil {
+		return false
+	}
+	for _, n := range sc.Properties.Names {
+		if !problemMembers[n] {
+			return false
+		}
+	}
+	return true
+}
+
+// flatten merges the properties of the allOf schemas into a single object schema.
+func (imp *importer) flatten(sc *schema) *schema {
+	if len(sc.AllOf) == 0 {
+		return sc
+	}
+	res := *sc
+	res.AllOf = nil
+	res.Required = nil
+	res.Properties = schemas{Map: make(map[string]*schema)}
+	add := func(s *schema) {
+		for _, n := range s.Properties.Names {
+			if _, ok := res.Properties.Map[n]; !ok {
+				res.Properties.Names = append(res.Properties.Names, n)
+			}
+			res.Properties.Map[n] = s.Properties.Map[n]
+		}
+		res.Required = append(res.Required, s.Required...)
+	}
+	for _, s := range sc.AllOf {
+		for s != nil && s.Ref != "" {
+			s = imp.spec.Components.Schemas.Map[refName(s.Ref)]
+		}
+		if s != nil {
+			add(imp.flatten(s))
+		}
+	}
+	add(sc)
+	return &res
+}
+
+// eachOperation calls fn for each operation of the specification in order.
+func (imp *importer) eachOperation(fn func(path, method string, o *operation)) {
+	for _, p := range imp.spec.Paths.Names {
+		item := imp.spec.Paths.Map[p]
+		for _, m := range httpMethods {
+			if o := item.operation(m); o != nil {
+				fn(p, m, o)
+			}
+		}
+	}
+}
+
+// response resolves the response reference if any.
+func (imp *importer) response(r *response) *response {
+	for r != nil && r.Ref != "" {
+		r = imp.spec.Components.Responses[refName(r.Ref)]
+	}
+	if r == nil {
+		return &response{}
+	}
+	return r
+}
+
+// parameter resolves the parameter reference if any.
+func (imp *importer) parameter(p *parameter) *parameter {
+	for p != nil && p.Ref != "" {
+		p = imp.spec.Components.Parameters[refName(p.Ref)]
+	}
+	return p
+}
+
+// requestBody resolves the request body reference if any.
+func (imp *importer) requestBody(b *requestBody) *requestBody {
+	for b != nil && b.Ref != "" {
+		b = imp.spec.Components.RequestBodies[refName(b.Ref)]
+	}
+	return b
+}
+
+func (imp *importer) writeAPI(w *bytes.Buffer) {
+	s := imp.spec
+	name := s.Info.Title
+	if name == "" {
+		name = "api"
+	}
+	fmt.Fprintf(w, "var _ = API(%q, func() {\n", name)
+	if s.Info.Title != "" {
+		fmt.Fprintf(w, "Title(%q)\n", s.Info.Title)
+	}
+	if s.Info.Description != "" {
+		fmt.Fprintf(w, "Description(%q)\n", s.Info.Description)
+	}
+	if s.Info.Version != "" {
+		fmt.Fprintf(w, "Version(%q)\n", s.Info.Version)
+	}
+	if len(s.Servers) > 0 {
+		if len(s.Servers) > 1 {
+			w.WriteString("// TODO: only the first server is imported\n")
+		}
+		u, err := url.Parse(s.Servers[0].URL)
+		switch {
+		case err != nil || strings.Contains(s.Servers[0].URL, "{"):
+			fmt.Fprintf(w, "// TODO: server URL %q uses variables or is invalid\n", s.Servers[0].URL)
+		default:
+			if u.Host != "" {
+				fmt.Fprintf(w, "Host(%q)\n", u.Host)
+			}
+			if u.Scheme != "" {
+				var schemes []string
+				for _, sch := range append([]string{u.Scheme}, s.Schemes...) {
+					if !contains(schemes, sch) {
+						schemes = append(schemes, sch)
+					}
+				}
+				fmt.Fprintf(w, "Scheme(%s)\n", quoteAll(schemes))
+			}
+			if p := strings.TrimSuffix(u.Path, "/"); p != "" {
+				fmt.Fprintf(w, "BasePath(%q)\n", convertPath(p))
+			}
+		}
+	}
+	imp.writeSecurity(w, s.Security)
+	w.WriteString("})\n\n")
+}
+
+func (imp *importer) writeSecuritySchemes(w *bytes.Buffer) {
+	schemes := imp.spec.Components.SecuritySchemes
+	names := make([]string, 0, len(schemes))
+	for n := range schemes {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		sec := schemes[n]
+		var dsl, args []string
+		if sec.Description != "" {
+			dsl = append(dsl, fmt.Sprintf("Description(%q)", sec.Description))
+		}
+		switch {
+		case sec.Type == "http" && strings.EqualFold(sec.Scheme, "basic"):
+			args = []string{"BasicAuthSecurity", strconv.Quote(n)}
+		case sec.Type == "apiKey" && sec.In == "header":
+			args = []string{"APIKeySecurity", strconv.Quote(n)}
+			dsl = append(dsl, fmt.Sprintf("Header(%q)", sec.Name))
+		case sec.Type == "apiKey" && sec.In == "query":
+			args = []string{"APIKeySecurity", strconv.Quote(n)}
+			dsl = append(dsl, fmt.Sprintf("Query(%q)", sec.Name))
+		default:
+			fmt.Fprintf(w, "// TODO: security scheme %q of type %q cannot be imported\n\n", n, sec.Type)
+			continue
+		}
+		v := codegen.Goify(n, true)
+		if !strings.HasSuffix(v, "Auth") {
+			v += "Auth"
+		}
+		v = imp.varName(v)
+		if len(dsl) > 0 {
+			fmt.Fprintf(w, "var %s = %s(%s, func() {\n%s\n})\n\n", v, args[0], args[1], strings.Join(dsl, "\n"))
+		} else {
+			fmt.Fprintf(w, "var %s = %s(%s)\n\n", v, args[0], args[1])
+		}
+		imp.security[n] = v
+	}
+}
+
+// writeSecurity writes the Security or NoSecurity DSL corresponding to the given requirements.
+func (imp *importer) writeSecurity(w *bytes.Buffer, reqs []map[string][]string) {
+	if reqs == nil {
+		return
+	}
+	if len(reqs) == 0 {
+		w.WriteString("NoSecurity()\n")
+		return
+	}
+	if len(reqs) > 1 || len(reqs[0]) != 1 {
+		w.WriteString("// TODO: alternative or combined security requirements cannot be imported\n")
+	}
+	names := make([]string, 0, len(reqs[0]))
+	for n := range reqs[0] {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if v, ok := imp.security[n]; ok {
+			fmt.Fprintf(w, "Security(%s)\n", v)
+			return
+		}
+		fmt.Fprintf(w, "// TODO: security requirement %q refers to a scheme that could not be imported\n", n)
+	}
+}
+
+func (imp *importer) writeResources(w *bytes.Buffer) {
+	type action struct {
+		path, method string
+		op           *operation
+	}
+	var resources []string
+	actions := make(map[string][]*action)
+	imp.eachOperation(func(path, method string, o *operation) {
+		res := resourceName(path, o)
+		if _, ok := actions[res]; !ok {
+			resources = append(resources, res)
+		}
+		actions[res] = append(actions[res], &action{path, method, o})
+	})
+	for _, res := range resources {
+		fmt.Fprintf(w, "var _ = Resource(%q, func() {\n", res)
+		names := make(map[string]bool)
+		for i, a := range actions[res] {
+			if i > 0 {
+				w.WriteString("\n")
+			}
+			name := actionName(a.path, a.method, a.op)
+			for i := 2; names[name]; i++ {
+				name = fmt.Sprintf("%s%d", actionName(a.path, a.method, a.op), i)
+			}
+			names[name] = true
+			imp.writeAction(w, res, name, a.path, a.method, a.op)
+		}
+		w.WriteString("})\n\n")
+	}
+}
+
+func (imp *importer) writeAction(w *bytes.Buffer, res, name, path, method string, o *operation) {
+	fmt.Fprintf(w, "Action(%q, func() {\n", name)
+	desc := o.Description
+	if desc == "" {
+		desc = o.Summary
+	}
+	if desc != "" {
+		fmt.Fprintf(w, "Description(%q)\n", desc)
+	}
+	if o.Deprecated {
+		w.WriteString("// TODO: the operation is deprecated\n")
+	}
+	if imp.spec.Paths.Map[path].Ref != "" {
+		fmt.Fprintf(w, "// TODO: path item reference %q cannot be imported\n", imp.spec.Paths.Map[path].Ref)
+	}
+	fmt.Fprintf(w, "Routing(%s(%q))\n", method, convertPath(path))
+	owner := codegen.Goify(res, true) + codegen.Goify(name, true)
+	imp.writeParams(w, owner, imp.spec.Paths.Map[path].Parameters, o.Parameters)
+	imp.writePayload(w, owner, o.RequestBody)
+	imp.writeSecurity(w, o.Security)
+	for _, code := range o.Responses.Names {
+		imp.writeResponse(w, owner, code, imp.response(o.Responses.Map[code]))
+	}
+	w.WriteString("})\n")
+}
+
+func (imp *importer) writeParams(w *bytes.Buffer, owner string, common, params []*parameter) {
+	var (
+		all  []*parameter
+		keys []string
+	)
+	for _, list := range [][]*parameter{common, params} {
+		for _, p := range list {
+			p = imp.parameter(p)
+			if p == nil {
+				w.WriteString("// TODO: unresolved parameter reference\n")
+				continue
+			}
+			key := p.In + ":" + p.Name
+			found := false
+			for i, k := range keys {
+				if k == key {
+					all[i] = p
+					found = true
+				}
+			}
+			if !found {
+				keys = append(keys, key)
+				all = append(all, p)
+			}
+		}
+	}
+	var attrs, headers []*parameter
+	for _, p := range all {
+		switch p.In {
+		case "path", "query":
+			attrs = append(attrs, p)
+		case "header":
+			headers = append(headers, p)
+		default:
+			fmt.Fprintf(w, "// TODO: %s parameter %q cannot be imported\n", p.In, p.Name)
+		}
+	}
+	for _, group := range []struct {
+		dsl, kind string
+		params    []*parameter
+	}{{"Params", "Param", attrs}, {"Headers", "Header", headers}} {
+		if len(group.params) == 0 {
+			continue
+		}
+		var required []string
+		fmt.Fprintf(w, "%s(func() {\n", group.dsl)
+		for _, p := range group.params {
+			sc := p.Schema
+			if sc == nil {
+				s := p.schema
+				s.Description = ""
+				sc = &s
+			}
+			if p.Description != "" {
+				dup := *sc
+				dup.Description = p.Description
+				sc = &dup
+			}
+			if imp.isObject(imp.resolve(sc)) {
+				fmt.Fprintf(w, "// TODO: parameter %q is an object which is not supported\n", p.Name)
+				continue
+			}
+			imp.writeAttribute(w, group.kind, p.Name, sc, owner)
+			if p.Required || p.In == "path" {
+				required = append(required, p.Name)
+			}
+		}
+		if len(required) > 0 {
+			fmt.Fprintf(w, "Required(%s)\n", quoteAll(required))
+		}
+		w.WriteString("})\n")
+	}
+}
+
+func (imp *importer) writePayload(w *bytes.Buffer, owner string, b *requestBody) {
+	if b == nil {
+		return
+	}
+	body := imp.requestBody(b)
+	if body == nil {
+		w.WriteString("// TODO: unresolved request body reference\n")
+		return
+	}
+	sc, ok := jsonSchema(body.Content)
+	if !ok {
+		if len(body.Content) > 0 {
+			w.WriteString("// TODO: non JSON request body cannot be imported\n")
+		}
+		return
+	}
+	dsl := "OptionalPayload"
+	if body.Required {
+		dsl = "Payload"
+	}
+	var todos []string
+	expr, inline := imp.typeExpr(sc, owner+"Payload", &todos)
+	writeTODOs(w, todos)
+	if inline != nil {
+		fmt.Fprintf(w, "%s(func() {\n", dsl)
+		imp.writeObject(w, inline, owner+"Payload")
+		w.WriteString("})\n")
+		return
+	}
+	fmt.Fprintf(w, "%s(%s)\n", dsl, expr)
+}
+
+func (imp *importer) writeResponse(w *bytes.Buffer, owner, code string, r *response) {
+	status, err := strconv.Atoi(code)
+	if err != nil {
+		fmt.Fprintf(w, "// TODO: %q response cannot be imported\n", code)
+		return
+	}
+	name, ok := imp.statuses[status]
+	var dsl []string
+	if !ok {
+		name = fmt.Sprintf("Status%d", status)
+		dsl = append(dsl, fmt.Sprintf("Status(%d)", status))
+	}
+	args := []string{name}
+	if ok {
+		args[0] = "" // use the design package constant
+	}
+	sc, isJSON := jsonSchema(r.Content)
+	if sc == nil && len(r.Content) > 0 {
+		fmt.Fprintf(w, "// TODO: non JSON %s response body cannot be imported\n", code)
+	}
+	if sc != nil && isJSON {
+		if mt := imp.responseMediaType(owner+name, sc); mt != "" {
+			args = append(args, mt)
+		} else {
+			fmt.Fprintf(w, "// TODO: %s response body is not an object or a collection of objects\n", code)
+		}
+	}
+	if len(r.Headers) > 0 {
+		var hw bytes.Buffer
+		hw.WriteString("Headers(func() {\n")
+		names := make([]string, 0, len(r.Headers))
+		for n := range r.Headers {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			h := imp.parameter(r.Headers[n])
+			if h == nil {
+				continue
+			}
+			sc := h.Schema
+			if sc == nil {
+				s := h.schema
+				sc = &s
+			}
+			imp.writeAttribute(&hw, "Header", n, sc, owner+name)
+		}
+		hw.WriteString("})")
+		dsl = append(dsl, hw.String())
+	}
+	if args[0] == "" {
+		args[0] = name
+	} else {
+		args[0] = strconv.Quote(name)
+	}
+	if len(dsl) > 0 {
+		args = append(args, "func() {\n"+strings.Join(dsl, "\n")+"\n}")
+	}
+	fmt.Fprintf(w, "Response(%s)\n", strings.Join(args, ", "))
+}
+
+// responseMediaType returns the expression of the media type describing the response body sc.
+// It creates a new media type if sc describes an inline object.
+func (imp *importer) responseMediaType(name string, sc *schema) string {
+	collection := false
+	if sc.Items != nil {
+		collection = true
+		sc = sc.Items
+	}
+	var ut *userType
+	if sc.Ref != "" {
+		ut = imp.types[refName(sc.Ref)]
+	} else if imp.isObject(sc) {
+		ut = &userType{
+			name:       name,
+			varName:    imp.varName(name + "Media"),
+			identifier: "application/vnd." + codegen.KebabCase(name),
+			schema:     sc,
+		}
+		imp.extra = append(imp.extra, ut)
+	}
+	if ut == nil || ut.identifier == "" {
+		return ""
+	}
+	if collection {
+		return fmt.Sprintf("CollectionOf(%s)", ut.varName)
+	}
+	return ut.varName
+}
+
+func (imp *importer) writeUserType(w *bytes.Buffer, ut *userType) {
+	if ut.builtin {
+		return
+	}
+	imp.current = ut.ref
+	defer func() { imp.current = "" }()
+	sc := imp.flatten(ut.schema)
+	if ut.identifier == "" {
+		fmt.Fprintf(w, "var %s = Type(%q, func() {\n", ut.varName, ut.name)
+		if sc.Description != "" {
+			fmt.Fprintf(w, "Description(%q)\n", sc.Description)
+		}
+		imp.writeObject(w, sc, ut.name)
+		w.WriteString("})\n\n")
+		return
+	}
+	fmt.Fprintf(w, "var %s = MediaType(%q, func() {\n", ut.varName, ut.identifier)
+	if sc.Description != "" {
+		fmt.Fprintf(w, "Description(%q)\n", sc.Description)
+	}
+	fmt.Fprintf(w, "TypeName(%q)\n", ut.name)
+	w.WriteString("Attributes(func() {\n")
+	imp.writeObject(w, sc, ut.name)
+	w.WriteString("})\n")
+	w.WriteString("View(\"default\", func() {\n")
+	for _, n := range sc.Properties.Names {
+		fmt.Fprintf(w, "Attribute(%q)\n", n)
+	}
+	w.WriteString("})\n")
+	w.WriteString("})\n\n")
+}
+
+// writeObject writes the attributes of the object described by sc.
+func (imp *importer) writeObject(w *bytes.Buffer, sc *schema, owner string) {
+	sc = imp.flatten(sc)
+	var todos []string
+	imp.unsupported(sc, &todos)
+	writeTODOs(w, todos)
+	for _, n := range sc.Properties.Names {
+		imp.writeAttribute(w, "Attribute", n, sc.Properties.Map[n], owner)
+	}
+	var required []string
+	for _, r := range sc.Required {
+		if _, ok := sc.Properties.Map[r]; !ok {
+			fmt.Fprintf(w, "// TODO: required attribute %q is not defined\n", r)
+			continue
+		}
+		if !contains(required, r) {
+			required = append(required, r)
+		}
+	}
+	if len(required) > 0 {
+		fmt.Fprintf(w, "Required(%s)\n", quoteAll(required))
+	}
+}
+
+// writeAttribute writes the Attribute, Param or Header DSL describing sc.
+func (imp *importer) writeAttribute(w *bytes.Buffer, kind, name string, sc *schema, owner string) {
+	var todos []string
+	attOwner := owner + codegen.Goify(name, true)
+	expr, inline := imp.typeExpr(sc, attOwner, &todos)
+	if r := imp.resolve(sc); r != nil && r.Ref == "" && r != sc {
+		// reference to a schema that does not define a user type, e.g. an enum
+		desc := sc.Description
+		sc = r
+		if desc != "" {
+			dup := *r
+			dup.Description = desc
+			sc = &dup
+		}
+	}
+	vals := imp.validations(sc, expr, &todos)
+	writeTODOs(w, todos)
+	if inline != nil {
+		fmt.Fprintf(w, "%s(%q, func() {\n", kind, name)
+		if sc.Description != "" {
+			fmt.Fprintf(w, "Description(%q)\n", sc.Description)
+		}
+		for _, v := range vals {
+			w.WriteString(v + "\n")
+		}
+		imp.writeObject(w, inline, attOwner)
+		w.WriteString("})\n")
+		return
+	}
+	args := []string{strconv.Quote(name), expr}
+	if sc.Description != "" {
+		args = append(args, strconv.Quote(sc.Description))
+	}
+	if len(vals) > 0 {
+		args = append(args, "func() {\n"+strings.Join(vals, "\n")+"\n}")
+	}
+	fmt.Fprintf(w, "%s(%s)\n", kind, strings.Join(args, ", "))
+}
+
+// resolve follows the references to component schemas that do not define user types.
+func (imp *importer) resolve(sc *schema) *schema {
+	for i := 0; sc != nil && sc.Ref != "" && i < 32; i++ {
+		n := refName(sc.Ref)
+		if _, ok := imp.types[n]; ok {
+			return sc
+		}
+		sc = imp.spec.Components.Schemas.Map[n]
+	}
+	return sc
+}
+
+// typeExpr returns the DSL expression of the type described by sc. It returns the schema of the
+// object instead if sc describes an inline object. owner is used to name the user types created
+// for inline objects that cannot be described inline.
+func (imp *importer) typeExpr(sc *schema, owner string, todos *[]string) (string, *schema) {
+	if sc == nil {
+		return "Any", nil
+	}
+	if sc.Ref != "" {
+		return imp.refTypeExpr(sc, owner, todos)
+	}
+	if len(sc.OneOf) > 0 || len(sc.AnyOf) > 0 {
+		*todos = append(*todos, "oneOf and anyOf schemas cannot be imported, the value may be of any type")
+		return "Any", nil
+	}
+	if imp.isObject(sc) {
+		return "", sc
+	}
+	switch typ := jsonType(sc, todos); typ {
+	case "array":
+		elem := imp.elemExpr(sc.Items, owner+"Item", todos)
+		if vals := imp.validations(sc.Items, elem, todos); len(vals) > 0 && sc.Items.Ref == "" {
+			return fmt.Sprintf("ArrayOf(%s, func() {\n%s\n})", elem, strings.Join(vals, "\n")), nil
+		}
+		return fmt.Sprintf("ArrayOf(%s)", elem), nil
+	case "object":
+		if ap := sc.additionalProperties(); ap != nil {
+			return fmt.Sprintf("HashOf(String, %s)", imp.elemExpr(ap, owner+"Value", todos)), nil
+		}
+		return "Any", nil
+	default:
+		return primitiveExpr(typ, sc.Format), nil
+	}
+}
+
+// refTypeExpr returns the DSL expression of the type referred to by sc.
+func (imp *importer) refTypeExpr(sc *schema, owner string, todos *[]string) (string, *schema) {
+	resolved := imp.resolve(sc)
+	if resolved != nil && resolved.Ref == "" {
+		return imp.typeExpr(resolved, owner, todos)
+	}
+	if resolved != nil {
+		if ut, ok := imp.types[refName(resolved.Ref)]; ok {
+			return imp.refExpr(ut), nil
+		}
+	}
+	*todos = append(*todos, fmt.Sprintf("unresolved reference %q", sc.Ref))
+	return "Any", nil
+}
+
+// jsonType returns the JSON type of the values described by sc.
+func jsonType(sc *schema, todos *[]string) string {
+	var types []string
+	for _, t := range sc.types() {
+		if t != "null" {
+			types = append(types, t)
+		}
+	}
+	switch {
+	case len(types) > 1:
+		*todos = append(*todos, fmt.Sprintf("multiple types %v cannot be imported", types))
+		return ""
+	case len(types) == 1:
+		return types[0]
+	case sc.Items != nil:
+		return "array"
+	case sc.additionalProperties() != nil:
+		return "object"
+	}
+	return ""
+}
+
+// primitiveExpr returns the DSL expression of the primitive type with the given JSON type and
+// format.
+func primitiveExpr(typ, format string) string {
+	switch typ {
+	case "string":
+		switch format {
+		case "date-time":
+			return "DateTime"
+		case "uuid":
+			return "UUID"
+		}
+		return "String"
+	case "integer":
+		return "Integer"
+	case "number":
+		return "Number"
+	case "boolean":
+		return "Boolean"
+	}
+	return "Any"
+}
+
+// elemExpr returns the DSL expression of the type of array elements or hash values. Inline
+// objects are described with new user types.
+func (imp *importer) elemExpr(sc *schema, name string, todos *[]string) string {
+	expr, inline := imp.typeExpr(sc, name, todos)
+	if inline == nil {
+		return expr
+	}
+	ut := &userType{name: name, varName: imp.varName(name), schema: inline, byName: true}
+	imp.extra = append(imp.extra, ut)
+	return imp.refExpr(ut)
+}
+
+// refExpr returns the expression used to refer to the given user type. Types that are part of a
+// reference cycle are referred to by name to avoid Go initialization cycles.
+func (imp *importer) refExpr(ut *userType) string {
+	byName := ut.byName
+	if !byName && imp.current != "" {
+		byName = ut.ref == imp.current || imp.reaches(ut.ref, imp.current, make(map[string]bool))
+	}
+	if !byName {
+		return ut.varName
+	}
+	if ut.identifier != "" {
+		return strconv.Quote(ut.identifier)
+	}
+	return strconv.Quote(ut.name)
+}
+
+// validations returns the DSL describing the validations of sc.
+func (imp *importer) validations(sc *schema, expr string, todos *[]string) []string {
+	if sc == nil {
+		return nil
+	}
+	var vals []string
+	if len(sc.Enum) > 0 {
+		var lits []string
+		for _, v := range sc.Enum {
+			if l, ok := literal(v); ok {
+				lits = append(lits, l)
+			}
+		}
+		if len(lits) == len(sc.Enum) {
+			vals = append(vals, fmt.Sprintf("Enum(%s)", strings.Join(lits, ", ")))
+		} else {
+			*todos = append(*todos, "enum values that are not strings, numbers or booleans cannot be imported")
+		}
+	}
+	if sc.Default != nil {
+		if l, ok := literal(sc.Default); ok {
+			vals = append(vals, fmt.Sprintf("Default(%s)", l))
+		} else {
+			*todos = append(*todos, "default values that are not strings, numbers or booleans cannot be imported")
+		}
+	}
+	if sc.Example != nil {
+		if l, ok := literal(sc.Example); ok {
+			vals = append(vals, fmt.Sprintf("Example(%s)", l))
+		}
+	}
+	if sc.Format != "" && expr == "String" {
+		if contains(apidsl.SupportedValidationFormats, sc.Format) {
+			vals = append(vals, fmt.Sprintf("Format(%q)", sc.Format))
+		} else {
+			*todos = append(*todos, fmt.Sprintf("format %q cannot be imported", sc.Format))
+		}
+	}
+	if sc.Pattern != "" {
+		vals = append(vals, fmt.Sprintf("Pattern(%q)", sc.Pattern))
+	}
+	if sc.Minimum != nil {
+		vals = append(vals, fmt.Sprintf("Minimum(%s)", number(*sc.Minimum)))
+	}
+	if sc.Maximum != nil {
+		vals = append(vals, fmt.Sprintf("Maximum(%s)", number(*sc.Maximum)))
+	}
+	for _, l := range []struct {
+		dsl string
+		val *int
+	}{{"MinLength", sc.MinLength}, {"MaxLength", sc.MaxLength}, {"MinLength", sc.MinItems}, {"MaxLength", sc.MaxItems}} {
+		if l.val != nil {
+			vals = append(vals, fmt.Sprintf("%s(%d)", l.dsl, *l.val))
+		}
+	}
+	if sc.ReadOnly {
+		vals = append(vals, "ReadOnly()")
+	}
+	if !imp.isObject(sc) {
+		imp.unsupported(sc, todos)
+	}
+	return vals
+}
+
+// unsupported records the schema keywords that have no DSL equivalent.
+func (imp *importer) unsupported(sc *schema, todos *[]string) {
+	for _, u := range []struct {
+		set     bool
+		keyword string
+	}{
+		{sc.ExclusiveMinimum != nil && sc.ExclusiveMinimum != false, "exclusiveMinimum"},
+		{sc.ExclusiveMaximum != nil && sc.ExclusiveMaximum != false, "exclusiveMaximum"},
+		{sc.MultipleOf != nil, "multipleOf"},
+		{sc.UniqueItems, "uniqueItems"},
+		{sc.MinProperties != nil, "minProperties"},
+		{sc.MaxProperties != nil, "maxProperties"},
+		{sc.Not != nil, "not"},
+		{sc.Discriminator != nil, "discriminator"},
+		{sc.Nullable, "nullable"},
+		{sc.WriteOnly, "writeOnly"},
+	} {
+		if u.set {
+			*todos = append(*todos, fmt.Sprintf("%s cannot be imported", u.keyword))
+		}
+	}
+}
+
+// jsonSchema returns the schema of the JSON content if any. It returns the schema of the first
+// content and false if there is no JSON content.
+func jsonSchema(content map[string]*mediaType) (*schema, bool) {
+	if len(content) == 0 {
+		return nil, false
+	}
+	mimes := make([]string, 0, len(content))
+	for m := range content {
+		mimes = append(mimes, m)
+	}
+	sort.Strings(mimes)
+	if c, ok := content["application/json"]; ok {
+		return c.Schema, true
+	}
+	for _, m := range mimes {
+		if strings.Contains(m, "json") {
+			return content[m].Schema, true
+		}
+	}
+	return nil, false
+}
+
+// resourceName returns the name of the resource grouping the given operation.
+func resourceName(path string, o *operation) string {
+	if len(o.Tags) > 0 {
+		return codegen.SnakeCase(codegen.Goify(o.Tags[0], true))
+	}
+	for _, seg := range strings.Split(path, "/") {
+		if seg != "" && !strings.HasPrefix(seg, "{") {
+			return codegen.SnakeCase(codegen.Goify(seg, true))
+		}
+	}
+	return "root"
+}
+
+// actionName returns the name of the action corresponding to the given operation.
+func actionName(path, method string, o *operation) string {
+	if o.OperationID != "" {
+		return codegen.SnakeCase(codegen.Goify(o.OperationID, true))
+	}
+	parts := []string{strings.ToLower(method)}
+	for _, seg := range strings.Split(path, "/") {
+		seg = strings.Trim(seg, "{}")
+		if seg != "" {
+			parts = append(parts, seg)
+		}
+	}
+	return codegen.SnakeCase(codegen.Goify(strings.Join(parts, "_"), true))
+}
+
+// convertPath converts the OpenAPI path parameters into goa wildcards.
+func convertPath(path string) string {
+	return pathParamRegex.ReplaceAllString(path, ":$1")
+}
+
+// literal returns the Go literal for the given JSON scalar value.
+func literal(v interface{}) (string, bool) {
+	switch actual := v.(type) {
+	case string:
+		return strconv.Quote(actual), true
+	case bool:
+		return strconv.FormatBool(actual), true
+	case float64:
+		return number(actual), true
+	}
+	return "", false
+}
+
+// number returns the Go literal for the given JSON number.
+func number(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func writeTODOs(w *bytes.Buffer, todos []string) {
+	for _, t := range todos {
+		fmt.Fprintf(w, "// TODO: %s\n", t)
+	}
+}
+
+func quoteAll(vals []string) string {
+	quoted := make([]string, len(vals))
+	for i, v := range vals {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
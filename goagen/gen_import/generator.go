@@ -0,0 +1,79 @@
+package genimport
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of a design import Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{Target: "design"}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the design package generator. Contrary to the other generators it does not
+// require a design package and is run directly by goagen instead of going through the meta
+// generator.
+type Generator struct {
+	Spec     string   // Path to the Swagger or OpenAPI specification file
+	OutDir   string   // Path to output directory
+	Target   string   // Name of generated design package
+	Force    bool     // Whether to override existing files
+	genfiles []string // Generated files
+}
+
+// Generate produces the design package.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.Spec == "" {
+		return nil, fmt.Errorf("missing specification file")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	s, err := loadSpec(g.Spec)
+	if err != nil {
+		return nil, err
+	}
+	code, err := newImporter(s).Write(g.Target, filepath.Base(g.Spec))
+	if err != nil {
+		return nil, err
+	}
+
+	designDir := filepath.Join(g.OutDir, g.Target)
+	if err = os.MkdirAll(designDir, 0755); err != nil {
+		return nil, err
+	}
+	designFile := filepath.Join(designDir, "design.go")
+	if _, err = os.Stat(designFile); err == nil && !g.Force {
+		return nil, fmt.Errorf("%s already exists, use --force to overwrite it", designFile)
+	}
+	if err = ioutil.WriteFile(designFile, code, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, designFile)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
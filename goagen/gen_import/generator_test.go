@@ -0,0 +1,353 @@
+package genimport_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	genimport "github.com/goadesign/goa/goagen/gen_import"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var (
+		specFile string
+		outDir   string
+		force    bool
+		files    []string
+		genErr   error
+		content  string
+	)
+
+	BeforeEach(func() {
+		var err error
+		outDir, err = ioutil.TempDir("", "genimport")
+		Ω(err).ShouldNot(HaveOccurred())
+		force = false
+		content = ""
+	})
+
+	JustBeforeEach(func() {
+		g := genimport.NewGenerator(
+			genimport.Spec(specFile),
+			genimport.OutDir(outDir),
+			genimport.Force(force),
+		)
+		files, genErr = g.Generate()
+		if genErr == nil && len(files) == 1 {
+			b, err := ioutil.ReadFile(files[0])
+			Ω(err).ShouldNot(HaveOccurred())
+			content = string(b)
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+		os.Remove(specFile)
+	})
+
+	writeSpec := func(name, spec string) {
+		specFile = filepath.Join(os.TempDir(), name)
+		Ω(ioutil.WriteFile(specFile, []byte(spec), 0644)).ShouldNot(HaveOccurred())
+	}
+
+	_, file, _, _ := runtime.Caller(0)
+	goaDir := filepath.Dir(filepath.Dir(filepath.Dir(file)))
+
+	// build compiles the generated design in a module that uses the goa sources under test.
+	build := func() {
+		content, err := ioutil.ReadFile(filepath.Join(goaDir, "go.mod"))
+		if err != nil {
+			Skip("goa is not built in module mode")
+		}
+		gomod := "module example.com/imported\n\nrequire github.com/goadesign/goa v1.4.3\n\nreplace github.com/goadesign/goa => " + goaDir + "\n"
+		// The replace directives of dependencies are ignored, copy the goa ones.
+		for _, line := range strings.Split(string(content), "\n") {
+			if strings.HasPrefix(line, "replace ") {
+				gomod += line + "\n"
+			}
+		}
+		Ω(ioutil.WriteFile(filepath.Join(outDir, "go.mod"), []byte(gomod), 0644)).ShouldNot(HaveOccurred())
+		if sum, err := ioutil.ReadFile(filepath.Join(goaDir, "go.sum")); err == nil {
+			Ω(ioutil.WriteFile(filepath.Join(outDir, "go.sum"), sum, 0644)).ShouldNot(HaveOccurred())
+		}
+		cmd := exec.Command("go", "build", "./design")
+		cmd.Dir = outDir
+		out, err := cmd.CombinedOutput()
+		Ω(err).ShouldNot(HaveOccurred(), string(out))
+	}
+
+	Context("with a Swagger 2.0 specification", func() {
+		BeforeEach(func() {
+			writeSpec("swagger.yaml", swaggerSpec)
+		})
+
+		It("generates the design package", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(Equal([]string{filepath.Join(outDir, "design", "design.go")}))
+			Ω(content).Should(ContainSubstring("package design"))
+			Ω(content).Should(ContainSubstring(`var _ = API("petstore", func() {`))
+			Ω(content).Should(ContainSubstring(`BasePath("/v1")`))
+			Ω(content).Should(ContainSubstring(`var BasicAuth = BasicAuthSecurity("basic")`))
+		})
+
+		It("maps the paths to resources and actions", func() {
+			Ω(content).Should(ContainSubstring(`var _ = Resource("pets", func() {`))
+			Ω(content).Should(ContainSubstring(`Action("list_pets", func() {`))
+			Ω(content).Should(ContainSubstring(`Routing(GET("/pets/:petId"))`))
+			Ω(content).Should(ContainSubstring(`Param("limit", Integer, "Maximum number of results", func() {`))
+			Ω(content).Should(ContainSubstring(`Payload(NewPet)`))
+			Ω(content).Should(ContainSubstring(`Response(OK, CollectionOf(PetMedia))`))
+			Ω(content).Should(ContainSubstring(`Response(NotFound)`))
+		})
+
+		It("maps the definitions to types and media types", func() {
+			Ω(content).Should(ContainSubstring(`var PetMedia = MediaType("application/vnd.pet", func() {`))
+			Ω(content).Should(ContainSubstring(`var NewPet = Type("NewPet", func() {`))
+			Ω(content).Should(ContainSubstring(`Attribute("born", DateTime)`))
+			Ω(content).Should(ContainSubstring(`Enum("available", "sold")`))
+			Ω(content).Should(ContainSubstring(`MaxLength(64)`))
+			Ω(content).Should(ContainSubstring(`Required("id", "name")`))
+		})
+
+		It("writes TODOs for the unsupported constructs", func() {
+			Ω(content).Should(ContainSubstring(`// TODO: "default" response cannot be imported`))
+			Ω(content).Should(ContainSubstring(`// TODO: exclusiveMinimum cannot be imported`))
+			Ω(content).Should(ContainSubstring(`// TODO: formData parameter "picture" cannot be imported`))
+		})
+
+		It("generates a design that compiles", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			build()
+		})
+
+		Context("with an existing design", func() {
+			BeforeEach(func() {
+				Ω(os.MkdirAll(filepath.Join(outDir, "design"), 0755)).ShouldNot(HaveOccurred())
+				Ω(ioutil.WriteFile(filepath.Join(outDir, "design", "design.go"), nil, 0644)).ShouldNot(HaveOccurred())
+			})
+
+			It("returns an error", func() {
+				Ω(genErr).Should(HaveOccurred())
+			})
+
+			Context("and force", func() {
+				BeforeEach(func() {
+					force = true
+				})
+
+				It("overwrites the design", func() {
+					Ω(genErr).ShouldNot(HaveOccurred())
+					Ω(content).Should(ContainSubstring("package design"))
+				})
+			})
+		})
+	})
+
+	Context("with an OpenAPI 3 specification", func() {
+		BeforeEach(func() {
+			writeSpec("openapi.json", openapiSpec)
+		})
+
+		It("generates the design package", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`Host("api.example.com")`))
+			Ω(content).Should(ContainSubstring(`Scheme("https")`))
+			Ω(content).Should(ContainSubstring(`Payload(NodeMedia)`))
+			Ω(content).Should(ContainSubstring(`Response(NotFound, ProblemMedia)`))
+			Ω(content).Should(ContainSubstring(`// TODO: cookie parameter "session" cannot be imported`))
+		})
+
+		It("uses the design problem details media type for problem responses", func() {
+			Ω(content).ShouldNot(ContainSubstring(`MediaType("application/vnd.problem"`))
+		})
+
+		It("refers to recursive types by name", func() {
+			Ω(content).Should(ContainSubstring(`Attribute("children", ArrayOf("application/vnd.node"))`))
+		})
+
+		It("creates types for inline objects", func() {
+			Ω(content).Should(ContainSubstring(`Attribute("meta", HashOf(String, "NodeMetaValue"))`))
+			Ω(content).Should(ContainSubstring(`var NodeMetaValue = Type("NodeMetaValue", func() {`))
+		})
+
+		It("generates a design that compiles", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			build()
+		})
+	})
+
+	Context("with definitions named after the DSL identifiers", func() {
+		BeforeEach(func() {
+			spec := "swagger: \"2.0\"\ninfo:\n  title: names\n  version: \"1.0\"\npaths: {}\ndefinitions:\n"
+			notest := func(fi os.FileInfo) bool { return !strings.HasSuffix(fi.Name(), "_test.go") }
+			for _, dir := range []string{"design", filepath.Join("design", "apidsl")} {
+				pkgs, err := parser.ParseDir(token.NewFileSet(), filepath.Join(goaDir, dir), notest, 0)
+				Ω(err).ShouldNot(HaveOccurred())
+				for _, pkg := range pkgs {
+					for _, f := range pkg.Files {
+						for n := range f.Scope.Objects {
+							if ast.IsExported(n) {
+								spec += "  " + n + ":\n    type: object\n    properties:\n      id:\n        type: string\n"
+							}
+						}
+					}
+				}
+			}
+			writeSpec("names.yaml", spec)
+		})
+
+		It("generates variables that do not conflict with the DSL", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`var ProblemMedia2 = Type("ProblemMedia", func() {`))
+			build()
+		})
+	})
+
+	Context("with an unsupported specification", func() {
+		BeforeEach(func() {
+			writeSpec("unsupported.yaml", `swagger: "1.2"`)
+		})
+
+		It("returns an error", func() {
+			Ω(genErr).Should(HaveOccurred())
+		})
+	})
+})
+
+const swaggerSpec = `
+swagger: "2.0"
+info:
+  title: petstore
+  version: "1.0"
+host: petstore.swagger.io
+basePath: /v1
+securityDefinitions:
+  basic:
+    type: basic
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      tags: [pets]
+      parameters:
+        - name: limit
+          in: query
+          description: Maximum number of results
+          type: integer
+          maximum: 100
+      responses:
+        "200":
+          description: pets
+          schema:
+            type: array
+            items:
+              $ref: "#/definitions/Pet"
+        default:
+          description: error
+    post:
+      operationId: createPet
+      tags: [pets]
+      parameters:
+        - name: body
+          in: body
+          required: true
+          schema:
+            $ref: "#/definitions/NewPet"
+        - name: picture
+          in: formData
+          type: file
+      responses:
+        "201":
+          description: created
+  /pets/{petId}:
+    get:
+      operationId: showPet
+      tags: [pets]
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          type: string
+      responses:
+        "200":
+          description: pet
+          schema:
+            $ref: "#/definitions/Pet"
+        "404":
+          description: not found
+definitions:
+  Pet:
+    type: object
+    required: [id, name]
+    properties:
+      id:
+        type: integer
+      name:
+        type: string
+        maxLength: 64
+      born:
+        type: string
+        format: date-time
+      status:
+        $ref: "#/definitions/Status"
+      weight:
+        type: number
+        minimum: 0
+        exclusiveMinimum: true
+  Status:
+    type: string
+    enum: [available, sold]
+  NewPet:
+    type: object
+    properties:
+      name:
+        type: string
+`
+
+const openapiSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "tree", "version": "2.0"},
+  "servers": [{"url": "https://api.example.com/api"}],
+  "components": {
+    "responses": {
+      "NotFound": {"description": "not found", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Problem"}}}}
+    },
+    "schemas": {
+      "Node": {
+        "type": "object",
+        "properties": {
+          "children": {"type": "array", "items": {"$ref": "#/components/schemas/Node"}},
+          "meta": {"type": "object", "additionalProperties": {"type": "object", "properties": {"v": {"type": "integer"}}}}
+        }
+      },
+      "Problem": {
+        "type": "object",
+        "properties": {"title": {"type": "string"}}
+      }
+    }
+  },
+  "paths": {
+    "/nodes/{id}": {
+      "put": {
+        "operationId": "updateNode",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}},
+          {"name": "session", "in": "cookie", "schema": {"type": "string"}}
+        ],
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Node"}}}},
+        "responses": {
+          "200": {"description": "ok", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Node"}}}},
+          "404": {"$ref": "#/components/responses/NotFound"}
+        }
+      }
+    }
+  }
+}`
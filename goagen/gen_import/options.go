@@ -0,0 +1,32 @@
+package genimport
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//Spec Path to the Swagger or OpenAPI specification file
+func Spec(spec string) Option {
+	return func(g *Generator) {
+		g.Spec = spec
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//Target Name of the generated design package
+func Target(target string) Option {
+	return func(g *Generator) {
+		g.Target = target
+	}
+}
+
+//Force Whether to override existing files
+func Force(force bool) Option {
+	return func(g *Generator) {
+		g.Force = force
+	}
+}
@@ -0,0 +1,497 @@
+package genimport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+type (
+	// spec is the subset of the Swagger 2.0 and OpenAPI 3 specification used by the importer.
+	// Swagger documents are normalized into the OpenAPI 3 structure after being loaded.
+	spec struct {
+		Swagger             string                     `json:"swagger"`
+		OpenAPI             string                     `json:"openapi"`
+		Info                *info                      `json:"info"`
+		Host                string                     `json:"host"`
+		BasePath            string                     `json:"basePath"`
+		Schemes             []string                   `json:"schemes"`
+		Consumes            []string                   `json:"consumes"`
+		Produces            []string                   `json:"produces"`
+		Servers             []*server                  `json:"servers"`
+		Paths               paths                      `json:"paths"`
+		Definitions         schemas                    `json:"definitions"`
+		Parameters          map[string]*parameter      `json:"parameters"`
+		Responses           map[string]*response       `json:"responses"`
+		SecurityDefinitions map[string]*securityScheme `json:"securityDefinitions"`
+		Security            []map[string][]string      `json:"security"`
+		Components          *components                `json:"components"`
+	}
+
+	info struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Version     string `json:"version"`
+	}
+
+	server struct {
+		URL string `json:"url"`
+	}
+
+	components struct {
+		Schemas         schemas                    `json:"schemas"`
+		Parameters      map[string]*parameter      `json:"parameters"`
+		Responses       map[string]*response       `json:"responses"`
+		RequestBodies   map[string]*requestBody    `json:"requestBodies"`
+		SecuritySchemes map[string]*securityScheme `json:"securitySchemes"`
+	}
+
+	pathItem struct {
+		Ref        string       `json:"$ref"`
+		Parameters []*parameter `json:"parameters"`
+		Get        *operation   `json:"get"`
+		Put        *operation   `json:"put"`
+		Post       *operation   `json:"post"`
+		Delete     *operation   `json:"delete"`
+		Options    *operation   `json:"options"`
+		Head       *operation   `json:"head"`
+		Patch      *operation   `json:"patch"`
+		Trace      *operation   `json:"trace"`
+	}
+
+	operation struct {
+		OperationID string                `json:"operationId"`
+		Summary     string                `json:"summary"`
+		Description string                `json:"description"`
+		Tags        []string              `json:"tags"`
+		Parameters  []*parameter          `json:"parameters"`
+		RequestBody *requestBody          `json:"requestBody"`
+		Responses   responses             `json:"responses"`
+		Consumes    []string              `json:"consumes"`
+		Produces    []string              `json:"produces"`
+		Security    []map[string][]string `json:"security"`
+		Deprecated  bool                  `json:"deprecated"`
+	}
+
+	// parameter embeds schema to capture the type and validations of Swagger 2.0 non-body
+	// parameters.
+	parameter struct {
+		schema
+		Name     string  `json:"name"`
+		In       string  `json:"in"`
+		Required bool    `json:"required"`
+		Schema   *schema `json:"schema"`
+	}
+
+	requestBody struct {
+		Ref         string                `json:"$ref"`
+		Description string                `json:"description"`
+		Required    bool                  `json:"required"`
+		Content     map[string]*mediaType `json:"content"`
+	}
+
+	response struct {
+		Ref         string                `json:"$ref"`
+		Description string                `json:"description"`
+		Schema      *schema               `json:"schema"`
+		Content     map[string]*mediaType `json:"content"`
+		Headers     map[string]*parameter `json:"headers"`
+	}
+
+	mediaType struct {
+		Schema *schema `json:"schema"`
+	}
+
+	securityScheme struct {
+		Type        string `json:"type"`
+		Scheme      string `json:"scheme"`
+		Description string `json:"description"`
+		Name        string `json:"name"`
+		In          string `json:"in"`
+	}
+
+	schema struct {
+		Ref                  string          `json:"$ref"`
+		Type                 interface{}     `json:"type"`
+		Format               string          `json:"format"`
+		Title                string          `json:"title"`
+		Description          string          `json:"description"`
+		Default              interface{}     `json:"default"`
+		Example              interface{}     `json:"example"`
+		Enum                 []interface{}   `json:"enum"`
+		Pattern              string          `json:"pattern"`
+		Minimum              *float64        `json:"minimum"`
+		Maximum              *float64        `json:"maximum"`
+		ExclusiveMinimum     interface{}     `json:"exclusiveMinimum"`
+		ExclusiveMaximum     interface{}     `json:"exclusiveMaximum"`
+		MultipleOf           *float64        `json:"multipleOf"`
+		MinLength            *int            `json:"minLength"`
+		MaxLength            *int            `json:"maxLength"`
+		MinItems             *int            `json:"minItems"`
+		MaxItems             *int            `json:"maxItems"`
+		UniqueItems          bool            `json:"uniqueItems"`
+		MinProperties        *int            `json:"minProperties"`
+		MaxProperties        *int            `json:"maxProperties"`
+		Items                *schema         `json:"items"`
+		Properties           schemas         `json:"properties"`
+		AdditionalProperties json.RawMessage `json:"additionalProperties"`
+		Required             []string        `json:"required"`
+		AllOf                []*schema       `json:"allOf"`
+		OneOf                []*schema       `json:"oneOf"`
+		AnyOf                []*schema       `json:"anyOf"`
+		Not                  *schema         `json:"not"`
+		Discriminator        interface{}     `json:"discriminator"`
+		Nullable             bool            `json:"nullable"`
+		ReadOnly             bool            `json:"readOnly"`
+		WriteOnly            bool            `json:"writeOnly"`
+	}
+
+	// schemas is a map of schemas that preserves the order of the keys in the document.
+	schemas struct {
+		Names []string
+		Map   map[string]*schema
+	}
+
+	// paths is a map of path items that preserves the order of the keys in the document.
+	paths struct {
+		Names []string
+		Map   map[string]*pathItem
+	}
+
+	// responses is a map of responses that preserves the order of the keys in the document.
+	responses struct {
+		Names []string
+		Map   map[string]*response
+	}
+)
+
+// httpMethods lists the HTTP methods in the order used to iterate over the path item operations.
+var httpMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "TRACE"}
+
+// loadSpec reads and normalizes the Swagger 2.0 or OpenAPI 3 document in the file at the given
+// path. The document may be formatted in JSON or YAML.
+func loadSpec(path string) (*spec, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseSpec(b)
+}
+
+// parseSpec parses and normalizes the given Swagger 2.0 or OpenAPI 3 document.
+func parseSpec(b []byte) (*spec, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse specification: %s", err)
+	}
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, doc); err != nil {
+		return nil, fmt.Errorf("failed to parse specification: %s", err)
+	}
+	var s spec
+	if err := json.Unmarshal(buf.Bytes(), &s); err != nil {
+		return nil, fmt.Errorf("failed to parse specification: %s", err)
+	}
+	switch {
+	case strings.HasPrefix(s.Swagger, "2."):
+		s.normalize()
+	case strings.HasPrefix(s.OpenAPI, "3."):
+	default:
+		return nil, fmt.Errorf("unsupported specification, only Swagger 2.0 and OpenAPI 3.x are supported")
+	}
+	if s.Components == nil {
+		s.Components = &components{}
+	}
+	if s.Info == nil {
+		s.Info = &info{}
+	}
+	return &s, nil
+}
+
+// normalize converts a Swagger 2.0 document into the equivalent OpenAPI 3 structure.
+func (s *spec) normalize() {
+	s.Components = &components{
+		Schemas:         s.Definitions,
+		Parameters:      make(map[string]*parameter),
+		Responses:       s.Responses,
+		RequestBodies:   make(map[string]*requestBody),
+		SecuritySchemes: make(map[string]*securityScheme),
+	}
+	for n, p := range s.Parameters {
+		if p.In == "body" {
+			s.Components.RequestBodies[n] = &requestBody{Description: p.Description, Required: p.Required, Content: content(s.Consumes, p.Schema)}
+			continue
+		}
+		s.Components.Parameters[n] = p
+	}
+	for _, r := range s.Responses {
+		r.Content = content(s.Produces, r.Schema)
+	}
+	for n, sec := range s.SecurityDefinitions {
+		if sec.Type == "basic" {
+			sec = &securityScheme{Type: "http", Scheme: "basic", Description: sec.Description}
+		}
+		s.Components.SecuritySchemes[n] = sec
+	}
+	for _, n := range s.Paths.Names {
+		item := s.Paths.Map[n]
+		item.Parameters = s.normalizeParams(item.Parameters, s.Consumes, nil)
+		for _, m := range httpMethods {
+			o := item.operation(m)
+			if o == nil {
+				continue
+			}
+			consumes := s.Consumes
+			if len(o.Consumes) > 0 {
+				consumes = o.Consumes
+			}
+			produces := s.Produces
+			if len(o.Produces) > 0 {
+				produces = o.Produces
+			}
+			o.Parameters = s.normalizeParams(o.Parameters, consumes, o)
+			for _, r := range o.Responses.Map {
+				if r.Ref == "" {
+					r.Content = content(produces, r.Schema)
+				}
+			}
+		}
+	}
+	if s.BasePath != "" || s.Host != "" {
+		scheme := "http"
+		if len(s.Schemes) > 0 {
+			scheme = s.Schemes[0]
+		}
+		s.Servers = []*server{{URL: scheme + "://" + s.Host + s.BasePath}}
+	}
+}
+
+// normalizeParams converts the Swagger 2.0 body parameters into request bodies and references to
+// body parameters into references to request bodies.
+func (s *spec) normalizeParams(params []*parameter, consumes []string, o *operation) []*parameter {
+	var res []*parameter
+	for _, p := range params {
+		if p.Ref != "" {
+			if _, ok := s.Components.RequestBodies[refName(p.Ref)]; ok {
+				if o != nil {
+					o.RequestBody = &requestBody{Ref: "#/components/requestBodies/" + refName(p.Ref)}
+				}
+				continue
+			}
+		}
+		if p.In == "body" {
+			if o != nil {
+				o.RequestBody = &requestBody{Description: p.Description, Required: p.Required, Content: content(consumes, p.Schema)}
+			}
+			continue
+		}
+		if p.Schema == nil && p.Ref == "" {
+			sc := p.schema
+			sc.Description = ""
+			p.Schema = &sc
+		}
+		res = append(res, p)
+	}
+	return res
+}
+
+// content builds the OpenAPI 3 content map for the given Swagger 2.0 schema and mime types.
+func content(mimeTypes []string, sc *schema) map[string]*mediaType {
+	if sc == nil {
+		return nil
+	}
+	if len(mimeTypes) == 0 {
+		mimeTypes = []string{"application/json"}
+	}
+	c := make(map[string]*mediaType, len(mimeTypes))
+	for _, m := range mimeTypes {
+		c[m] = &mediaType{Schema: sc}
+	}
+	return c
+}
+
+// operation returns the path item operation for the given HTTP method if any.
+func (p *pathItem) operation(method string) *operation {
+	switch method {
+	case "GET":
+		return p.Get
+	case "HEAD":
+		return p.Head
+	case "POST":
+		return p.Post
+	case "PUT":
+		return p.Put
+	case "PATCH":
+		return p.Patch
+	case "DELETE":
+		return p.Delete
+	case "OPTIONS":
+		return p.Options
+	case "TRACE":
+		return p.Trace
+	}
+	return nil
+}
+
+// types returns the JSON types of the schema, OpenAPI 3.1 allows for a list of types.
+func (sc *schema) types() []string {
+	switch t := sc.Type.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		var res []string
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+// additionalProperties returns the schema of the additional properties if any.
+func (sc *schema) additionalProperties() *schema {
+	if len(sc.AdditionalProperties) == 0 {
+		return nil
+	}
+	var allowed bool
+	if err := json.Unmarshal(sc.AdditionalProperties, &allowed); err == nil {
+		if allowed {
+			return &schema{}
+		}
+		return nil
+	}
+	var ap schema
+	if err := json.Unmarshal(sc.AdditionalProperties, &ap); err != nil {
+		return nil
+	}
+	return &ap
+}
+
+// refName returns the name of the component referred to by the given JSON reference.
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// UnmarshalJSON records the order of the schema names.
+func (s *schemas) UnmarshalJSON(b []byte) error {
+	s.Map = make(map[string]*schema)
+	return decodeOrdered(b, func(key string, raw json.RawMessage) error {
+		var sc schema
+		if err := json.Unmarshal(raw, &sc); err != nil {
+			return err
+		}
+		s.Names = append(s.Names, key)
+		s.Map[key] = &sc
+		return nil
+	})
+}
+
+// UnmarshalJSON records the order of the paths.
+func (p *paths) UnmarshalJSON(b []byte) error {
+	p.Map = make(map[string]*pathItem)
+	return decodeOrdered(b, func(key string, raw json.RawMessage) error {
+		if strings.HasPrefix(key, "x-") {
+			return nil
+		}
+		var item pathItem
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return err
+		}
+		p.Names = append(p.Names, key)
+		p.Map[key] = &item
+		return nil
+	})
+}
+
+// UnmarshalJSON records the order of the responses.
+func (r *responses) UnmarshalJSON(b []byte) error {
+	r.Map = make(map[string]*response)
+	return decodeOrdered(b, func(key string, raw json.RawMessage) error {
+		if strings.HasPrefix(key, "x-") {
+			return nil
+		}
+		var resp response
+		if err := json.Unmarshal(raw, &resp); err != nil {
+			return err
+		}
+		r.Names = append(r.Names, key)
+		r.Map[key] = &resp
+		return nil
+	})
+}
+
+// decodeOrdered calls fn for each key of the JSON object b in order.
+func decodeOrdered(b []byte, fn func(string, json.RawMessage) error) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return nil
+	}
+	if d, ok := t.(json.Delim); !ok || d != '{' {
+		return fmt.Errorf("expected JSON object, got %v", t)
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		if err := fn(t.(string), raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJSON writes the JSON representation of the YAML value v preserving the order of the keys.
+func writeJSON(buf *bytes.Buffer, v interface{}) error {
+	switch actual := v.(type) {
+	case yaml.MapSlice:
+		buf.WriteByte('{')
+		for i, item := range actual {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			k, err := json.Marshal(fmt.Sprintf("%v", item.Key))
+			if err != nil {
+				return err
+			}
+			buf.Write(k)
+			buf.WriteByte(':')
+			if err := writeJSON(buf, item.Value); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, e := range actual {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, e); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		b, err := json.Marshal(actual)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+	return nil
+}
@@ -453,7 +453,10 @@ func (c *{{ $ctrlName }}) {{ goify .Name true }}WSHandler(ctx *{{ targetPkg }}.{
 const mainT = `
 func main() {
 	// Create service
-	service := goa.New({{ printf "%q" .Name }})
+	service := goa.New({{ printf "%q" .Name }}){{ if .API.ProblemDetails }}
+
+	// Render errors as RFC 7807 problem details documents
+	service.ProblemDetails = true{{ end }}
 
 	// Mount middleware
 	service.Use(middleware.RequestID())
@@ -465,6 +468,8 @@ func main() {
 	{{ $tmp := tempvar }}{{ $tmp }} := New{{ $name }}Controller(service)
 	{{ targetPkg }}.Mount{{ $name }}Controller(service, {{ $tmp }})
 {{ end }}
+	// Drain in-flight requests on SIGINT or SIGTERM
+	service.ShutdownOnSignal(30 * time.Second)
 
 {{ if .TLS }}
 	// Start service
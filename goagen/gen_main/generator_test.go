@@ -112,6 +112,19 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(MatchRegexp(`// FirstController_Alpha: start_implement\s*// Put your logic here\s*return nil\s*// FirstController_Alpha: end_implement`))
 		})
 
+		Context("with problem details", func() {
+			BeforeEach(func() {
+				design.Design.ProblemDetails = true
+			})
+
+			It("configures the service to render problem details", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "main.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring("service.ProblemDetails = true"))
+			})
+		})
+
 		Context("regenerated with a new resource", func() {
 			BeforeEach(func() {
 				// Perform a first generation
@@ -237,12 +250,18 @@ var _ = Describe("NewGenerator", func() {
 })
 
 const listenAndServeCode = `
+	service.ShutdownOnSignal(30 * time.Second)
+
+	// Start service
 	if err := service.ListenAndServe(":8080"); err != nil {
 		service.LogError("startup", "err", err)
 	}
 `
 
 const listenAndServeTLSCode = `
+	service.ShutdownOnSignal(30 * time.Second)
+
+	// Start service
 	if err := service.ListenAndServeTLS(":8080", "cert.pem", "key.pem"); err != nil {
 		service.LogError("startup", "err", err)
 	}
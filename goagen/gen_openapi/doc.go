@@ -0,0 +1,7 @@
+/*
+Package genopenapi provides a generator for OpenAPI 3.x specifications.
+The generated documents describe the API paths, request bodies, responses and security schemes
+and are written in both JSON and YAML formats. See https://spec.openapis.org/oas/v3.0.3 for
+more information on the OpenAPI specification.
+*/
+package genopenapi
@@ -0,0 +1,91 @@
+package genopenapi_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	genopenapi "github.com/goadesign/goa/goagen/gen_openapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genopenapi.Generator
+
+	var args = struct {
+		api     *design.APIDefinition
+		outDir  string
+		version string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir:  "out_dir",
+		version: genopenapi.Version31,
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genopenapi.NewGenerator(
+				genopenapi.API(args.api),
+				genopenapi.OutDir(args.outDir),
+				genopenapi.Version(args.version),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Version).Should(Equal(args.version))
+		})
+	})
+
+	Context("with no version option", func() {
+		BeforeEach(func() {
+			generator = genopenapi.NewGenerator()
+		})
+
+		It("defaults to OpenAPI 3.0", func() {
+			Ω(generator.Version).Should(Equal(genopenapi.Version30))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		outDir, err = ioutil.TempDir("", "openapi")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		g := genopenapi.NewGenerator(
+			genopenapi.API(&design.APIDefinition{Name: "test api", Title: "test"}),
+			genopenapi.OutDir(outDir),
+		)
+		files, genErr = g.Generate()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+	})
+
+	It("generates the JSON and YAML specifications", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(ConsistOf(
+			filepath.Join(outDir, "openapi"),
+			filepath.Join(outDir, "openapi", "openapi.json"),
+			filepath.Join(outDir, "openapi", "openapi.yaml"),
+		))
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "openapi", "openapi.yaml"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(HavePrefix("openapi: 3.0.3\n"))
+	})
+})
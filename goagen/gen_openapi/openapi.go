@@ -0,0 +1,1060 @@
+package genopenapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	genschema "github.com/goadesign/goa/goagen/gen_schema"
+)
+
+type (
+	// OpenAPI represents an instance of an OpenAPI 3.x document.
+	// See https://spec.openapis.org/oas/v3.0.3
+	OpenAPI struct {
+		OpenAPI      string                 `json:"openapi"`
+		Info         *Info                  `json:"info"`
+		Servers      []*Server              `json:"servers,omitempty"`
+		Paths        map[string]interface{} `json:"paths"`
+		Components   *Components            `json:"components,omitempty"`
+		Security     []map[string][]string  `json:"security,omitempty"`
+		Tags         []*Tag                 `json:"tags,omitempty"`
+		ExternalDocs *ExternalDocs          `json:"externalDocs,omitempty"`
+	}
+
+	// Info provides metadata about the API.
+	Info struct {
+		Title          string                    `json:"title"`
+		Description    string                    `json:"description,omitempty"`
+		TermsOfService string                    `json:"termsOfService,omitempty"`
+		Contact        *design.ContactDefinition `json:"contact,omitempty"`
+		License        *design.LicenseDefinition `json:"license,omitempty"`
+		Version        string                    `json:"version"`
+		Extensions     map[string]interface{}    `json:"-"`
+	}
+
+	// Server represents a server hosting the API.
+	Server struct {
+		// URL to the target host.
+		URL string `json:"url"`
+		// Description of the host designated by the URL.
+		Description string `json:"description,omitempty"`
+	}
+
+	// Path describes the operations available on a single path.
+	Path struct {
+		// Get defines a GET operation on this path.
+		Get *Operation `json:"get,omitempty"`
+		// Put defines a PUT operation on this path.
+		Put *Operation `json:"put,omitempty"`
+		// Post defines a POST operation on this path.
+		Post *Operation `json:"post,omitempty"`
+		// Delete defines a DELETE operation on this path.
+		Delete *Operation `json:"delete,omitempty"`
+		// Options defines a OPTIONS operation on this path.
+		Options *Operation `json:"options,omitempty"`
+		// Head defines a HEAD operation on this path.
+		Head *Operation `json:"head,omitempty"`
+		// Patch defines a PATCH operation on this path.
+		Patch *Operation `json:"patch,omitempty"`
+		// Trace defines a TRACE operation on this path.
+		Trace *Operation `json:"trace,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// Operation describes a single API operation on a path.
+	Operation struct {
+		// Tags is a list of tags for API documentation control.
+		Tags []string `json:"tags,omitempty"`
+		// Summary is a short summary of what the operation does.
+		Summary string `json:"summary,omitempty"`
+		// Description is a verbose explanation of the operation behavior.
+		Description string `json:"description,omitempty"`
+		// ExternalDocs points to additional external documentation for this operation.
+		ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+		// OperationID is a unique string used to identify the operation.
+		OperationID string `json:"operationId,omitempty"`
+		// Parameters is a list of parameters that are applicable for this operation.
+		Parameters []*Parameter `json:"parameters,omitempty"`
+		// RequestBody describes the request body if any.
+		RequestBody *RequestBody `json:"requestBody,omitempty"`
+		// Responses is the list of possible responses indexed by HTTP status code.
+		Responses map[string]*Response `json:"responses"`
+		// Deprecated declares this operation to be deprecated.
+		Deprecated bool `json:"deprecated,omitempty"`
+		// Security is a declaration of which security schemes are applied for this operation.
+		Security []map[string][]string `json:"security,omitempty"`
+		// Servers overrides the API servers for this operation.
+		Servers []*Server `json:"servers,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// Parameter describes a single operation parameter.
+	Parameter struct {
+		// Name of the parameter. Parameter names are case sensitive.
+		Name string `json:"name"`
+		// In is the location of the parameter.
+		// Possible values are "query", "header", "path" or "cookie".
+		In string `json:"in"`
+		// Description is a brief description of the parameter.
+		Description string `json:"description,omitempty"`
+		// Required determines whether this parameter is mandatory.
+		Required bool `json:"required"`
+		// Deprecated specifies that the parameter is deprecated.
+		Deprecated bool `json:"deprecated,omitempty"`
+		// Schema defines the type used for the parameter.
+		Schema *Schema `json:"schema,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// RequestBody describes a single request body.
+	RequestBody struct {
+		// Description is a brief description of the request body.
+		Description string `json:"description,omitempty"`
+		// Content describes the request body content indexed by media type.
+		Content map[string]*MediaType `json:"content"`
+		// Required determines if the request body is required in the request.
+		Required bool `json:"required,omitempty"`
+	}
+
+	// MediaType provides the schema and examples for a given media type.
+	MediaType struct {
+		// Schema defines the type used for the content.
+		Schema *Schema `json:"schema,omitempty"`
+		// Example of the media type.
+		Example interface{} `json:"example,omitempty"`
+	}
+
+	// Response describes a single response from an API operation.
+	Response struct {
+		// Ref references a response defined in the document components.
+		// This field is exclusive with the other fields of Response.
+		Ref string `json:"$ref,omitempty"`
+		// Description of the response.
+		Description string `json:"description,omitempty"`
+		// Headers maps a header name to its definition.
+		Headers map[string]*Header `json:"headers,omitempty"`
+		// Content describes the response body indexed by media type.
+		Content map[string]*MediaType `json:"content,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// Header represents a response header.
+	Header struct {
+		// Description is a brief description of the header.
+		Description string `json:"description,omitempty"`
+		// Required determines whether this header is mandatory.
+		Required bool `json:"required,omitempty"`
+		// Schema defines the type used for the header.
+		Schema *Schema `json:"schema,omitempty"`
+	}
+
+	// Components holds the reusable objects referenced by the document.
+	Components struct {
+		// Schemas contains the type definitions.
+		Schemas map[string]*Schema `json:"schemas,omitempty"`
+		// Responses contains the API level responses.
+		Responses map[string]*Response `json:"responses,omitempty"`
+		// SecuritySchemes contains the API security scheme definitions.
+		SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	// SecurityScheme defines a security scheme that can be used by the operations.
+	SecurityScheme struct {
+		// Type of the security scheme. Valid values are "apiKey", "http", "oauth2" or
+		// "openIdConnect".
+		Type string `json:"type"`
+		// Description for security scheme
+		Description string `json:"description,omitempty"`
+		// Name of the header, query or cookie parameter to be used when type is "apiKey".
+		Name string `json:"name,omitempty"`
+		// In is the location of the API key when type is "apiKey".
+		In string `json:"in,omitempty"`
+		// Scheme is the name of the HTTP Authorization scheme when type is "http".
+		Scheme string `json:"scheme,omitempty"`
+		// BearerFormat is a hint to the client to identify how the bearer token is
+		// formatted.
+		BearerFormat string `json:"bearerFormat,omitempty"`
+		// Flows contains the configuration for the OAuth2 flows when type is "oauth2".
+		Flows *OAuthFlows `json:"flows,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// OAuthFlows allows configuration of the supported OAuth2 flows.
+	OAuthFlows struct {
+		Implicit          *OAuthFlow `json:"implicit,omitempty"`
+		Password          *OAuthFlow `json:"password,omitempty"`
+		ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+		AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+	}
+
+	// OAuthFlow contains the configuration details of a single OAuth2 flow.
+	OAuthFlow struct {
+		// AuthorizationURL is the authorization URL to be used for this flow.
+		AuthorizationURL string `json:"authorizationUrl,omitempty"`
+		// TokenURL is the token URL to be used for this flow.
+		TokenURL string `json:"tokenUrl,omitempty"`
+		// Scopes lists the available scopes for the flow.
+		Scopes map[string]string `json:"scopes"`
+	}
+
+	// Schema is the OpenAPI flavor of JSON schema used to describe data types.
+	Schema struct {
+		Ref                  string             `json:"$ref,omitempty"`
+		Title                string             `json:"title,omitempty"`
+		Description          string             `json:"description,omitempty"`
+		Type                 string             `json:"type,omitempty"`
+		Format               string             `json:"format,omitempty"`
+		Items                *Schema            `json:"items,omitempty"`
+		Properties           map[string]*Schema `json:"properties,omitempty"`
+		AdditionalProperties bool               `json:"additionalProperties,omitempty"`
+		Required             []string           `json:"required,omitempty"`
+		Enum                 []interface{}      `json:"enum,omitempty"`
+		Default              interface{}        `json:"default,omitempty"`
+		Example              interface{}        `json:"example,omitempty"`
+		Examples             []interface{}      `json:"examples,omitempty"`
+		Pattern              string             `json:"pattern,omitempty"`
+		Minimum              *float64           `json:"minimum,omitempty"`
+		Maximum              *float64           `json:"maximum,omitempty"`
+		MinLength            *int               `json:"minLength,omitempty"`
+		MaxLength            *int               `json:"maxLength,omitempty"`
+		MinItems             *int               `json:"minItems,omitempty"`
+		MaxItems             *int               `json:"maxItems,omitempty"`
+		MinProperties        *int               `json:"minProperties,omitempty"`
+		MaxProperties        *int               `json:"maxProperties,omitempty"`
+		ReadOnly             bool               `json:"readOnly,omitempty"`
+		Deprecated           bool               `json:"deprecated,omitempty"`
+		OneOf                []*Schema          `json:"oneOf,omitempty"`
+	}
+
+	// ExternalDocs allows referencing an external resource for extended documentation.
+	ExternalDocs struct {
+		// Description is a short description of the target documentation.
+		Description string `json:"description,omitempty"`
+		// URL for the target documentation.
+		URL string `json:"url"`
+	}
+
+	// Tag adds metadata to a single tag that is used by the Operation Object.
+	Tag struct {
+		// Name of the tag.
+		Name string `json:"name"`
+		// Description is a short description of the tag.
+		Description string `json:"description,omitempty"`
+		// ExternalDocs is additional external documentation for this tag.
+		ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// These types are used in marshalJSON() to avoid recursive call of json.Marshal().
+	_Info           Info
+	_Path           Path
+	_Operation      Operation
+	_Parameter      Parameter
+	_Response       Response
+	_SecurityScheme SecurityScheme
+	_Tag            Tag
+
+	// builder holds the state needed while building a document.
+	builder struct {
+		api     *design.APIDefinition
+		version string
+		doc     *OpenAPI
+	}
+)
+
+const (
+	// Version30 is the OpenAPI 3.0 specification version.
+	Version30 = "3.0.3"
+	// Version31 is the OpenAPI 3.1 specification version.
+	Version31 = "3.1.0"
+)
+
+// definitionsPrefix is the prefix used by the JSON schema references produced by genschema.
+const definitionsPrefix = "#/definitions/"
+
+// componentsPrefix is the prefix used by OpenAPI schema references.
+const componentsPrefix = "#/components/schemas/"
+
+func marshalJSON(v interface{}, extensions map[string]interface{}) ([]byte, error) {
+	marshaled, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(extensions) == 0 {
+		return marshaled, nil
+	}
+	var unmarshaled map[string]interface{}
+	if err := json.Unmarshal(marshaled, &unmarshaled); err != nil {
+		return nil, err
+	}
+	for k, v := range extensions {
+		unmarshaled[k] = v
+	}
+	return json.Marshal(unmarshaled)
+}
+
+// MarshalJSON returns the JSON encoding of i.
+func (i Info) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Info(i), i.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of p.
+func (p Path) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Path(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of o.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Operation(o), o.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of p.
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Parameter(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r Response) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Response(r), r.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of s.
+func (s SecurityScheme) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_SecurityScheme(s), s.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of t.
+func (t Tag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Tag(t), t.Extensions)
+}
+
+// New creates an OpenAPI 3.0 document from an API definition.
+func New(api *design.APIDefinition) (*OpenAPI, error) {
+	return NewWithVersion(api, Version30)
+}
+
+// NewWithVersion creates an OpenAPI document that complies with the given version of the
+// specification from an API definition. version must start with either "3.0" or "3.1".
+func NewWithVersion(api *design.APIDefinition, version string) (*OpenAPI, error) {
+	if api == nil {
+		return nil, nil
+	}
+	if !strings.HasPrefix(version, "3.0") && !strings.HasPrefix(version, "3.1") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %#v, must be 3.0.x or 3.1.x", version)
+	}
+	b := &builder{api: api, version: version}
+	b.doc = &OpenAPI{
+		OpenAPI: version,
+		Info: &Info{
+			Title:          api.Title,
+			Description:    api.Description,
+			TermsOfService: api.TermsOfService,
+			Contact:        api.Contact,
+			License:        api.License,
+			Version:        api.Version,
+			Extensions:     extensionsFromDefinition(api.Metadata),
+		},
+		Servers:      serversFromDefinition(api.Host, api.Schemes),
+		Paths:        make(map[string]interface{}),
+		Tags:         tagsFromDefinition(api.Metadata),
+		ExternalDocs: docsFromDefinition(api.Docs),
+		Components: &Components{
+			SecuritySchemes: securitySchemesFromDefinition(api.SecuritySchemes),
+		},
+	}
+	if api.Security != nil && api.Security.Scheme.Kind != design.NoSecurityKind {
+		b.doc.Security = securityRequirement(api.Security)
+	}
+
+	err := api.IterateResponses(func(r *design.ResponseDefinition) error {
+		if b.doc.Components.Responses == nil {
+			b.doc.Components.Responses = make(map[string]*Response)
+		}
+		b.doc.Components.Responses[r.Name] = b.responseFromDefinition(r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.IterateResources(func(res *design.ResourceDefinition) error {
+		for k, v := range extensionsFromDefinition(res.Metadata) {
+			b.doc.Paths[k] = v
+		}
+		err := res.IterateFileServers(func(fs *design.FileServerDefinition) error {
+			if !mustGenerate(fs.Metadata) {
+				return nil
+			}
+			b.buildPathFromFileServer(fs)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if !mustGenerate(a.Metadata) {
+				return nil
+			}
+			for _, route := range a.Routes {
+				if err := b.buildPathFromDefinition(route); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if api.Health != nil {
+		b.buildPathsFromHealth(api.Health)
+	}
+	if len(genschema.Definitions) > 0 {
+		b.doc.Components.Schemas = make(map[string]*Schema, len(genschema.Definitions))
+		for n, d := range genschema.Definitions {
+			b.doc.Components.Schemas[n] = b.schemaFromJSONSchema(d)
+		}
+	}
+	return b.doc, nil
+}
+
+// mustGenerate returns true if the metadata indicates that an OpenAPI specification should be
+// generated, false otherwise. Both the "openapi:generate" and "swagger:generate" keys are
+// honored.
+func mustGenerate(meta dslengine.MetadataDefinition) bool {
+	for _, key := range []string{"openapi:generate", "swagger:generate"} {
+		if m, ok := meta[key]; ok {
+			if len(m) > 0 && m[0] == "false" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// serversFromDefinition returns the list of servers for the given host and URL schemes.
+func serversFromDefinition(host string, schemes []string) []*Server {
+	if host == "" {
+		return nil
+	}
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	servers := make([]*Server, len(schemes))
+	for i, s := range schemes {
+		servers[i] = &Server{URL: fmt.Sprintf("%s://%s", s, host)}
+	}
+	return servers
+}
+
+func securitySchemesFromDefinition(schemes []*design.SecuritySchemeDefinition) map[string]*SecurityScheme {
+	if len(schemes) == 0 {
+		return nil
+	}
+	defs := make(map[string]*SecurityScheme)
+	for _, scheme := range schemes {
+		def := &SecurityScheme{
+			Description: scheme.Description,
+			Extensions:  extensionsFromDefinition(scheme.Metadata),
+		}
+		switch scheme.Kind {
+		case design.BasicAuthSecurityKind:
+			def.Type = "http"
+			def.Scheme = "basic"
+		case design.APIKeySecurityKind:
+			def.Type = "apiKey"
+			def.Name = scheme.Name
+			def.In = scheme.In
+		case design.JWTSecurityKind:
+			if scheme.In == "header" && strings.EqualFold(scheme.Name, "Authorization") {
+				def.Type = "http"
+				def.Scheme = "bearer"
+				def.BearerFormat = "JWT"
+			} else {
+				def.Type = "apiKey"
+				def.Name = scheme.Name
+				def.In = scheme.In
+			}
+			if scheme.TokenURL != "" {
+				def.Description += fmt.Sprintf("\n\n**Token URL**: %s", scheme.TokenURL)
+			}
+			if len(scheme.Scopes) != 0 {
+				def.Description += fmt.Sprintf("\n\n**Security Scopes**:\n%s", scopesMapList(scheme.Scopes))
+			}
+		case design.OAuth2SecurityKind:
+			def.Type = "oauth2"
+			def.Flows = oauthFlowsFromDefinition(scheme)
+		}
+		defs[scheme.SchemeName] = def
+	}
+	return defs
+}
+
+// oauthFlowsFromDefinition maps the Swagger 2.0 flow names used by the design onto the OpenAPI
+// 3 flow objects.
+func oauthFlowsFromDefinition(scheme *design.SecuritySchemeDefinition) *OAuthFlows {
+	scopes := scheme.Scopes
+	if scopes == nil {
+		scopes = make(map[string]string)
+	}
+	flow := &OAuthFlow{
+		AuthorizationURL: scheme.AuthorizationURL,
+		TokenURL:         scheme.TokenURL,
+		Scopes:           scopes,
+	}
+	flows := &OAuthFlows{}
+	switch scheme.Flow {
+	case "implicit":
+		flow.TokenURL = ""
+		flows.Implicit = flow
+	case "password":
+		flow.AuthorizationURL = ""
+		flows.Password = flow
+	case "application":
+		flow.AuthorizationURL = ""
+		flows.ClientCredentials = flow
+	default:
+		flows.AuthorizationCode = flow
+	}
+	return flows
+}
+
+func scopesMapList(scopes map[string]string) string {
+	names := make([]string, 0, len(scopes))
+	for name := range scopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = fmt.Sprintf("  * `%s`: %s", name, scopes[name])
+	}
+	return strings.Join(lines, "\n")
+}
+
+func securityRequirement(security *design.SecurityDefinition) []map[string][]string {
+	scopes := security.Scopes
+	if scopes == nil {
+		scopes = make([]string, 0)
+	}
+	return []map[string][]string{{security.Scheme.SchemeName: scopes}}
+}
+
+// metadataValues returns the values of the metadata key using either the "openapi" or
+// "swagger" prefix, "openapi" wins if both are set.
+func metadataValues(mdata dslengine.MetadataDefinition, suffix string) []string {
+	if v, ok := mdata["openapi:"+suffix]; ok {
+		return v
+	}
+	return mdata["swagger:"+suffix]
+}
+
+func tagsFromDefinition(mdata dslengine.MetadataDefinition) (tags []*Tag) {
+	var names []string
+	seen := make(map[string]bool)
+	for k := range mdata {
+		chunks := strings.Split(k, ":")
+		if len(chunks) != 3 {
+			continue
+		}
+		if (chunks[0] != "swagger" && chunks[0] != "openapi") || chunks[1] != "tag" {
+			continue
+		}
+		if !seen[chunks[2]] {
+			seen[chunks[2]] = true
+			names = append(names, chunks[2])
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tag := &Tag{Name: name}
+		if d := metadataValues(mdata, "tag:"+name+":desc"); len(d) > 0 {
+			tag.Description = d[0]
+		}
+		if u := metadataValues(mdata, "tag:"+name+":url"); len(u) > 0 {
+			tag.ExternalDocs = &ExternalDocs{URL: u[0]}
+			if d := metadataValues(mdata, "tag:"+name+":url:desc"); len(d) > 0 {
+				tag.ExternalDocs.Description = d[0]
+			}
+		}
+		tags = append(tags, tag)
+	}
+	return
+}
+
+func tagNamesFromDefinitions(mdatas ...dslengine.MetadataDefinition) (tagNames []string) {
+	for _, mdata := range mdatas {
+		for _, tag := range tagsFromDefinition(mdata) {
+			tagNames = append(tagNames, tag.Name)
+		}
+	}
+	return
+}
+
+func summaryFromDefinition(name string, mdata dslengine.MetadataDefinition) string {
+	if s := metadataValues(mdata, "summary"); len(s) > 0 {
+		return s[0]
+	}
+	return name
+}
+
+// extensionsFromDefinition returns the OpenAPI extensions defined via metadata using either
+// the "openapi:extension:x-" or "swagger:extension:x-" prefix.
+func extensionsFromDefinition(mdata dslengine.MetadataDefinition) map[string]interface{} {
+	extensions := make(map[string]interface{})
+	for key, value := range mdata {
+		chunks := strings.Split(key, ":")
+		if len(chunks) != 3 {
+			continue
+		}
+		if (chunks[0] != "swagger" && chunks[0] != "openapi") || chunks[1] != "extension" {
+			continue
+		}
+		if !strings.HasPrefix(chunks[2], "x-") || len(value) == 0 {
+			continue
+		}
+		if _, ok := extensions[chunks[2]]; ok && chunks[0] == "swagger" {
+			continue
+		}
+		val := value[0]
+		var ival interface{}
+		if err := json.Unmarshal([]byte(val), &ival); err != nil {
+			extensions[chunks[2]] = val
+			continue
+		}
+		extensions[chunks[2]] = ival
+	}
+	if len(extensions) == 0 {
+		return nil
+	}
+	return extensions
+}
+
+func docsFromDefinition(docs *design.DocsDefinition) *ExternalDocs {
+	if docs == nil {
+		return nil
+	}
+	return &ExternalDocs{
+		Description: docs.Description,
+		URL:         docs.URL,
+	}
+}
+
+// buildPathFromFileServer adds the GET operation that serves the files of fs.
+func (b *builder) buildPathFromFileServer(fs *design.FileServerDefinition) {
+	wcs := design.ExtractWildcards(fs.RequestPath)
+	var params []*Parameter
+	if len(wcs) > 0 {
+		params = []*Parameter{{
+			In:          "path",
+			Name:        wcs[0],
+			Description: "Relative file path",
+			Required:    true,
+			Schema:      &Schema{Type: "string"},
+		}}
+	}
+	responses := map[string]*Response{
+		"200": {
+			Description: "File downloaded",
+			Content: map[string]*MediaType{
+				"application/octet-stream": {Schema: b.binarySchema()},
+			},
+		},
+	}
+	if len(wcs) > 0 {
+		errMedia := b.api.ErrorResponseMedia()
+		responses["404"] = &Response{
+			Description: "File not found",
+			Content: map[string]*MediaType{
+				errMedia.Identifier: {Schema: b.typeSchema(errMedia)},
+			},
+		}
+	}
+	operation := &Operation{
+		Description:  fs.Description,
+		Summary:      summaryFromDefinition(fmt.Sprintf("Download %s", fs.FilePath), fs.Metadata),
+		ExternalDocs: docsFromDefinition(fs.Docs),
+		OperationID:  fmt.Sprintf("%s#%s", fs.Parent.Name, fs.RequestPath),
+		Parameters:   params,
+		Responses:    responses,
+	}
+	if fs.Security != nil && fs.Security.Scheme.Kind != design.NoSecurityKind {
+		operation.Security = securityRequirement(fs.Security)
+	}
+	p := b.path(fs.RequestPath)
+	p.Get = operation
+	p.Extensions = extensionsFromDefinition(fs.Metadata)
+}
+
+// buildPathsFromHealth adds the operations corresponding to the liveness and readiness
+// endpoints mounted by the health package.
+func (b *builder) buildPathsFromHealth(h *design.HealthDefinition) {
+	probes := []struct{ name, path, summary string }{
+		{"live", h.LivePath, "Liveness probe"},
+		{"ready", h.ReadyPath, "Readiness probe"},
+	}
+	errMedia := b.api.ErrorResponseMedia()
+	for _, probe := range probes {
+		report := &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"status": {Type: "string"},
+				"checks": {Type: "object", AdditionalProperties: true},
+			},
+		}
+		responses := map[string]*Response{
+			"200": {
+				Description: "Healthy",
+				Content:     map[string]*MediaType{"application/json": {Schema: report}},
+			},
+			"503": {
+				Description: "Unhealthy",
+				Content: map[string]*MediaType{
+					errMedia.Identifier: {Schema: b.typeSchema(errMedia)},
+				},
+			},
+		}
+		b.path(probe.path).Get = &Operation{
+			Tags:        []string{"health"},
+			Summary:     probe.summary,
+			OperationID: "health#" + probe.name,
+			Responses:   responses,
+		}
+	}
+}
+
+// buildPathFromDefinition adds the operation corresponding to the given action route.
+func (b *builder) buildPathFromDefinition(route *design.RouteDefinition) error {
+	action := route.Parent
+
+	tagNames := tagNamesFromDefinitions(action.Parent.Metadata, action.Metadata)
+	if len(tagNames) == 0 {
+		// By default tag with resource name
+		tagNames = []string{action.Parent.Name}
+	}
+	params, err := b.paramsFromDefinition(action.AllParams(), route.FullPath())
+	if err != nil {
+		return err
+	}
+	params = append(params, b.paramsFromHeaders(action)...)
+
+	responses := make(map[string]*Response, len(action.Responses))
+	action.IterateResponses(func(r *design.ResponseDefinition) error {
+		responses[strconv.Itoa(r.Status)] = b.responseFromDefinition(r)
+		return nil
+	})
+
+	operationID := fmt.Sprintf("%s#%s", action.Parent.Name, action.Name)
+	for i, rt := range action.Routes {
+		if rt == route && i > 0 {
+			operationID = fmt.Sprintf("%s#%d", operationID, i)
+			break
+		}
+	}
+
+	operation := &Operation{
+		Tags:         tagNames,
+		Description:  action.Description,
+		Summary:      summaryFromDefinition(action.Name+" "+action.Parent.Name, action.Metadata),
+		ExternalDocs: docsFromDefinition(action.Docs),
+		OperationID:  operationID,
+		Parameters:   params,
+		RequestBody:  b.requestBodyFromDefinition(action),
+		Responses:    responses,
+		Deprecated:   action.EffectiveDeprecation() != nil,
+		Extensions:   extensionsFromDefinition(route.Metadata),
+	}
+	if len(action.Schemes) > 0 && !reflect.DeepEqual(action.Schemes, b.api.Schemes) {
+		operation.Servers = serversFromDefinition(b.api.Host, action.Schemes)
+	}
+	if action.Security != nil && action.Security.Scheme.Kind != design.NoSecurityKind {
+		if action.Security.Scheme.Kind == design.JWTSecurityKind && len(action.Security.Scopes) > 0 {
+			if operation.Description != "" {
+				operation.Description += "\n\n"
+			}
+			operation.Description += fmt.Sprintf("Required security scopes:\n%s", scopesList(action.Security.Scopes))
+		}
+		operation.Security = securityRequirement(action.Security)
+	}
+
+	p := b.path(route.FullPath())
+	p.setOperation(route.Verb, operation)
+	p.Extensions = extensionsFromDefinition(action.Metadata)
+	return nil
+}
+
+// setOperation sets the operation for the given HTTP verb.
+func (p *Path) setOperation(verb string, operation *Operation) {
+	switch verb {
+	case "GET":
+		p.Get = operation
+	case "PUT":
+		p.Put = operation
+	case "POST":
+		p.Post = operation
+	case "DELETE":
+		p.Delete = operation
+	case "OPTIONS":
+		p.Options = operation
+	case "HEAD":
+		p.Head = operation
+	case "PATCH":
+		p.Patch = operation
+	case "TRACE":
+		p.Trace = operation
+	}
+}
+
+// path returns the path object for the given goa request path, creating it if needed.
+func (b *builder) path(requestPath string) *Path {
+	key := design.WildcardRegex.ReplaceAllStringFunc(
+		requestPath,
+		func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		},
+	)
+	if key == "" {
+		key = "/"
+	}
+	if p, ok := b.doc.Paths[key].(*Path); ok {
+		return p
+	}
+	p := new(Path)
+	b.doc.Paths[key] = p
+	return p
+}
+
+func scopesList(scopes []string) string {
+	sort.Strings(scopes)
+	lines := make([]string, len(scopes))
+	for i, scope := range scopes {
+		lines[i] = fmt.Sprintf("  * `%s`", scope)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (b *builder) paramsFromDefinition(params *design.AttributeDefinition, path string) ([]*Parameter, error) {
+	if params == nil {
+		return nil, nil
+	}
+	obj := params.Type.ToObject()
+	if obj == nil {
+		return nil, fmt.Errorf("invalid parameters definition, not an object")
+	}
+	res := make([]*Parameter, 0, len(obj))
+	wildcards := design.ExtractWildcards(path)
+	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		in := "query"
+		required := params.IsRequired(n)
+		for _, w := range wildcards {
+			if n == w {
+				in = "path"
+				required = true
+				break
+			}
+		}
+		res = append(res, b.paramFor(at, n, in, required))
+		return nil
+	})
+	return res, nil
+}
+
+func (b *builder) paramsFromHeaders(action *design.ActionDefinition) []*Parameter {
+	var params []*Parameter
+	action.IterateHeaders(func(name string, required bool, header *design.AttributeDefinition) error {
+		params = append(params, b.paramFor(header, name, "header", required))
+		return nil
+	})
+	return params
+}
+
+func (b *builder) paramFor(at *design.AttributeDefinition, name, in string, required bool) *Parameter {
+	return &Parameter{
+		In:          in,
+		Name:        name,
+		Description: at.Description,
+		Required:    required,
+		Deprecated:  at.Deprecation != nil,
+		Schema:      b.attributeSchema(at),
+		Extensions:  extensionsFromDefinition(at.Metadata),
+	}
+}
+
+// requestBodyFromDefinition builds the request body of the given action. The body lists one
+// schema per content type the action consumes or a single "multipart/form-data" schema if the
+// action payload is multipart.
+func (b *builder) requestBodyFromDefinition(action *design.ActionDefinition) *RequestBody {
+	if action.Payload == nil {
+		return nil
+	}
+	content := make(map[string]*MediaType)
+	if action.PayloadMultipart {
+		schema := b.typeSchema(action.Payload)
+		if schema.Ref != "" {
+			schema = b.schemaFromJSONSchema(genschema.AttributeSchema(b.api, action.Payload.AttributeDefinition))
+		}
+		content["multipart/form-data"] = &MediaType{Schema: schema}
+	} else {
+		consumes := b.api.Consumes
+		if action.OverridesConsumes() {
+			consumes = action.EffectiveConsumes()
+		}
+		for _, enc := range consumes {
+			for _, mt := range enc.MIMETypes {
+				content[mt] = &MediaType{Schema: b.typeSchema(action.Payload)}
+			}
+		}
+		if len(content) == 0 {
+			content["application/json"] = &MediaType{Schema: b.typeSchema(action.Payload)}
+		}
+	}
+	return &RequestBody{
+		Description: action.Payload.Description,
+		Content:     content,
+		Required:    !action.PayloadOptional,
+	}
+}
+
+// responseFromDefinition builds the response object for the given response definition.
+func (b *builder) responseFromDefinition(r *design.ResponseDefinition) *Response {
+	desc := r.Description
+	if desc == "" {
+		desc = http.StatusText(r.Status)
+	}
+	resp := &Response{
+		Description: desc,
+		Headers:     b.headersFromDefinition(r.Headers),
+		Extensions:  extensionsFromDefinition(r.Metadata),
+	}
+	if r.MediaType != "" {
+		var schema *Schema
+		identifier := r.MediaType
+		if mt, ok := b.api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]; ok {
+			view := r.ViewName
+			if view == "" {
+				view = design.DefaultView
+			}
+			if mt.IsError() {
+				mt = b.api.ErrorResponseMedia()
+				identifier = mt.Identifier
+			}
+			schema = &Schema{Ref: b.ref(genschema.MediaTypeRef(b.api, mt, view))}
+		} else if r.Type != nil {
+			schema = b.typeSchema(r.Type)
+		}
+		resp.Content = map[string]*MediaType{identifier: {Schema: schema}}
+	} else if r.Type != nil {
+		resp.Content = map[string]*MediaType{"application/json": {Schema: b.typeSchema(r.Type)}}
+	}
+	return resp
+}
+
+func (b *builder) headersFromDefinition(headers *design.AttributeDefinition) map[string]*Header {
+	if headers == nil {
+		return nil
+	}
+	obj := headers.Type.ToObject()
+	if obj == nil || len(obj) == 0 {
+		return nil
+	}
+	res := make(map[string]*Header, len(obj))
+	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		res[n] = &Header{
+			Description: at.Description,
+			Required:    headers.IsRequired(n),
+			Schema:      b.attributeSchema(at),
+		}
+		return nil
+	})
+	return res
+}
+
+// typeSchema returns the schema for the given data type.
+func (b *builder) typeSchema(t design.DataType) *Schema {
+	return b.schemaFromJSONSchema(genschema.TypeSchema(b.api, t))
+}
+
+// attributeSchema returns the schema for the given attribute including its validations.
+func (b *builder) attributeSchema(at *design.AttributeDefinition) *Schema {
+	return b.schemaFromJSONSchema(genschema.AttributeSchema(b.api, at))
+}
+
+// binarySchema returns the schema used to describe file content.
+func (b *builder) binarySchema() *Schema {
+	return &Schema{Type: "string", Format: "binary"}
+}
+
+// ref converts a JSON schema definition reference into an OpenAPI components reference.
+func (b *builder) ref(r string) string {
+	if strings.HasPrefix(r, definitionsPrefix) {
+		return componentsPrefix + strings.TrimPrefix(r, definitionsPrefix)
+	}
+	return r
+}
+
+// schemaFromJSONSchema converts a JSON schema produced by genschema into an OpenAPI schema.
+// The hyper-schema specific fields are dropped, "anyOf" is rendered as "oneOf" as goa unions
+// are exclusive and references are rewritten to point to the document components.
+func (b *builder) schemaFromJSONSchema(js *genschema.JSONSchema) *Schema {
+	if js == nil {
+		return nil
+	}
+	if js.Ref != "" {
+		return &Schema{Ref: b.ref(js.Ref)}
+	}
+	s := &Schema{
+		Title:                js.Title,
+		Description:          js.Description,
+		Type:                 string(js.Type),
+		Format:               js.Format,
+		AdditionalProperties: js.AdditionalProperties,
+		Required:             js.Required,
+		Enum:                 js.Enum,
+		Default:              js.DefaultValue,
+		Pattern:              js.Pattern,
+		Minimum:              js.Minimum,
+		Maximum:              js.Maximum,
+		MinLength:            js.MinLength,
+		MaxLength:            js.MaxLength,
+		MinItems:             js.MinItems,
+		MaxItems:             js.MaxItems,
+		MinProperties:        js.MinProperties,
+		MaxProperties:        js.MaxProperties,
+		ReadOnly:             js.ReadOnly,
+		Deprecated:           js.Deprecated,
+		Items:                b.schemaFromJSONSchema(js.Items),
+	}
+	if js.Type == genschema.JSONFile {
+		s.Type = "string"
+		s.Format = "binary"
+	}
+	if js.Example != nil {
+		if strings.HasPrefix(b.version, "3.1") {
+			s.Examples = []interface{}{js.Example}
+		} else {
+			s.Example = js.Example
+		}
+	}
+	if len(js.Properties) > 0 {
+		s.Properties = make(map[string]*Schema, len(js.Properties))
+		for n, p := range js.Properties {
+			s.Properties[n] = b.schemaFromJSONSchema(p)
+		}
+	}
+	for _, o := range js.AnyOf {
+		s.OneOf = append(s.OneOf, b.schemaFromJSONSchema(o))
+	}
+	return s
+}
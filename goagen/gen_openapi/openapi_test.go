@@ -0,0 +1,336 @@
+package genopenapi_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	genopenapi "github.com/goadesign/goa/goagen/gen_openapi"
+	genschema "github.com/goadesign/goa/goagen/gen_schema"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var doc *genopenapi.OpenAPI
+	var newErr error
+	var version string
+
+	BeforeEach(func() {
+		doc = nil
+		newErr = nil
+		version = genopenapi.Version30
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		doc, newErr = genopenapi.NewWithVersion(Design, version)
+	})
+
+	Context("with a valid API definition", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				Title("title")
+				Description("description")
+				Version("1.0")
+				Host("goa.design")
+				Scheme("http", "https")
+				Metadata("openapi:extension:x-api", `{"foo":"bar"}`)
+				Metadata("swagger:tag:tag", "Tag desc.")
+				Metadata("openapi:tag:tag:desc", "Tag desc.")
+			})
+		})
+
+		It("sets the basic fields", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(doc.OpenAPI).Should(Equal(genopenapi.Version30))
+			Ω(doc.Info.Title).Should(Equal("title"))
+			Ω(doc.Info.Description).Should(Equal("description"))
+			Ω(doc.Info.Version).Should(Equal("1.0"))
+			Ω(doc.Info.Extensions).Should(Equal(map[string]interface{}{
+				"x-api": map[string]interface{}{"foo": "bar"},
+			}))
+			Ω(doc.Servers).Should(Equal([]*genopenapi.Server{
+				{URL: "http://goa.design"},
+				{URL: "https://goa.design"},
+			}))
+			Ω(doc.Tags).Should(Equal([]*genopenapi.Tag{{Name: "tag", Description: "Tag desc."}}))
+		})
+	})
+
+	Context("with an unsupported version", func() {
+		BeforeEach(func() {
+			API("test", func() {})
+			version = "2.0"
+		})
+
+		It("returns an error", func() {
+			Ω(newErr).Should(HaveOccurred())
+		})
+	})
+
+	Context("with actions", func() {
+		BeforeEach(func() {
+			bottle := MediaType("application/vnd.goa.example.bottle", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("name", String)
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+				})
+			})
+			API("test", func() {
+				Consumes("application/json")
+				Consumes("application/xml")
+			})
+			Resource("bottle", func() {
+				BasePath("/bottles")
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id", Integer, func() {
+							Minimum(1)
+						})
+					})
+					Headers(func() {
+						Header("X-Account", String)
+						Required("X-Account")
+					})
+					Response(OK, bottle)
+					Response(NotFound)
+				})
+				Action("create", func() {
+					Routing(POST(""))
+					Payload(func() {
+						Attribute("name", String)
+						Required("name")
+					})
+					Response(Created, func() {
+						Headers(func() {
+							Header("Location", String)
+						})
+					})
+				})
+				Action("upload", func() {
+					Routing(PUT("/:id/label"))
+					OptionalPayload(func() {
+						Attribute("label", File)
+					})
+					MultipartForm()
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("produces the paths", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(doc.Paths).Should(HaveKey("/bottles/{id}"))
+			Ω(doc.Paths).Should(HaveKey("/bottles"))
+			Ω(doc.Paths).Should(HaveKey("/bottles/{id}/label"))
+		})
+
+		It("produces the parameters", func() {
+			show := doc.Paths["/bottles/{id}"].(*genopenapi.Path).Get
+			Ω(show).ShouldNot(BeNil())
+			Ω(show.OperationID).Should(Equal("bottle#show"))
+			Ω(show.Tags).Should(Equal([]string{"bottle"}))
+			Ω(show.Parameters).Should(HaveLen(2))
+			id := show.Parameters[0]
+			Ω(id.Name).Should(Equal("id"))
+			Ω(id.In).Should(Equal("path"))
+			Ω(id.Required).Should(BeTrue())
+			Ω(id.Schema.Type).Should(Equal("integer"))
+			Ω(*id.Schema.Minimum).Should(Equal(1.0))
+			header := show.Parameters[1]
+			Ω(header.Name).Should(Equal("X-Account"))
+			Ω(header.In).Should(Equal("header"))
+			Ω(header.Required).Should(BeTrue())
+		})
+
+		It("produces the responses", func() {
+			show := doc.Paths["/bottles/{id}"].(*genopenapi.Path).Get
+			Ω(show.Responses).Should(HaveKey("200"))
+			Ω(show.Responses).Should(HaveKey("404"))
+			ok := show.Responses["200"]
+			Ω(ok.Description).Should(Equal("OK"))
+			Ω(ok.Content).Should(HaveKey("application/vnd.goa.example.bottle"))
+			ref := ok.Content["application/vnd.goa.example.bottle"].Schema.Ref
+			Ω(ref).Should(HavePrefix("#/components/schemas/"))
+			Ω(doc.Components.Schemas).Should(HaveKey(ref[len("#/components/schemas/"):]))
+			created := doc.Paths["/bottles"].(*genopenapi.Path).Post.Responses["201"]
+			Ω(created.Headers).Should(HaveKey("Location"))
+			Ω(created.Headers["Location"].Schema.Type).Should(Equal("string"))
+		})
+
+		It("produces a request body for each content type", func() {
+			create := doc.Paths["/bottles"].(*genopenapi.Path).Post
+			Ω(create.RequestBody).ShouldNot(BeNil())
+			Ω(create.RequestBody.Required).Should(BeTrue())
+			Ω(create.RequestBody.Content).Should(HaveLen(2))
+			Ω(create.RequestBody.Content).Should(HaveKey("application/json"))
+			Ω(create.RequestBody.Content).Should(HaveKey("application/xml"))
+			ref := create.RequestBody.Content["application/json"].Schema.Ref
+			Ω(ref).Should(HavePrefix("#/components/schemas/"))
+			payload := doc.Components.Schemas[ref[len("#/components/schemas/"):]]
+			Ω(payload).ShouldNot(BeNil())
+			Ω(payload.Required).Should(Equal([]string{"name"}))
+		})
+
+		It("produces multipart request bodies", func() {
+			upload := doc.Paths["/bottles/{id}/label"].(*genopenapi.Path).Put
+			Ω(upload.RequestBody).ShouldNot(BeNil())
+			Ω(upload.RequestBody.Required).Should(BeFalse())
+			Ω(upload.RequestBody.Content).Should(HaveLen(1))
+			mt := upload.RequestBody.Content["multipart/form-data"]
+			Ω(mt).ShouldNot(BeNil())
+			Ω(mt.Schema.Properties).Should(HaveKey("label"))
+			Ω(mt.Schema.Properties["label"].Type).Should(Equal("string"))
+			Ω(mt.Schema.Properties["label"].Format).Should(Equal("binary"))
+		})
+	})
+
+	Context("with security schemes", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				BasicAuthSecurity("basic")
+				APIKeySecurity("key", func() {
+					Query("api_key")
+				})
+				JWTSecurity("jwt", func() {
+					Header("Authorization")
+					TokenURL("http://example.com/token")
+					Scope("api:read", "Read access")
+				})
+				OAuth2Security("oauth2", func() {
+					ApplicationFlow("http://example.com/token")
+					Scope("api:write", "Write access")
+				})
+				Security("jwt", func() {
+					Scope("api:read")
+				})
+			})
+		})
+
+		It("produces the security schemes", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			schemes := doc.Components.SecuritySchemes
+			Ω(schemes).Should(HaveLen(4))
+			Ω(schemes["basic"].Type).Should(Equal("http"))
+			Ω(schemes["basic"].Scheme).Should(Equal("basic"))
+			Ω(schemes["key"].Type).Should(Equal("apiKey"))
+			Ω(schemes["key"].In).Should(Equal("query"))
+			Ω(schemes["key"].Name).Should(Equal("api_key"))
+			Ω(schemes["jwt"].Type).Should(Equal("http"))
+			Ω(schemes["jwt"].Scheme).Should(Equal("bearer"))
+			Ω(schemes["jwt"].BearerFormat).Should(Equal("JWT"))
+			Ω(schemes["oauth2"].Type).Should(Equal("oauth2"))
+			Ω(schemes["oauth2"].Flows.ClientCredentials).Should(Equal(&genopenapi.OAuthFlow{
+				TokenURL: "http://example.com/token",
+				Scopes:   map[string]string{"api:write": "Write access"},
+			}))
+			Ω(doc.Security).Should(Equal([]map[string][]string{{"jwt": {"api:read"}}}))
+		})
+	})
+
+	Context("with health checks", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				HealthChecks("/health/live", "/health/ready")
+			})
+		})
+
+		It("produces the liveness and readiness operations", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			live := doc.Paths["/health/live"].(*genopenapi.Path).Get
+			Ω(live.OperationID).Should(Equal("health#live"))
+			ready := doc.Paths["/health/ready"].(*genopenapi.Path).Get
+			Ω(ready.OperationID).Should(Equal("health#ready"))
+			Ω(ready.Responses["503"].Content).Should(HaveKey(ErrorMediaIdentifier))
+		})
+	})
+
+	Context("with problem details", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				ProblemDetails()
+			})
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(GET("/"))
+					Response(OK)
+					Response(BadRequest, ErrorMedia)
+				})
+			})
+		})
+
+		It("describes the error responses as problem details documents", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			act := doc.Paths["/"].(*genopenapi.Path).Get
+			content := act.Responses["400"].Content
+			Ω(content).Should(HaveLen(1))
+			Ω(content).Should(HaveKey(ProblemMediaIdentifier))
+			Ω(content[ProblemMediaIdentifier].Schema.Ref).Should(Equal("#/components/schemas/problem"))
+			Ω(doc.Components.Schemas).Should(HaveKey("problem"))
+			Ω(doc.Components.Schemas).ShouldNot(HaveKey("error"))
+		})
+	})
+
+	Context("with deprecations", func() {
+		BeforeEach(func() {
+			API("test", func() {})
+			Resource("res", func() {
+				Deprecated("use other")
+				Action("act", func() {
+					Routing(GET("/"))
+					Params(func() {
+						Param("p", String)
+						Param("old", String, func() {
+							Deprecated("use p")
+						})
+					})
+					Response(OK)
+				})
+			})
+		})
+
+		It("marks the operations and parameters as deprecated", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			act := doc.Paths["/"].(*genopenapi.Path).Get
+			Ω(act.Deprecated).Should(BeTrue())
+			Ω(act.Parameters).Should(HaveLen(2))
+			for _, p := range act.Parameters {
+				Ω(p.Deprecated).Should(Equal(p.Name == "old"))
+				Ω(p.Schema.Deprecated).Should(Equal(p.Name == "old"))
+			}
+		})
+	})
+
+	Context("with OpenAPI 3.1", func() {
+		BeforeEach(func() {
+			version = genopenapi.Version31
+			API("test", func() {})
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(GET("/"))
+					Params(func() {
+						Param("p", String, func() {
+							Example("example")
+						})
+					})
+					Response(OK)
+				})
+			})
+		})
+
+		It("uses examples rather than example", func() {
+			act := doc.Paths["/"].(*genopenapi.Path).Get
+			Ω(doc.OpenAPI).Should(Equal(genopenapi.Version31))
+			Ω(act.Parameters[0].Schema.Example).Should(BeNil())
+			Ω(act.Parameters[0].Schema.Examples).Should(Equal([]interface{}{"example"}))
+		})
+	})
+})
@@ -0,0 +1,27 @@
+package genopenapi
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//Version The OpenAPI specification version, e.g. "3.0.3" or "3.1.0"
+func Version(version string) Option {
+	return func(g *Generator) {
+		g.Version = version
+	}
+}
@@ -26,6 +26,7 @@ type (
 		Description  string                 `json:"description,omitempty"`
 		DefaultValue interface{}            `json:"default,omitempty"`
 		Example      interface{}            `json:"example,omitempty"`
+		Deprecated   bool                   `json:"deprecated,omitempty"`
 
 		// Hyper schema
 		Media     *JSONMedia  `json:"media,omitempty"`
@@ -44,6 +45,8 @@ type (
 		MaxLength            *int          `json:"maxLength,omitempty"`
 		MinItems             *int          `json:"minItems,omitempty"`
 		MaxItems             *int          `json:"maxItems,omitempty"`
+		MinProperties        *int          `json:"minProperties,omitempty"`
+		MaxProperties        *int          `json:"maxProperties,omitempty"`
 		Required             []string      `json:"required,omitempty"`
 		AdditionalProperties bool          `json:"additionalProperties,omitempty"`
 
@@ -311,6 +314,18 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 	case *design.Hash:
 		s.Type = JSONObject
 		s.AdditionalProperties = true
+	case *design.Union:
+		// A union value is an object with exactly one of the alternatives as key.
+		one := 1
+		s.Type = JSONObject
+		s.MinProperties = &one
+		s.MaxProperties = &one
+		for _, n := range actual.Names() {
+			prop := NewJSONSchema()
+			buildAttributeSchema(api, prop, actual.Alternatives[n])
+			s.Properties[n] = prop
+			s.AnyOf = append(s.AnyOf, &JSONSchema{Required: []string{n}})
+		}
 	case *design.UserTypeDefinition:
 		s.Ref = TypeRef(api, actual)
 	case *design.MediaTypeDefinition:
@@ -339,6 +354,7 @@ func (s *JSONSchema) createMergeItems(other *JSONSchema) mergeItems {
 		{&s.Title, other.Title, s.Title == ""},
 		{&s.Media, other.Media, s.Media == nil},
 		{&s.ReadOnly, other.ReadOnly, s.ReadOnly == false},
+		{&s.Deprecated, other.Deprecated, s.Deprecated == false},
 		{&s.PathStart, other.PathStart, s.PathStart == ""},
 		{&s.Enum, other.Enum, s.Enum == nil},
 		{&s.Format, other.Format, s.Format == ""},
@@ -368,6 +384,15 @@ func (s *JSONSchema) createMergeItems(other *JSONSchema) mergeItems {
 			a: s.MaxItems, b: other.MaxItems,
 			needed: maxInt(s.MaxItems, other.MaxItems),
 		},
+		{
+			a: &s.MinProperties, b: other.MinProperties,
+			needed: minInt(s.MinProperties, other.MinProperties),
+		},
+		{
+			a: &s.MaxProperties, b: other.MaxProperties,
+			needed: maxInt(s.MaxProperties, other.MaxProperties),
+		},
+		{&s.AnyOf, other.AnyOf, s.AnyOf == nil},
 	}
 }
 
@@ -415,6 +440,7 @@ func (s *JSONSchema) Dup() *JSONSchema {
 		Title:                s.Title,
 		Media:                s.Media,
 		ReadOnly:             s.ReadOnly,
+		Deprecated:           s.Deprecated,
 		PathStart:            s.PathStart,
 		Links:                s.Links,
 		Ref:                  s.Ref,
@@ -427,8 +453,11 @@ func (s *JSONSchema) Dup() *JSONSchema {
 		MaxLength:            s.MaxLength,
 		MinItems:             s.MinItems,
 		MaxItems:             s.MaxItems,
+		MinProperties:        s.MinProperties,
+		MaxProperties:        s.MaxProperties,
 		Required:             s.Required,
 		AdditionalProperties: s.AdditionalProperties,
+		AnyOf:                s.AnyOf,
 	}
 	for n, p := range s.Properties {
 		js.Properties[n] = p.Dup()
@@ -442,6 +471,12 @@ func (s *JSONSchema) Dup() *JSONSchema {
 	return &js
 }
 
+// AttributeSchema produces the JSON schema corresponding to the given attribute including its
+// description, default value and validations.
+func AttributeSchema(api *design.APIDefinition, at *design.AttributeDefinition) *JSONSchema {
+	return buildAttributeSchema(api, NewJSONSchema(), at)
+}
+
 // buildAttributeSchema initializes the given JSON schema that corresponds to the given attribute.
 func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.AttributeDefinition) *JSONSchema {
 	if at.View != "" {
@@ -459,6 +494,7 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 	s.Description = at.Description
 	s.Example = at.GenerateExample(api.RandomGenerator(), nil)
 	s.ReadOnly = at.IsReadOnly()
+	s.Deprecated = at.Deprecation != nil
 	val := at.Validation
 	if val == nil {
 		return s
@@ -78,4 +78,46 @@ var _ = Describe("TypeSchema", func() {
 		})
 
 	})
+	Context("with a union", func() {
+		BeforeEach(func() {
+			typ = OneOf(func() {
+				Attribute("email", design.String)
+				Attribute("phone", design.Integer)
+			})
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		It("returns an object schema that requires exactly one property", func() {
+			Ω(s).ShouldNot(BeNil())
+			Ω(s.Type).Should(BeEquivalentTo(genschema.JSONObject))
+			Ω(s.Properties).Should(HaveLen(2))
+			Ω(s.Properties["email"].Type).Should(Equal(genschema.JSONType("string")))
+			Ω(s.Properties["phone"].Type).Should(Equal(genschema.JSONType("integer")))
+			Ω(*s.MinProperties).Should(Equal(1))
+			Ω(*s.MaxProperties).Should(Equal(1))
+			Ω(s.AnyOf).Should(HaveLen(2))
+			Ω(s.AnyOf[0].Required).Should(Equal([]string{"email"}))
+			Ω(s.AnyOf[1].Required).Should(Equal([]string{"phone"}))
+		})
+	})
+	Context("with a deprecated attribute", func() {
+		BeforeEach(func() {
+			Type("Legacy", func() {
+				Attribute("name", design.String)
+				Attribute("nickname", design.String, func() {
+					Deprecated("use name")
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["Legacy"]
+		})
+
+		It("marks the property as deprecated", func() {
+			Ω(s).ShouldNot(BeNil())
+			def := genschema.Definitions["Legacy"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Properties["name"].Deprecated).Should(BeFalse())
+			Ω(def.Properties["nickname"].Deprecated).Should(BeTrue())
+		})
+	})
 })
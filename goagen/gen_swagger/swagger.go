@@ -425,45 +425,105 @@ func New(api *design.APIDefinition) (*Swagger, error) {
 		return nil, err
 	}
 	err = api.IterateResources(func(res *design.ResourceDefinition) error {
-		for k, v := range extensionsFromDefinition(res.Metadata) {
-			s.Paths[k] = v
-		}
-		err := res.IterateFileServers(func(fs *design.FileServerDefinition) error {
-			if !mustGenerate(fs.Metadata) {
-				return nil
-			}
-			return buildPathFromFileServer(s, api, fs)
-		})
-		if err != nil {
-			return err
-		}
-		return res.IterateActions(func(a *design.ActionDefinition) error {
-			if !mustGenerate(a.Metadata) {
-				return nil
-			}
-			for _, route := range a.Routes {
-				if err := buildPathFromDefinition(s, api, route, basePath); err != nil {
-					return err
-				}
-			}
-			return nil
-		})
+		return buildPathsFromResource(s, api, res, basePath)
 	})
 	if err != nil {
 		return nil, err
 	}
+	if api.Health != nil {
+		buildPathsFromHealth(s, api, api.Health, basePath)
+	}
 	if len(genschema.Definitions) > 0 {
 		s.Definitions = make(map[string]*genschema.JSONSchema)
 		for n, d := range genschema.Definitions {
 			// sad but swagger doesn't support these
 			d.Media = nil
 			d.Links = nil
+			removeAnyOf(d)
 			s.Definitions[n] = d
 		}
 	}
+	removePathsAnyOf(s)
 	return s, nil
 }
 
+// buildPathsFromResource adds the operations corresponding to the resource file servers and
+// actions.
+func buildPathsFromResource(s *Swagger, api *design.APIDefinition, res *design.ResourceDefinition, basePath string) error {
+	for k, v := range extensionsFromDefinition(res.Metadata) {
+		s.Paths[k] = v
+	}
+	err := res.IterateFileServers(func(fs *design.FileServerDefinition) error {
+		if !mustGenerate(fs.Metadata) {
+			return nil
+		}
+		return buildPathFromFileServer(s, api, fs)
+	})
+	if err != nil {
+		return err
+	}
+	return res.IterateActions(func(a *design.ActionDefinition) error {
+		if !mustGenerate(a.Metadata) {
+			return nil
+		}
+		for _, route := range a.Routes {
+			if err := buildPathFromDefinition(s, api, route, basePath); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// removeAnyOf removes the "anyOf" keywords recursively as Swagger does not support them. Unions
+// are still described by the alternative properties together with the minProperties and
+// maxProperties validations.
+func removeAnyOf(s *genschema.JSONSchema) {
+	if s == nil {
+		return
+	}
+	s.AnyOf = nil
+	for _, p := range s.Properties {
+		removeAnyOf(p)
+	}
+	for _, d := range s.Definitions {
+		removeAnyOf(d)
+	}
+	removeAnyOf(s.Items)
+}
+
+// removePathsAnyOf removes the "anyOf" keywords of the inline schemas of the parameters and
+// responses, see removeAnyOf.
+func removePathsAnyOf(s *Swagger) {
+	for _, p := range s.Parameters {
+		removeAnyOf(p.Schema)
+	}
+	for _, r := range s.Responses {
+		removeAnyOf(r.Schema)
+	}
+	for _, v := range s.Paths {
+		path, ok := v.(*Path)
+		if !ok {
+			continue
+		}
+		for _, p := range path.Parameters {
+			removeAnyOf(p.Schema)
+		}
+		ops := []*Operation{path.Get, path.Put, path.Post, path.Delete, path.Options, path.Head, path.Patch}
+		for _, op := range ops {
+			if op == nil {
+				continue
+			}
+			for _, p := range op.Parameters {
+				removeAnyOf(p.Schema)
+			}
+			for _, r := range op.Responses {
+				removeAnyOf(r.Schema)
+			}
+		}
+	}
+}
+
 // mustGenerate returns true if the metadata indicates that a Swagger specification should be
 // generated, false otherwise.
 func mustGenerate(meta dslengine.MetadataDefinition) bool {
@@ -476,9 +536,17 @@ func mustGenerate(meta dslengine.MetadataDefinition) bool {
 }
 
 // hasAbsoluteRoutes returns true if any action exposed by the API uses an absolute route of if the
-// API has file servers. This is needed as Swagger does not support exceptions to the base path so
-// if the API has any absolute route the base path must be "/" and all routes must be absolutes.
+// API has file servers or health check endpoints outside of the base path. This is needed as
+// Swagger does not support exceptions to the base path so if the API has any absolute route the
+// base path must be "/" and all routes must be absolutes.
 func hasAbsoluteRoutes(api *design.APIDefinition) bool {
+	if h := api.Health; h != nil && api.BasePath != "" {
+		for _, p := range []string{h.LivePath, h.ReadyPath} {
+			if p != api.BasePath && !strings.HasPrefix(p, strings.TrimSuffix(api.BasePath, "/")+"/") {
+				return true
+			}
+		}
+	}
 	hasAbsoluteRoutes := false
 	for _, res := range api.Resources {
 		for _, fs := range res.FileServers {
@@ -782,6 +850,9 @@ func responseSpecFromDefinition(s *Swagger, api *design.APIDefinition, r *design
 			if view == "" {
 				view = design.DefaultView
 			}
+			if mt.IsError() {
+				mt = api.ErrorResponseMedia()
+			}
 			schema = genschema.NewJSONSchema()
 			schema.Ref = genschema.MediaTypeRef(api, mt, view)
 		}
@@ -905,6 +976,61 @@ func buildPathFromFileServer(s *Swagger, api *design.APIDefinition, fs *design.F
 	return nil
 }
 
+// rateLimitResponse returns the response sent by the rate limit middleware to requests that
+// exceed the limit.
+func rateLimitResponse(api *design.APIDefinition) *Response {
+	return &Response{
+		Description: "Too Many Requests",
+		Schema:      genschema.TypeSchema(api, api.ErrorResponseMedia()),
+		Headers: map[string]*Header{
+			"Retry-After":         {Description: "Number of seconds after which the request may be retried", Type: "integer"},
+			"RateLimit-Limit":     {Description: "Maximum number of requests allowed during the period", Type: "integer"},
+			"RateLimit-Remaining": {Description: "Number of requests left", Type: "integer"},
+			"RateLimit-Reset":     {Description: "Number of seconds until the limit is fully reset", Type: "integer"},
+		},
+	}
+}
+
+// buildPathsFromHealth adds the operations corresponding to the liveness and readiness
+// endpoints mounted by the health package. The paths are relative to basePath.
+func buildPathsFromHealth(s *Swagger, api *design.APIDefinition, h *design.HealthDefinition, basePath string) {
+	probes := []struct{ name, path, summary string }{
+		{"live", h.LivePath, "Liveness probe"},
+		{"ready", h.ReadyPath, "Readiness probe"},
+	}
+	for _, probe := range probes {
+		report := genschema.NewJSONSchema()
+		report.Type = genschema.JSONObject
+		report.Properties["status"] = &genschema.JSONSchema{Type: genschema.JSONString}
+		report.Properties["checks"] = &genschema.JSONSchema{Type: genschema.JSONObject, AdditionalProperties: true}
+		responses := map[string]*Response{
+			"200": {Description: "Healthy", Schema: report},
+			"503": {Description: "Unhealthy", Schema: genschema.TypeSchema(api, api.ErrorResponseMedia())},
+		}
+		operation := &Operation{
+			Tags:        []string{"health"},
+			Summary:     probe.summary,
+			OperationID: "health#" + probe.name,
+			Produces:    []string{"application/json", api.ErrorResponseMedia().Identifier},
+			Responses:   responses,
+			Schemes:     api.Schemes,
+		}
+		key := probe.path
+		if basePath != "/" {
+			key = strings.TrimPrefix(key, basePath)
+		}
+		if key == "" {
+			key = "/"
+		}
+		path, ok := s.Paths[key].(*Path)
+		if !ok {
+			path = new(Path)
+			s.Paths[key] = path
+		}
+		path.Get = operation
+	}
+}
+
 func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *design.RouteDefinition, basePath string) error {
 	action := route.Parent
 
@@ -928,6 +1054,9 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		}
 		responses[strconv.Itoa(r.Status)] = resp
 	}
+	if _, ok := responses["429"]; !ok && action.EffectiveRateLimit() != nil {
+		responses["429"] = rateLimitResponse(api)
+	}
 
 	consumesMultipart := false
 	if action.Payload != nil {
@@ -977,25 +1106,43 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		Parameters:   params,
 		Responses:    responses,
 		Schemes:      schemes,
-		Deprecated:   false,
+		Deprecated:   action.EffectiveDeprecation() != nil,
 		Extensions:   extensionsFromDefinition(route.Metadata),
 	}
 
 	if consumesMultipart {
 		operation.Consumes = append(operation.Consumes, "multipart/form-data")
+	} else if action.OverridesConsumes() {
+		for _, enc := range action.EffectiveConsumes() {
+			operation.Consumes = append(operation.Consumes, enc.MIMETypes...)
+		}
 	}
 
-	computeProduces(operation, s, action)
+	computeProduces(operation, s, api, action)
 	applySecurity(operation, action.Security)
 
 	computePaths(operation, s, route, basePath)
 	return nil
 }
 
-func computeProduces(operation *Operation, s *Swagger, action *design.ActionDefinition) {
+// computeProduces lists the media types of the action responses in the operation produces unless
+// they are all produced by the API. The list also includes the media types the action or its
+// resource produce if they override the API produces.
+func computeProduces(operation *Operation, s *Swagger, api *design.APIDefinition, action *design.ActionDefinition) {
 	produces := make(map[string]struct{})
+	apiProduces := s.Produces
+	if action.OverridesProduces() {
+		apiProduces = nil
+		for _, enc := range action.EffectiveProduces() {
+			for _, mt := range enc.MIMETypes {
+				produces[mt] = struct{}{}
+			}
+		}
+	}
 	action.IterateResponses(func(resp *design.ResponseDefinition) error {
-		if resp.MediaType != "" {
+		if resp.MediaType == design.ErrorMediaIdentifier {
+			produces[api.ErrorResponseMedia().Identifier] = struct{}{}
+		} else if resp.MediaType != "" {
 			produces[resp.MediaType] = struct{}{}
 		}
 		return nil
@@ -1003,7 +1150,7 @@ func computeProduces(operation *Operation, s *Swagger, action *design.ActionDefi
 	subset := true
 	for p := range produces {
 		found := false
-		for _, p2 := range s.Produces {
+		for _, p2 := range apiProduces {
 			if p == p2 {
 				found = true
 				break
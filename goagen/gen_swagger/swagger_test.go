@@ -3,6 +3,7 @@ package genswagger_test
 import (
 	"bytes"
 	"encoding/json"
+	"time"
 
 	"github.com/go-openapi/loads"
 	_ "github.com/goadesign/goa-cellar/design"
@@ -258,6 +259,33 @@ var _ = Describe("New", func() {
 
 		})
 
+		Context("with an inline union payload", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							PUT("/"),
+						)
+						Payload(func() {
+							Attribute("channel", OneOf(func() {
+								Attribute("email", String)
+								Attribute("sms", String)
+							}))
+						})
+					})
+				})
+			})
+
+			It("removes the anyOf keywords unsupported by Swagger", func() {
+				b, err := json.Marshal(swagger)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(b)).ShouldNot(ContainSubstring(`"anyOf"`))
+				Ω(string(b)).Should(ContainSubstring(`"minProperties":1`))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
 		Context("with optional payload", func() {
 			BeforeEach(func() {
 				p := Type("OptionalPayload", func() {
@@ -281,6 +309,112 @@ var _ = Describe("New", func() {
 
 		})
 
+		Context("with deprecations", func() {
+			BeforeEach(func() {
+				p := Type("LegacyPayload", func() {
+					Member("m1", String)
+					Member("m2", String, func() {
+						Deprecated("use m1")
+					})
+				})
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							PUT("/"),
+						)
+						Deprecated("use other", "2020-06-30")
+						Payload(p)
+					})
+				})
+			})
+
+			It("marks the operation and properties as deprecated", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				a := swagger.Paths["/"].(*genswagger.Path)
+				Ω(a.Put).ShouldNot(BeNil())
+				Ω(a.Put.Deprecated).Should(BeTrue())
+				def := swagger.Definitions["LegacyPayload"]
+				Ω(def).ShouldNot(BeNil())
+				Ω(def.Properties["m1"].Deprecated).Should(BeFalse())
+				Ω(def.Properties["m2"].Deprecated).Should(BeTrue())
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
+		Context("with action level encodings", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Produces("application/xml")
+					Action("act", func() {
+						Routing(
+							PUT("/"),
+						)
+						Consumes("application/x-msgpack")
+						Payload(String)
+						Response(NoContent)
+					})
+				})
+			})
+
+			It("lists the operation consumes and produces", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				a := swagger.Paths["/"].(*genswagger.Path)
+				Ω(a.Put.Consumes).Should(Equal([]string{"application/x-msgpack"}))
+				Ω(a.Put.Produces).Should(Equal([]string{"application/xml"}))
+				Ω(swagger.Consumes).Should(HaveLen(4))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
+		Context("with a rate limit", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					RateLimit(100, time.Minute, ByHeader("X-API-Key"))
+					Action("act", func() {
+						Routing(
+							GET("/"),
+						)
+						Response(NoContent)
+					})
+				})
+			})
+
+			It("documents the too many requests response", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				a := swagger.Paths["/"].(*genswagger.Path)
+				Ω(a.Get.Responses).Should(HaveKey("429"))
+				Ω(a.Get.Responses["429"].Headers).Should(HaveKey("Retry-After"))
+				Ω(a.Get.Responses["429"].Headers).Should(HaveKey("RateLimit-Remaining"))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
+		Context("with cache control", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					CacheControl(time.Minute, "public")
+					Action("act", func() {
+						Routing(
+							GET("/"),
+						)
+						Response(OK)
+					})
+				})
+			})
+
+			It("documents the Cache-Control header", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				a := swagger.Paths["/"].(*genswagger.Path)
+				Ω(a.Get.Responses["200"].Headers).Should(HaveKey("Cache-Control"))
+				Ω(a.Get.Responses["200"].Headers["Cache-Control"].Description).Should(ContainSubstring("max-age=60, public"))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
 		Context("with multipart/form-data payload", func() {
 			BeforeEach(func() {
 				f := Type("MultipartPayload", func() {
@@ -824,4 +958,89 @@ var _ = Describe("New", func() {
 
 		})
 	})
+
+	Context("with health checks", func() {
+		var apiDSL func()
+
+		BeforeEach(func() {
+			apiDSL = func() {
+				HealthChecks()
+			}
+			API("test", func() {
+				apiDSL()
+			})
+		})
+
+		It("describes the liveness and readiness endpoints", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			for path, id := range map[string]string{"/livez": "health#live", "/readyz": "health#ready"} {
+				p, ok := swagger.Paths[path].(*genswagger.Path)
+				Ω(ok).Should(BeTrue())
+				Ω(p.Get.OperationID).Should(Equal(id))
+				Ω(p.Get.Responses).Should(HaveKey("200"))
+				Ω(p.Get.Responses).Should(HaveKey("503"))
+			}
+		})
+
+		It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+
+		Context("under the API base path", func() {
+			BeforeEach(func() {
+				apiDSL = func() {
+					BasePath("/api")
+					HealthChecks("/api/livez", "/api/readyz")
+				}
+			})
+
+			It("strips the base path", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				Ω(swagger.BasePath).Should(Equal("/api"))
+				Ω(swagger.Paths).Should(HaveKey("/livez"))
+				Ω(swagger.Paths).Should(HaveKey("/readyz"))
+			})
+		})
+
+		Context("outside of the API base path", func() {
+			BeforeEach(func() {
+				apiDSL = func() {
+					BasePath("/api")
+					HealthChecks()
+				}
+			})
+
+			It("uses absolute paths", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				Ω(swagger.BasePath).Should(BeEmpty())
+				Ω(swagger.Paths).Should(HaveKey("/livez"))
+				Ω(swagger.Paths).Should(HaveKey("/readyz"))
+			})
+		})
+	})
+
+	Context("with problem details", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				ProblemDetails()
+			})
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(
+						GET("/"),
+					)
+					Response(OK, "application/json")
+					Response(BadRequest, ErrorMedia)
+				})
+			})
+		})
+
+		It("declares the problem details error responses", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			a := swagger.Paths["/"].(*genswagger.Path)
+			Ω(a.Get.Produces).Should(ContainElement(ProblemMediaIdentifier))
+			Ω(a.Get.Responses["400"].Schema.Ref).Should(Equal("#/definitions/problem"))
+			Ω(swagger.Definitions).Should(HaveKey("problem"))
+		})
+
+		It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+	})
 })
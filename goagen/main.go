@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/goadesign/goa/goagen/codegen"
+	genimport "github.com/goadesign/goa/goagen/gen_import"
 	"github.com/goadesign/goa/goagen/meta"
 	"github.com/goadesign/goa/goagen/utils"
 	"github.com/goadesign/goa/version"
@@ -110,6 +111,59 @@ package and tool and the Swagger specification for the API.
 	}
 	rootCmd.AddCommand(swaggerCmd)
 
+	// openapiCmd implements the "openapi" command.
+	var oas string
+	openapiCmd := &cobra.Command{
+		Use:   "openapi",
+		Short: "Generate OpenAPI 3 specification",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genopenapi", c) },
+	}
+	openapiCmd.Flags().StringVar(&oas, "oas", "3.0.3", `the OpenAPI specification version, either 3.0.x or 3.1.x`)
+	rootCmd.AddCommand(openapiCmd)
+
+	// diffCmd implements the "diff" command.
+	var (
+		basePkg, rev string
+		diffJSON     bool
+	)
+	diffCmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Report breaking changes between two versions of a design",
+		Long: `The diff command compares the design package given with --design with either the design
+package given with --base or the same design package at the git revision given with --rev.
+It reports removed actions or routes, new required request attributes, narrowed enums or
+validations, removed response codes and changed types and exits with a non-zero status if
+any breaking change is found. In module mode --rev checks out the revision in a temporary git
+worktree and requires the design package to belong to the main module.`,
+		Run: func(c *cobra.Command, _ []string) { err = runDiff(designPkg, basePkg, rev, diffJSON, debug) },
+	}
+	diffCmd.Flags().StringVar(&basePkg, "base", "", "base design package `import path`")
+	diffCmd.Flags().StringVar(&rev, "rev", "", "git `revision` of the design package to compare with")
+	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "print the report in JSON")
+	rootCmd.AddCommand(diffCmd)
+
+	// importCmd implements the "import" command.
+	var (
+		specFile, designTarget string
+		importForce            bool
+	)
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Generate design package from Swagger or OpenAPI specification",
+		Run: func(c *cobra.Command, _ []string) {
+			files, err = genimport.NewGenerator(
+				genimport.Spec(specFile),
+				genimport.OutDir(c.Flag("out").Value.String()),
+				genimport.Target(designTarget),
+				genimport.Force(importForce),
+			).Generate()
+		},
+	}
+	importCmd.Flags().StringVar(&specFile, "spec", "", "path to the Swagger 2.0 or OpenAPI 3 specification `file` (JSON or YAML)")
+	importCmd.Flags().StringVar(&designTarget, "pkg", "design", "Name of generated design Go package")
+	importCmd.Flags().BoolVar(&importForce, "force", false, "overwrite existing files")
+	rootCmd.AddCommand(importCmd)
+
 	// jsCmd implements the "js" command.
 	var (
 		timeout      = time.Duration(20) * time.Second
@@ -355,7 +409,7 @@ func flagJSON(fl *pflag.Flag) *flag {
 	switch fl.Name {
 	case "out":
 		f.Argument = "$DIR"
-	case "design":
+	case "design", "base":
 		f.Argument = "$DESIGN_PKG"
 	case "pkg-path":
 		f.Argument = "$PKG"
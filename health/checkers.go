@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/goadesign/goa/client"
+)
+
+type (
+	// Pinger is the interface implemented by resources that can be pinged such as *sql.DB.
+	Pinger interface {
+		// PingContext verifies the resource is reachable.
+		PingContext(context.Context) error
+	}
+
+	// checker implements Checker using a function.
+	checker struct {
+		name string
+		fn   func(context.Context) error
+	}
+)
+
+// NewChecker returns a checker with the given name that runs fn.
+func NewChecker(name string, fn func(context.Context) error) Checker {
+	return &checker{name: name, fn: fn}
+}
+
+// Ping returns a checker that pings the given resource, for example a database connection pool.
+func Ping(name string, p Pinger) Checker {
+	return NewChecker(name, p.PingContext)
+}
+
+// HTTP returns a checker that sends GET requests to the given URL using doer. The check fails if
+// the request fails or if the response status is 400 or greater.
+func HTTP(name string, doer client.Doer, url string) Checker {
+	return NewChecker(name, func(ctx context.Context) error {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := doer.Do(ctx, req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(ioutil.Discard, resp.Body)
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("unexpected response status %s", resp.Status)
+		}
+		return nil
+	})
+}
+
+// Name returns the name of the check.
+func (c *checker) Name() string {
+	return c.name
+}
+
+// Check runs the check function.
+func (c *checker) Check(ctx context.Context) error {
+	return c.fn(ctx)
+}
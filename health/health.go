@@ -0,0 +1,235 @@
+/*
+Package health exposes liveness and readiness endpoints on a goa service.
+
+The liveness endpoint reports whether the process is able to serve requests at all, orchestrators
+typically restart the process when it fails. The readiness endpoint reports whether the service
+should receive traffic, it fails while the service is starting or shutting down (see
+goa.Service.Ready) or when one of the dependencies the service relies on is unavailable.
+
+Each endpoint aggregates the results of the checkers registered with it:
+
+	h := health.New()
+	h.AddReadinessCheck(health.Ping("db", db), time.Second)
+	h.AddReadinessCheck(health.HTTP("auth", goaclient.HTTPClientDoer(http.DefaultClient), authURL), 0)
+	h.Mount(service)
+
+Healthy endpoints respond with a 200 status and a JSON report listing the checks. Unhealthy
+endpoints respond with a 503 status and an error response whose metadata lists the failed checks.
+*/
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+const (
+	// DefaultLivePath is the default path of the liveness endpoint.
+	DefaultLivePath = "/livez"
+
+	// DefaultReadyPath is the default path of the readiness endpoint.
+	DefaultReadyPath = "/readyz"
+
+	// DefaultTimeout is the default maximum duration of a check.
+	DefaultTimeout = 5 * time.Second
+)
+
+// ErrUnhealthy is the class of errors returned by unhealthy endpoints.
+var ErrUnhealthy = goa.NewErrorClass("unhealthy", 503)
+
+type (
+	// Checker is the interface implemented by health checks.
+	Checker interface {
+		// Name identifies the check in the reports.
+		Name() string
+		// Check returns a non nil error if the checked resource is unhealthy.
+		Check(context.Context) error
+	}
+
+	// Health aggregates the liveness and readiness checks of a service.
+	Health struct {
+		// LivePath is the path of the liveness endpoint, defaults to DefaultLivePath.
+		LivePath string
+		// ReadyPath is the path of the readiness endpoint, defaults to DefaultReadyPath.
+		ReadyPath string
+		// Timeout is the maximum duration of checks registered without an explicit
+		// timeout, defaults to DefaultTimeout.
+		Timeout time.Duration
+		// TTL is the duration during which check results are cached. Results are not
+		// cached if TTL is 0.
+		TTL time.Duration
+
+		mu    sync.Mutex
+		live  []*check
+		ready []*check
+	}
+
+	// Report is the response body of healthy endpoints.
+	Report struct {
+		// Status is "ok" for healthy endpoints.
+		Status string `json:"status" xml:"status" form:"status"`
+		// Checks lists the status of each check indexed by check name.
+		Checks map[string]string `json:"checks,omitempty" xml:"-" form:"checks,omitempty"`
+	}
+
+	// check wraps a checker and caches its results.
+	check struct {
+		Checker
+		timeout time.Duration
+
+		mu        sync.Mutex
+		checkedAt time.Time
+		err       error
+	}
+)
+
+// New returns a Health with the default paths and timeout.
+func New() *Health {
+	return &Health{
+		LivePath:  DefaultLivePath,
+		ReadyPath: DefaultReadyPath,
+		Timeout:   DefaultTimeout,
+	}
+}
+
+// AddLivenessCheck registers a check with the liveness endpoint. timeout is the maximum
+// duration of the check, Timeout is used if it is 0.
+func (h *Health) AddLivenessCheck(c Checker, timeout time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.live = append(h.live, &check{Checker: c, timeout: timeout})
+}
+
+// AddReadinessCheck registers a check with the readiness endpoint. timeout is the maximum
+// duration of the check, Timeout is used if it is 0.
+func (h *Health) AddReadinessCheck(c Checker, timeout time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ready = append(h.ready, &check{Checker: c, timeout: timeout})
+}
+
+// Mount mounts the liveness and readiness endpoints on the service mux.
+func (h *Health) Mount(service *goa.Service) {
+	livePath, readyPath := h.LivePath, h.ReadyPath
+	if livePath == "" {
+		livePath = DefaultLivePath
+	}
+	if readyPath == "" {
+		readyPath = DefaultReadyPath
+	}
+	ctrl := service.NewController("health")
+	goa.HandleRoute(service.Mux, "GET", livePath, &goa.Route{Controller: ctrl.Name, Action: "live"},
+		ctrl.MuxHandler("live", h.handler(service, false), nil))
+	service.LogInfo("mount", "ctrl", "health", "action", "live", "route", "GET "+livePath)
+	goa.HandleRoute(service.Mux, "GET", readyPath, &goa.Route{Controller: ctrl.Name, Action: "ready"},
+		ctrl.MuxHandler("ready", h.handler(service, true), nil))
+	service.LogInfo("mount", "ctrl", "health", "action", "ready", "route", "GET "+readyPath)
+}
+
+// Live runs the liveness checks and returns the errors of the failed checks indexed by name.
+func (h *Health) Live(ctx context.Context) map[string]error {
+	return h.run(ctx, h.checks(false))
+}
+
+// Ready runs the readiness checks and returns the errors of the failed checks indexed by name.
+func (h *Health) Ready(ctx context.Context) map[string]error {
+	return h.run(ctx, h.checks(true))
+}
+
+// handler returns the handler of the liveness or readiness endpoint.
+func (h *Health) handler(service *goa.Service, ready bool) goa.Handler {
+	kind := "liveness"
+	if ready {
+		kind = "readiness"
+	}
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		checks := h.checks(ready)
+		failed := h.run(ctx, checks)
+		if ready && !service.Ready() {
+			failed["service"] = fmt.Errorf("service is not ready")
+		}
+		if len(failed) == 0 {
+			report := &Report{Status: "ok"}
+			if len(checks) > 0 {
+				report.Checks = make(map[string]string, len(checks))
+				for _, c := range checks {
+					report.Checks[c.Name()] = "ok"
+				}
+			}
+			return service.Send(ctx, http.StatusOK, report)
+		}
+		keyvals := make([]interface{}, 0, 2*len(failed))
+		for n, err := range failed {
+			keyvals = append(keyvals, n, err.Error())
+		}
+		err := ErrUnhealthy(fmt.Sprintf("%s checks failed", kind), keyvals...)
+		goa.ContextResponse(ctx).ErrorCode = err.(goa.ServiceError).Token()
+		rw.Header().Set("Content-Type", goa.ErrorMediaIdentifier)
+		return service.Send(ctx, http.StatusServiceUnavailable, err)
+	}
+}
+
+// checks returns the registered readiness or liveness checks.
+func (h *Health) checks(ready bool) []*check {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if ready {
+		return h.ready
+	}
+	return h.live
+}
+
+// run runs the given checks concurrently and returns the errors of the failed checks.
+func (h *Health) run(ctx context.Context, checks []*check) map[string]error {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed = make(map[string]error)
+	)
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c *check) {
+			defer wg.Done()
+			if err := c.run(ctx, h.Timeout, h.TTL); err != nil {
+				mu.Lock()
+				failed[c.Name()] = err
+				mu.Unlock()
+			}
+		}(c)
+	}
+	wg.Wait()
+	return failed
+}
+
+// run runs the check unless a result younger than ttl is cached. The check fails if it does not
+// complete within its timeout or defaultTimeout if it has none.
+func (c *check) run(ctx context.Context, defaultTimeout, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ttl > 0 && !c.checkedAt.IsZero() && time.Since(c.checkedAt) < ttl {
+		return c.err
+	}
+	timeout := c.timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- c.Check(ctx) }()
+	select {
+	case c.err = <-done:
+	case <-ctx.Done():
+		c.err = fmt.Errorf("check timed out after %s", timeout)
+	}
+	c.checkedAt = time.Now()
+	return c.err
+}
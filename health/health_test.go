@@ -0,0 +1,174 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/client"
+	"github.com/goadesign/goa/health"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Health", func() {
+	var service *goa.Service
+	var h *health.Health
+	var dbErr error
+	var pings int32
+
+	get := func(path string) (int, *goa.ErrorResponse, *health.Report) {
+		rw := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", path, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		service.Mux.ServeHTTP(rw, req)
+		if rw.Code == http.StatusOK {
+			var report health.Report
+			Ω(json.Unmarshal(rw.Body.Bytes(), &report)).ShouldNot(HaveOccurred())
+			return rw.Code, nil, &report
+		}
+		Ω(rw.Header().Get("Content-Type")).Should(Equal(goa.ErrorMediaIdentifier))
+		var resp goa.ErrorResponse
+		Ω(json.Unmarshal(rw.Body.Bytes(), &resp)).ShouldNot(HaveOccurred())
+		return rw.Code, &resp, nil
+	}
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.WithLogger(nil)
+		service.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		dbErr = nil
+		pings = 0
+		h = health.New()
+		h.AddLivenessCheck(health.NewChecker("loop", func(context.Context) error { return nil }), 0)
+		h.AddReadinessCheck(health.NewChecker("db", func(context.Context) error {
+			atomic.AddInt32(&pings, 1)
+			return dbErr
+		}), 0)
+	})
+
+	JustBeforeEach(func() {
+		h.Mount(service)
+	})
+
+	It("reports liveness", func() {
+		code, _, report := get("/livez")
+		Ω(code).Should(Equal(http.StatusOK))
+		Ω(report).Should(Equal(&health.Report{Status: "ok", Checks: map[string]string{"loop": "ok"}}))
+	})
+
+	It("reports readiness of services not started with ListenAndServe", func() {
+		code, _, report := get("/readyz")
+		Ω(code).Should(Equal(http.StatusOK))
+		Ω(report.Checks).Should(Equal(map[string]string{"db": "ok"}))
+	})
+
+	It("is not ready once the service shuts down", func() {
+		Ω(service.Shutdown(context.Background())).ShouldNot(HaveOccurred())
+		code, resp, _ := get("/readyz")
+		Ω(code).Should(Equal(http.StatusServiceUnavailable))
+		Ω(resp.Code).Should(Equal("unhealthy"))
+		Ω(resp.Meta).Should(HaveKeyWithValue("service", "service is not ready"))
+	})
+
+	Context("with a started service", func() {
+		var l net.Listener
+
+		JustBeforeEach(func() {
+			var err error
+			l, err = net.Listen("tcp", "127.0.0.1:0")
+			Ω(err).ShouldNot(HaveOccurred())
+			go service.Serve(l)
+			Eventually(service.Ready).Should(BeTrue())
+		})
+
+		AfterEach(func() {
+			service.Shutdown(context.Background())
+		})
+
+		It("reports readiness", func() {
+			code, _, report := get("/readyz")
+			Ω(code).Should(Equal(http.StatusOK))
+			Ω(report.Checks).Should(Equal(map[string]string{"db": "ok"}))
+		})
+
+		It("reports failed checks", func() {
+			dbErr = errors.New("connection refused")
+			code, resp, _ := get("/readyz")
+			Ω(code).Should(Equal(http.StatusServiceUnavailable))
+			Ω(resp.Status).Should(Equal(http.StatusServiceUnavailable))
+			Ω(resp.Detail).Should(Equal("readiness checks failed"))
+			Ω(resp.Meta).Should(Equal(map[string]interface{}{"db": "connection refused"}))
+		})
+
+		Context("with a slow check", func() {
+			BeforeEach(func() {
+				h.AddReadinessCheck(health.NewChecker("slow", func(context.Context) error {
+					time.Sleep(time.Second)
+					return nil
+				}), 10*time.Millisecond)
+			})
+
+			It("times out", func() {
+				code, resp, _ := get("/readyz")
+				Ω(code).Should(Equal(http.StatusServiceUnavailable))
+				Ω(resp.Meta).Should(Equal(map[string]interface{}{"slow": "check timed out after 10ms"}))
+			})
+		})
+
+		Context("with a TTL", func() {
+			BeforeEach(func() {
+				h.TTL = time.Minute
+			})
+
+			It("caches the results", func() {
+				get("/readyz")
+				dbErr = errors.New("connection refused")
+				code, _, _ := get("/readyz")
+				Ω(code).Should(Equal(http.StatusOK))
+				Ω(atomic.LoadInt32(&pings)).Should(Equal(int32(1)))
+			})
+		})
+	})
+
+	Context("with custom paths", func() {
+		BeforeEach(func() {
+			h.LivePath = "/health/live"
+		})
+
+		It("mounts the endpoints on the custom paths", func() {
+			code, _, _ := get("/health/live")
+			Ω(code).Should(Equal(http.StatusOK))
+		})
+	})
+})
+
+var _ = Describe("HTTP", func() {
+	var status int
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(status)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("checks the downstream dependency", func() {
+		c := health.HTTP("auth", client.HTTPClientDoer(http.DefaultClient), server.URL)
+		Ω(c.Name()).Should(Equal("auth"))
+		status = http.StatusOK
+		Ω(c.Check(context.Background())).ShouldNot(HaveOccurred())
+		status = http.StatusBadGateway
+		Ω(c.Check(context.Background())).Should(MatchError("unexpected response status 502 Bad Gateway"))
+	})
+})
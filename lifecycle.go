@@ -0,0 +1,184 @@
+package goa
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+type (
+	// Hook is a function invoked when the service starts or shuts down. Hooks make it possible
+	// to open and release resources such as database connection pools together with the service.
+	Hook func(context.Context) error
+
+	// lifecycle holds the state used to start and gracefully shutdown a service.
+	lifecycle struct {
+		mu            sync.Mutex
+		startHooks    []Hook
+		shutdownHooks []Hook
+		state         int32
+		shutdown      *shutdownState
+	}
+
+	// shutdownState records the progress of a service shutdown.
+	shutdownState struct {
+		done chan struct{}
+		err  error
+	}
+)
+
+// DefaultShutdownHookTimeout is the maximum duration of the shutdown hooks of services that do not
+// set ShutdownHookTimeout.
+const DefaultShutdownHookTimeout = 10 * time.Second
+
+const (
+	// lifecycleReady is the state of services that serve requests, including services that are
+	// not started with Serve or ListenAndServe, e.g. mounted using their Mux.
+	lifecycleReady int32 = iota
+	// lifecycleStarting is the state of services running their start hooks.
+	lifecycleStarting
+	// lifecycleStopped is the state of services shutting down or whose server has stopped.
+	lifecycleStopped
+)
+
+// OnStart registers a hook invoked prior to the service accepting connections. The hooks run in
+// the order they are registered. The service does not start if a hook returns an error.
+func (service *Service) OnStart(h Hook) {
+	service.lifecycle.mu.Lock()
+	defer service.lifecycle.mu.Unlock()
+	service.lifecycle.startHooks = append(service.lifecycle.startHooks, h)
+}
+
+// OnShutdown registers a hook invoked once the service has finished draining in-flight requests.
+// The hooks run in the reverse order of registration, all hooks run even if some return an
+// error.
+func (service *Service) OnShutdown(h Hook) {
+	service.lifecycle.mu.Lock()
+	defer service.lifecycle.mu.Unlock()
+	service.lifecycle.shutdownHooks = append(service.lifecycle.shutdownHooks, h)
+}
+
+// Ready returns false while the start hooks run and once the service shuts down or its server
+// stops, true otherwise. Services whose handler is served without the Serve, ListenAndServe or
+// ListenAndServeTLS methods, e.g. through their Mux, are thus ready until Shutdown is called.
+// Ready is typically used by readiness probes to signal load balancers whether the service should
+// receive traffic.
+func (service *Service) Ready() bool {
+	return atomic.LoadInt32(&service.lifecycle.state) == lifecycleReady
+}
+
+// Shutdown gracefully shuts down the service. Shutdown first flips the readiness flag, waits for
+// ShutdownDelay then stops accepting new connections and waits for the in-flight requests to
+// complete. The requests still running when ctx is done are canceled via the service context and
+// their connections closed. Shutdown finally runs the hooks registered with OnShutdown with a
+// context that expires after ShutdownHookTimeout, independently of ctx.
+//
+// The ListenAndServe, ListenAndServeTLS and Serve methods return once Shutdown completes. Calling
+// Shutdown more than once waits for the first shutdown to complete and returns its error.
+func (service *Service) Shutdown(ctx context.Context) error {
+	service.lifecycle.mu.Lock()
+	state := service.lifecycle.shutdown
+	if state != nil {
+		service.lifecycle.mu.Unlock()
+		<-state.done
+		return state.err
+	}
+	state = &shutdownState{done: make(chan struct{})}
+	service.lifecycle.shutdown = state
+	hooks := service.lifecycle.shutdownHooks
+	service.lifecycle.mu.Unlock()
+
+	defer close(state.done)
+	atomic.StoreInt32(&service.lifecycle.state, lifecycleStopped)
+	service.LogInfo("shutdown", "status", "draining")
+	if service.ShutdownDelay > 0 {
+		select {
+		case <-time.After(service.ShutdownDelay):
+		case <-ctx.Done():
+		}
+	}
+	if err := service.Server.Shutdown(ctx); err != nil {
+		state.err = err
+		service.Server.Close()
+	}
+	service.CancelAll()
+	timeout := service.ShutdownHookTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownHookTimeout
+	}
+	hctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](hctx); err != nil {
+			service.LogError("shutdown hook", "err", err)
+			if state.err == nil {
+				state.err = err
+			}
+		}
+	}
+	service.LogInfo("shutdown", "status", "done")
+	return state.err
+}
+
+// ShutdownOnSignal shuts down the service gracefully when the process receives one of the given
+// signals, SIGINT and SIGTERM by default. timeout is the maximum duration given to the service to
+// drain in-flight requests, the shutdown hooks then run for at most ShutdownHookTimeout.
+func (service *Service) ShutdownOnSignal(timeout time.Duration, sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sigs...)
+	go func() {
+		sig := <-c
+		signal.Stop(c)
+		service.LogInfo("shutdown", "signal", sig.String())
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := service.Shutdown(ctx); err != nil {
+			service.LogError("shutdown", "err", err)
+		}
+	}()
+}
+
+// start runs the start hooks and flags the service as ready.
+func (service *Service) start() error {
+	service.lifecycle.mu.Lock()
+	hooks := service.lifecycle.startHooks
+	service.lifecycle.mu.Unlock()
+	atomic.StoreInt32(&service.lifecycle.state, lifecycleStarting)
+	for _, h := range hooks {
+		if err := h(service.Context); err != nil {
+			atomic.StoreInt32(&service.lifecycle.state, lifecycleStopped)
+			return err
+		}
+	}
+	atomic.StoreInt32(&service.lifecycle.state, lifecycleReady)
+	return nil
+}
+
+// serve runs the start hooks, invokes run and waits for the service shutdown to complete if run
+// returns because of it.
+func (service *Service) serve(run func() error) error {
+	if err := service.start(); err != nil {
+		return err
+	}
+	err := run()
+	atomic.StoreInt32(&service.lifecycle.state, lifecycleStopped)
+	if err != http.ErrServerClosed {
+		return err
+	}
+	service.lifecycle.mu.Lock()
+	state := service.lifecycle.shutdown
+	service.lifecycle.mu.Unlock()
+	if state == nil {
+		return err
+	}
+	<-state.done
+	return state.err
+}
@@ -0,0 +1,123 @@
+package goa_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Service lifecycle", func() {
+	var s *goa.Service
+	var l net.Listener
+	var calls []string
+	var served chan error
+
+	hook := func(name string, err error) goa.Hook {
+		return func(context.Context) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		s = goa.New("test")
+		s.WithLogger(nil)
+		l, err = net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+		calls = nil
+		served = make(chan error, 1)
+	})
+
+	AfterEach(func() {
+		l.Close()
+	})
+
+	It("is ready when served without Serve", func() {
+		Ω(s.Ready()).Should(BeTrue())
+	})
+
+	Context("with hooks", func() {
+		BeforeEach(func() {
+			readyDuringStart := make(chan bool, 1)
+			s.OnStart(func(context.Context) error {
+				readyDuringStart <- s.Ready()
+				return nil
+			})
+			s.OnStart(hook("start1", nil))
+			s.OnStart(hook("start2", nil))
+			s.OnShutdown(hook("shutdown1", nil))
+			s.OnShutdown(hook("shutdown2", nil))
+			s.Mux.Handle("GET", "/slow", func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+				time.Sleep(100 * time.Millisecond)
+				rw.Write([]byte("done"))
+			})
+			go func() { served <- s.Serve(l) }()
+			Eventually(readyDuringStart).Should(Receive(BeFalse()))
+			Eventually(s.Ready).Should(BeTrue())
+		})
+
+		It("drains in-flight requests and runs the hooks", func() {
+			body := make(chan string, 1)
+			go func() {
+				defer GinkgoRecover()
+				resp, err := http.Get("http://" + l.Addr().String() + "/slow")
+				Ω(err).ShouldNot(HaveOccurred())
+				defer resp.Body.Close()
+				b, _ := ioutil.ReadAll(resp.Body)
+				body <- string(b)
+			}()
+			time.Sleep(20 * time.Millisecond)
+
+			Ω(s.Shutdown(context.Background())).ShouldNot(HaveOccurred())
+			Ω(s.Ready()).Should(BeFalse())
+			Eventually(body).Should(Receive(Equal("done")))
+			Eventually(served).Should(Receive(BeNil()))
+			Ω(calls).Should(Equal([]string{"start1", "start2", "shutdown2", "shutdown1"}))
+		})
+
+		It("runs the hooks with their own deadline once draining times out", func() {
+			hookErr := make(chan error, 1)
+			s.ShutdownHookTimeout = time.Second
+			s.OnShutdown(func(ctx context.Context) error {
+				_, ok := ctx.Deadline()
+				Ω(ok).Should(BeTrue())
+				hookErr <- ctx.Err()
+				return nil
+			})
+			go http.Get("http://" + l.Addr().String() + "/slow")
+			time.Sleep(20 * time.Millisecond)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			Ω(s.Shutdown(ctx)).Should(HaveOccurred())
+			Ω(hookErr).Should(Receive(BeNil()))
+		})
+
+		It("returns the first shutdown error to all callers", func() {
+			s.OnShutdown(hook("failing", errors.New("boom")))
+			Ω(s.Shutdown(context.Background())).Should(MatchError("boom"))
+			Ω(s.Shutdown(context.Background())).Should(MatchError("boom"))
+			Eventually(served).Should(Receive(MatchError("boom")))
+		})
+	})
+
+	Context("with a failing start hook", func() {
+		BeforeEach(func() {
+			s.OnStart(hook("start", errors.New("no db")))
+		})
+
+		It("does not start the service", func() {
+			Ω(s.Serve(l)).Should(MatchError("no db"))
+			Ω(s.Ready()).Should(BeFalse())
+		})
+	})
+})
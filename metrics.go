@@ -3,9 +3,12 @@
 package goa
 
 import (
+	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	metrics "github.com/armon/go-metrics"
@@ -31,14 +34,53 @@ var (
 	validCharactersRE = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_:]*$`)
 )
 
-// Collector is the interface used for collecting metrics.
-type Collector interface {
-	AddSample(key []string, val float32)
-	EmitKey(key []string, val float32)
-	IncrCounter(key []string, val float32)
-	MeasureSince(key []string, start time.Time)
-	SetGauge(key []string, val float32)
-}
+var (
+	// RequestsKey is the key of the counter incremented by the controllers for each request.
+	// The counter is labeled with the controller and action names and the response status
+	// class, e.g. "2xx".
+	RequestsKey = []string{"goa", "http", "requests", "total"}
+
+	// RequestsInFlightKey is the key of the gauge recording the number of requests being
+	// handled by an action. The gauge is labeled with the controller and action names.
+	RequestsInFlightKey = []string{"goa", "http", "requests", "in_flight"}
+
+	// RequestDurationKey is the key of the timing metric recording the duration of the
+	// requests. The metric has the same labels as RequestsKey.
+	RequestDurationKey = []string{"goa", "http", "request", "duration"}
+
+	// ResponseSizeKey is the key of the sample recording the size of the response bodies in
+	// bytes. The sample has the same labels as RequestsKey.
+	ResponseSizeKey = []string{"goa", "http", "response", "size", "bytes"}
+)
+
+type (
+	// Collector is the interface used for collecting metrics.
+	Collector interface {
+		AddSample(key []string, val float32)
+		EmitKey(key []string, val float32)
+		IncrCounter(key []string, val float32)
+		MeasureSince(key []string, start time.Time)
+		SetGauge(key []string, val float32)
+	}
+
+	// LabeledCollector is implemented by the collectors that support labels such as the
+	// go-metrics Metrics type and the collector of the prometheus package. The label values of
+	// the metrics recorded by collectors that do not implement LabeledCollector are appended
+	// to the metric keys.
+	LabeledCollector interface {
+		Collector
+		AddSampleWithLabels(key []string, val float32, labels []metrics.Label)
+		IncrCounterWithLabels(key []string, val float32, labels []metrics.Label)
+		MeasureSinceWithLabels(key []string, start time.Time, labels []metrics.Label)
+		SetGaugeWithLabels(key []string, val float32, labels []metrics.Label)
+	}
+
+	// requestMetrics records the metrics of the requests handled by an action.
+	requestMetrics struct {
+		ctrl, action string
+		inFlight     int64
+	}
+)
 
 func init() {
 	SetMetrics(NewNoOpCollector())
@@ -148,6 +190,108 @@ func SetGauge(key []string, val float32) {
 	GetMetrics().SetGauge(key, val)
 }
 
+// AddSampleWithLabels adds a labeled sample to an aggregated metric.
+// Usage:
+//     AddSampleWithLabels([]string{"my","namespace","key"}, 15.0, []metrics.Label{{Name: "name", Value: "value"}})
+func AddSampleWithLabels(key []string, val float32, labels []metrics.Label) {
+	normalizeKeys(key)
+
+	if lc, ok := GetMetrics().(LabeledCollector); ok {
+		lc.AddSampleWithLabels(key, val, labels)
+		return
+	}
+	GetMetrics().AddSample(labeledKey(key, labels), val)
+}
+
+// IncrCounterWithLabels increments the labeled counter named by `key`
+// Usage:
+//     IncrCounterWithLabels([]string{"my","namespace","counter"}, 1.0, []metrics.Label{{Name: "name", Value: "value"}})
+func IncrCounterWithLabels(key []string, val float32, labels []metrics.Label) {
+	normalizeKeys(key)
+
+	if lc, ok := GetMetrics().(LabeledCollector); ok {
+		lc.IncrCounterWithLabels(key, val, labels)
+		return
+	}
+	GetMetrics().IncrCounter(labeledKey(key, labels), val)
+}
+
+// MeasureSinceWithLabels creates a labeled timing metric that records
+// the duration of elapsed time since `start`
+// Usage:
+//     MeasureSinceWithLabels([]string{"my","namespace","action"}, time.Now(), []metrics.Label{{Name: "name", Value: "value"}})
+func MeasureSinceWithLabels(key []string, start time.Time, labels []metrics.Label) {
+	normalizeKeys(key)
+
+	if lc, ok := GetMetrics().(LabeledCollector); ok {
+		lc.MeasureSinceWithLabels(key, start, labels)
+		return
+	}
+	GetMetrics().MeasureSince(labeledKey(key, labels), start)
+}
+
+// SetGaugeWithLabels sets the labeled gauge to the specified value
+// Usage:
+//     SetGaugeWithLabels([]string{"my","namespace"}, 2.0, []metrics.Label{{Name: "name", Value: "value"}})
+func SetGaugeWithLabels(key []string, val float32, labels []metrics.Label) {
+	normalizeKeys(key)
+
+	if lc, ok := GetMetrics().(LabeledCollector); ok {
+		lc.SetGaugeWithLabels(key, val, labels)
+		return
+	}
+	GetMetrics().SetGauge(labeledKey(key, labels), val)
+}
+
+// labeledKey returns the key made of key followed by the normalized label values.
+func labeledKey(key []string, labels []metrics.Label) []string {
+	lk := make([]string, len(key), len(key)+len(labels))
+	copy(lk, key)
+	for _, l := range labels {
+		lk = append(lk, l.Value)
+	}
+	normalizeKeys(lk)
+	return lk
+}
+
+// newRequestMetrics returns the request metrics of the given action.
+func newRequestMetrics(ctrl, action string) *requestMetrics {
+	return &requestMetrics{ctrl: ctrl, action: action}
+}
+
+// start records the beginning of a request and returns the time it started.
+func (m *requestMetrics) start() time.Time {
+	n := atomic.AddInt64(&m.inFlight, 1)
+	SetGaugeWithLabels(copyKey(RequestsInFlightKey), float32(n), m.labels())
+	return time.Now()
+}
+
+// done records the completion of a request given its response.
+func (m *requestMetrics) done(resp *ResponseData, start time.Time) {
+	n := atomic.AddInt64(&m.inFlight, -1)
+	SetGaugeWithLabels(copyKey(RequestsInFlightKey), float32(n), m.labels())
+	status := http.StatusOK
+	if resp != nil && resp.Status != 0 {
+		status = resp.Status
+	}
+	labels := append(m.labels(), metrics.Label{Name: "status", Value: strconv.Itoa(status/100) + "xx"})
+	IncrCounterWithLabels(copyKey(RequestsKey), 1, labels)
+	MeasureSinceWithLabels(copyKey(RequestDurationKey), start, labels)
+	if resp != nil {
+		AddSampleWithLabels(copyKey(ResponseSizeKey), float32(resp.Length), labels)
+	}
+}
+
+// labels returns the controller and action labels.
+func (m *requestMetrics) labels() []metrics.Label {
+	return []metrics.Label{{Name: "controller", Value: m.ctrl}, {Name: "action", Value: m.action}}
+}
+
+// copyKey returns a copy of key so that normalizing it does not modify the original.
+func copyKey(key []string) []string {
+	return append([]string(nil), key...)
+}
+
 // This function is used to make metric names safe for all metric services. Specifically, prometheus does
 // not support * or / in metric names.
 func normalizeKeys(key []string) {
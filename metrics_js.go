@@ -15,3 +15,12 @@ func IncrCounter(key []string, val float32) {
 func MeasureSince(key []string, start time.Time) {
 	// Do nothing
 }
+
+// requestMetrics records no request metrics, they are not supported in gopherjs.
+type requestMetrics struct{}
+
+func newRequestMetrics(ctrl, action string) *requestMetrics { return &requestMetrics{} }
+
+func (m *requestMetrics) start() time.Time { return time.Time{} }
+
+func (m *requestMetrics) done(resp *ResponseData, start time.Time) {}
@@ -120,4 +120,36 @@ var _ = Describe("Metrics", func() {
 			})
 		})
 	})
+
+	Describe("Increment counter with labels", func() {
+		var collector *keysCollector
+
+		BeforeEach(func() {
+			collector = &keysCollector{}
+			goa.SetMetrics(collector)
+		})
+
+		AfterEach(func() {
+			goa.SetMetrics(goa.NewNoOpCollector())
+		})
+
+		It("appends the label values to the key of collectors without labels", func() {
+			labels := []metrics.Label{{Name: "controller", Value: "bottle"}, {Name: "content", Value: "*/*"}}
+			goa.IncrCounterWithLabels([]string{"requests"}, 1, labels)
+			Ω(collector.keys).Should(Equal([][]string{{"requests", "bottle", "all"}}))
+		})
+	})
 })
+
+// keysCollector is a collector that records the counter keys.
+type keysCollector struct {
+	keys [][]string
+}
+
+func (*keysCollector) AddSample(key []string, val float32)        {}
+func (*keysCollector) EmitKey(key []string, val float32)          {}
+func (*keysCollector) MeasureSince(key []string, start time.Time) {}
+func (*keysCollector) SetGauge(key []string, val float32)         {}
+func (c *keysCollector) IncrCounter(key []string, val float32) {
+	c.keys = append(c.keys, key)
+}
@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+// AccessLogFormat is the format of the records written by the AccessLog middleware.
+type AccessLogFormat int
+
+const (
+	// AccessLogCommon is the Apache Common Log Format:
+	//
+	//	127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /bottles HTTP/1.1" 200 2326
+	AccessLogCommon AccessLogFormat = iota + 1
+
+	// AccessLogCombined is the Apache Combined Log Format, that is the Common Log Format
+	// followed by the request "Referer" and "User-Agent" headers.
+	AccessLogCombined
+
+	// AccessLogJSON writes the records as JSON objects.
+	AccessLogJSON
+
+	// AccessLogLogfmt writes the records as logfmt key/value pairs.
+	AccessLogLogfmt
+)
+
+// accessRecord is the information recorded for each request by the AccessLog middleware.
+type accessRecord struct {
+	Time       time.Time `json:"time"`
+	RemoteAddr string    `json:"remote_addr"`
+	User       string    `json:"user,omitempty"`
+	Method     string    `json:"method"`
+	URI        string    `json:"uri"`
+	Proto      string    `json:"proto"`
+	Status     int       `json:"status"`
+	BytesIn    int64     `json:"bytes_in"`
+	BytesOut   int       `json:"bytes_out"`
+	Latency    float64   `json:"latency_ms"`
+	RequestID  string    `json:"req_id,omitempty"`
+	TraceID    string    `json:"trace_id,omitempty"`
+	Controller string    `json:"ctrl,omitempty"`
+	Action     string    `json:"action,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Referer    string    `json:"referer,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+}
+
+// AccessLog returns a middleware that writes one record per request once the request completes.
+// The JSON and logfmt records include the request ID set by the RequestID middleware, the trace
+// ID set by the tracer middleware, the controller and action names, the sizes of the request and
+// response bodies and the request latency in milliseconds. The Apache formats only include the
+// standard fields so that the records can be processed by the existing tools.
+//
+// The records are written to w, one per line. The records are logged with the service logger if
+// w is nil: the Apache records are logged as the message while the fields of the JSON and logfmt
+// records are logged as key/value pairs so that the logger encodes them.
+func AccessLog(format AccessLogFormat, w io.Writer) goa.Middleware {
+	if format < AccessLogCommon || format > AccessLogLogfmt {
+		panic(fmt.Sprintf("invalid access log format %d", format))
+	}
+	var mu sync.Mutex
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			startedAt := time.Now()
+			err := h(ctx, rw, req)
+			rec := newAccessRecord(ctx, req, startedAt, err)
+			if w == nil {
+				if format == AccessLogCommon || format == AccessLogCombined {
+					goa.LogInfo(ctx, string(rec.format(format)))
+				} else {
+					goa.LogInfo(ctx, "access", rec.keyvals()...)
+				}
+				return err
+			}
+			line := append(rec.format(format), '\n')
+			mu.Lock()
+			defer mu.Unlock()
+			if _, werr := w.Write(line); werr != nil {
+				goa.LogError(ctx, "access log", "err", werr)
+			}
+			return err
+		}
+	}
+}
+
+// newAccessRecord returns the access record of the completed request.
+func newAccessRecord(ctx context.Context, req *http.Request, startedAt time.Time, err error) *accessRecord {
+	rec := &accessRecord{
+		Time:       startedAt,
+		RemoteAddr: from(req),
+		Method:     req.Method,
+		URI:        req.RequestURI,
+		Proto:      req.Proto,
+		Status:     http.StatusOK,
+		Latency:    float64(time.Since(startedAt)) / float64(time.Millisecond),
+		RequestID:  ContextRequestID(ctx),
+		TraceID:    ContextTraceID(ctx),
+		Controller: goa.ContextController(ctx),
+		Action:     goa.ContextAction(ctx),
+		Referer:    req.Referer(),
+		UserAgent:  req.UserAgent(),
+	}
+	if rec.URI == "" {
+		rec.URI = req.URL.RequestURI()
+	}
+	if user, _, ok := req.BasicAuth(); ok {
+		rec.User = user
+	}
+	if req.ContentLength > 0 {
+		rec.BytesIn = req.ContentLength
+	}
+	if resp := goa.ContextResponse(ctx); resp != nil {
+		rec.BytesOut = resp.Length
+		rec.Error = resp.ErrorCode
+		if resp.Status != 0 {
+			rec.Status = resp.Status
+		} else if serr, ok := err.(goa.ServiceError); ok {
+			rec.Status = serr.ResponseStatus()
+		} else if err != nil {
+			rec.Status = http.StatusInternalServerError
+		}
+	}
+	return rec
+}
+
+// format returns the record formatted with the given format.
+func (r *accessRecord) format(format AccessLogFormat) []byte {
+	var buf bytes.Buffer
+	switch format {
+	case AccessLogCommon, AccessLogCombined:
+		bytesOut := "-"
+		if r.BytesOut > 0 {
+			bytesOut = strconv.Itoa(r.BytesOut)
+		}
+		fmt.Fprintf(&buf, "%s - %s [%s] %s %d %s", r.RemoteAddr, dash(r.User),
+			r.Time.Format("02/Jan/2006:15:04:05 -0700"),
+			strconv.Quote(r.Method+" "+r.URI+" "+r.Proto), r.Status, bytesOut)
+		if format == AccessLogCombined {
+			fmt.Fprintf(&buf, " %s %s", strconv.Quote(dash(r.Referer)), strconv.Quote(dash(r.UserAgent)))
+		}
+	case AccessLogJSON:
+		b, _ := json.Marshal(r)
+		buf.Write(b)
+	case AccessLogLogfmt:
+		kvs := r.keyvals()
+		for i := 0; i < len(kvs); i += 2 {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(kvs[i].(string))
+			buf.WriteByte('=')
+			buf.WriteString(logfmtValue(fmt.Sprint(kvs[i+1])))
+		}
+	}
+	return buf.Bytes()
+}
+
+// keyvals returns the fields of the record as key/value pairs, the empty optional fields are
+// omitted.
+func (r *accessRecord) keyvals() []interface{} {
+	kvs := []interface{}{
+		"time", r.Time.Format(time.RFC3339Nano),
+		"remote_addr", r.RemoteAddr,
+	}
+	if r.User != "" {
+		kvs = append(kvs, "user", r.User)
+	}
+	kvs = append(kvs,
+		"method", r.Method,
+		"uri", r.URI,
+		"proto", r.Proto,
+		"status", r.Status,
+		"bytes_in", r.BytesIn,
+		"bytes_out", r.BytesOut,
+		"latency_ms", r.Latency,
+	)
+	optional := []string{
+		"req_id", r.RequestID,
+		"trace_id", r.TraceID,
+		"ctrl", r.Controller,
+		"action", r.Action,
+		"error", r.Error,
+		"referer", r.Referer,
+		"user_agent", r.UserAgent,
+	}
+	for i := 0; i < len(optional); i += 2 {
+		if optional[i+1] != "" {
+			kvs = append(kvs, optional[i], optional[i+1])
+		}
+	}
+	return kvs
+}
+
+// logfmtValue quotes v if it is empty or contains spaces, quotes, equal signs or control
+// characters.
+func logfmtValue(v string) string {
+	if v == "" || strings.IndexFunc(v, func(r rune) bool {
+		return r <= ' ' || r == '"' || r == '=' || r == 0x7f
+	}) >= 0 {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+// dash returns "-" if s is empty, s otherwise.
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
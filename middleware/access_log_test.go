@@ -0,0 +1,121 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AccessLog", func() {
+	var ctx context.Context
+	var req *http.Request
+	var logger *testLogger
+	var service *goa.Service
+	var buf *bytes.Buffer
+
+	h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return service.Send(ctx, 201, "created")
+	}
+
+	BeforeEach(func() {
+		logger = new(testLogger)
+		service = newService(logger)
+		buf = new(bytes.Buffer)
+
+		var err error
+		req, err = http.NewRequest("POST", "http://localhost/bottles?sort=name", strings.NewReader(`{"name":"bottle"}`))
+		Ω(err).ShouldNot(HaveOccurred())
+		req.RequestURI = "/bottles?sort=name"
+		req.RemoteAddr = "10.0.0.1:4242"
+		req.Header.Set(middleware.RequestIDHeader, "reqID")
+		req.Header.Set("Referer", "http://goa.design")
+		req.Header.Set("User-Agent", "test agent")
+		req.SetBasicAuth("frank", "secret")
+		ctx = newContext(service, newTestResponseWriter(), req, nil)
+		ctx = goa.WithAction(ctx, "create")
+		ctx = middleware.WithTrace(ctx, "traceID", "spanID", "")
+	})
+
+	run := func(format middleware.AccessLogFormat, w *bytes.Buffer) {
+		m := middleware.AccessLog(format, nil)
+		if w != nil {
+			m = middleware.AccessLog(format, w)
+		}
+		hdlr := middleware.RequestID()(m(h))
+		Ω(hdlr(ctx, goa.ContextResponse(ctx), req)).ShouldNot(HaveOccurred())
+	}
+
+	It("writes Common Log Format records", func() {
+		run(middleware.AccessLogCommon, buf)
+		Ω(buf.String()).Should(MatchRegexp(`^10\.0\.0\.1 - frank \[\d\d/\w{3}/\d{4}:\d\d:\d\d:\d\d [+-]\d{4}\] "POST /bottles\?sort=name HTTP/1\.1" 201 10\n$`))
+	})
+
+	It("writes Combined Log Format records", func() {
+		run(middleware.AccessLogCombined, buf)
+		Ω(buf.String()).Should(HaveSuffix(`" 201 10 "http://goa.design" "test agent"` + "\n"))
+	})
+
+	It("writes JSON records", func() {
+		run(middleware.AccessLogJSON, buf)
+		var rec map[string]interface{}
+		Ω(json.Unmarshal(buf.Bytes(), &rec)).ShouldNot(HaveOccurred())
+		Ω(rec).Should(HaveKeyWithValue("remote_addr", "10.0.0.1"))
+		Ω(rec).Should(HaveKeyWithValue("method", "POST"))
+		Ω(rec).Should(HaveKeyWithValue("status", 201.0))
+		Ω(rec).Should(HaveKeyWithValue("bytes_in", 17.0))
+		Ω(rec).Should(HaveKeyWithValue("bytes_out", 10.0))
+		Ω(rec).Should(HaveKeyWithValue("req_id", "reqID"))
+		Ω(rec).Should(HaveKeyWithValue("trace_id", "traceID"))
+		Ω(rec).Should(HaveKeyWithValue("ctrl", "test"))
+		Ω(rec).Should(HaveKeyWithValue("action", "create"))
+		Ω(rec).Should(HaveKey("latency_ms"))
+	})
+
+	It("writes logfmt records", func() {
+		run(middleware.AccessLogLogfmt, buf)
+		Ω(buf.String()).Should(MatchRegexp(`^time=\S+ remote_addr=10\.0\.0\.1 user=frank method=POST uri="/bottles\?sort=name" proto=HTTP/1\.1 status=201 bytes_in=17 bytes_out=10 latency_ms=\S+ req_id=reqID trace_id=traceID ctrl=test action=create referer=http://goa\.design user_agent="test agent"\n$`))
+	})
+
+	It("logs the records with the service logger", func() {
+		run(middleware.AccessLogCommon, nil)
+		Ω(logger.InfoEntries).Should(HaveLen(1))
+		Ω(logger.InfoEntries[0].Msg).Should(MatchRegexp(`^10\.0\.0\.1 - frank .* 201 10$`))
+
+		logger.InfoEntries = nil
+		run(middleware.AccessLogLogfmt, nil)
+		Ω(logger.InfoEntries).Should(HaveLen(1))
+		Ω(logger.InfoEntries[0].Msg).Should(Equal("access"))
+		Ω(logger.InfoEntries[0].Data).Should(ContainElement("traceID"))
+	})
+
+	It("logs the status of the service errors returned by the handler", func() {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return goa.ErrNotFound("bottle not found")
+		}
+		err := middleware.AccessLog(middleware.AccessLogCommon, buf)(h)(ctx, goa.ContextResponse(ctx), req)
+		Ω(err).Should(HaveOccurred())
+		Ω(buf.String()).Should(ContainSubstring(`"POST /bottles?sort=name HTTP/1.1" 404 -`))
+	})
+
+	It("logs the errors that are not service errors as internal errors", func() {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return errors.New("boom")
+		}
+		err := middleware.AccessLog(middleware.AccessLogCommon, buf)(h)(ctx, goa.ContextResponse(ctx), req)
+		Ω(err).Should(HaveOccurred())
+		Ω(buf.String()).Should(ContainSubstring(`"POST /bottles?sort=name HTTP/1.1" 500 -`))
+	})
+
+	It("panics with invalid formats", func() {
+		Ω(func() { middleware.AccessLog(0, buf) }).Should(Panic())
+	})
+})
+
@@ -0,0 +1,425 @@
+/*
+Package cache provides a middleware that caches the encoded responses to GET requests. Responses are
+cached per request path and query string. The request headers listed in the response "Vary" header
+and the request "Accept" header, used by goa to negotiate the response encoding, select between
+the cached variants.
+
+Only successful (200) responses are cached. Responses that set cookies, that have a "Vary: *"
+header or whose "Cache-Control" header includes the "private", "no-cache" or "no-store" directives
+are not cached. Responses to requests that have an "Authorization" header or the credentials
+declared with the WithCredentials option are only cached if their "Cache-Control" header includes
+the "public" or "s-maxage" directives (RFC 7234 section 3.2). The credentials declared with
+WithCredentials also select the cached variants so that the responses cached for a caller are never
+served to another. Requests with a "Cache-Control: no-cache" header bypass the cache and refresh
+it. Cached responses include the "Age" header.
+
+The middleware buffers the response by switching the writer of the goa response data the same way
+the gzip middleware does. Mounting the gzip middleware on the service thus compresses both the
+cached and the fresh responses. Responses flushed by the handler, such as event streams, are written
+as they are flushed and are not cached.
+
+The responses are kept in memory by default. Use UseStore or the WithStore option to share the
+cache across service instances:
+
+	cache.UseStore(service, redisStore)
+	service.Use(cache.New(time.Minute, cache.WithCacheControl("max-age=60, public")))
+
+The controllers generated from designs that use the CacheControl DSL or the "cache:ttl" metadata
+apply the middleware to the corresponding actions.
+*/
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+type (
+	// Option configures the middleware created by New.
+	Option func(*options)
+
+	options struct {
+		scope        string
+		store        Store
+		cacheControl string
+		vary         []string
+		credHeaders  []string
+		credParams   []string
+	}
+
+	// cacheWriter buffers the response so that it can be stored before it is sent. Flushed
+	// responses are written to the underlying writer as they are written and are not stored.
+	cacheWriter struct {
+		http.ResponseWriter
+		cacheControl string
+		status       int
+		body         bytes.Buffer
+		flushed      bool
+	}
+
+	// key is the type of the context keys used by the package.
+	key int
+)
+
+const (
+	// storeKey is the key used to store the service Store in the context.
+	storeKey key = iota + 1
+)
+
+// defaultStore is the store used when none is configured.
+var defaultStore = NewMemoryStore(DefaultMemoryStoreSize)
+
+// New returns a middleware that caches the successful responses to GET requests for ttl. A zero
+// ttl disables the cache, which is useful to only set the "Cache-Control" header with the
+// WithCacheControl option. The responses are not buffered in this case and the middleware does
+// nothing if there is no header to set either.
+//
+// The middleware uses the store given to the WithStore option, the store registered on the
+// service with UseStore or an in-memory store in this order. Requests are handled as if the
+// response was not cached if the store fails.
+func New(ttl time.Duration, opts ...Option) goa.Middleware {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return func(h goa.Handler) goa.Handler {
+		if ttl <= 0 && o.cacheControl == "" {
+			return h
+		}
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			resp := goa.ContextResponse(ctx)
+			if resp == nil || req.Method != "GET" {
+				return h(ctx, rw, req)
+			}
+			if ttl <= 0 {
+				// Nothing is cached: only set the header, without buffering the response.
+				cw := &cacheWriter{cacheControl: o.cacheControl, flushed: true}
+				cw.ResponseWriter = resp.SwitchWriter(cw)
+				err := h(ctx, rw, req)
+				resp.SwitchWriter(cw.ResponseWriter)
+				return err
+			}
+			store := o.store
+			if store == nil {
+				store = contextStore(ctx)
+			}
+			query := req.URL.Query()
+			for _, p := range o.credParams {
+				query.Del(p)
+			}
+			base := o.scope + ":" + req.URL.Path + "?" + query.Encode()
+			if !hasDirective(req.Header, "no-cache") {
+				if e := lookup(ctx, store, base, req, o.credParams); e != nil {
+					goa.IncrCounter([]string{"goa", "cache", "hit"}, 1.0)
+					return replay(resp, e)
+				}
+				goa.IncrCounter([]string{"goa", "cache", "miss"}, 1.0)
+			}
+			before := cloneHeader(resp.Header())
+			cw := &cacheWriter{cacheControl: o.cacheControl}
+			cw.ResponseWriter = resp.SwitchWriter(cw)
+			err := h(ctx, rw, req)
+			resp.SwitchWriter(cw.ResponseWriter)
+			if err == nil && cw.status == http.StatusOK && !cw.flushed {
+				save(ctx, store, base, req, cw, before, ttl, &o)
+			}
+			if cerr := cw.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
+			return err
+		}
+	}
+}
+
+// WithScope sets the prefix of the cache keys. Middlewares with the same scope and store share
+// their entries, the default scope is empty.
+func WithScope(scope string) Option {
+	return func(o *options) {
+		o.scope = scope
+	}
+}
+
+// WithVary adds the given request headers to the headers that select the cached variants of the
+// responses, as if the responses listed them in their "Vary" header. Use WithCredentials for the
+// headers that carry credentials.
+func WithVary(headers ...string) Option {
+	return func(o *options) {
+		o.vary = append(o.vary, headers...)
+	}
+}
+
+// WithCredentials declares the request header or query string parameter that carries the
+// credentials of the requests: in is "header" or "query" and name is the name of the header or of
+// the parameter. Responses to requests that include the credentials are only cached if they are
+// public and the cached variants are selected by the credentials. The controllers generated for
+// secured actions declare the credentials of the action security scheme.
+func WithCredentials(in, name string) Option {
+	return func(o *options) {
+		if in == "query" {
+			o.credParams = append(o.credParams, name)
+			return
+		}
+		o.credHeaders = append(o.credHeaders, name)
+		o.vary = append(o.vary, name)
+	}
+}
+
+// WithStore sets the store used to record the cached responses.
+func WithStore(store Store) Option {
+	return func(o *options) {
+		o.store = store
+	}
+}
+
+// WithCacheControl sets the value of the "Cache-Control" header of the successful responses that
+// do not set it already.
+func WithCacheControl(value string) Option {
+	return func(o *options) {
+		o.cacheControl = value
+	}
+}
+
+// UseStore sets the store used by the middlewares mounted on the service that are not given a
+// store explicitly. UseStore must be called before the controllers are created.
+func UseStore(service *goa.Service, store Store) {
+	service.Context = context.WithValue(service.Context, storeKey, store)
+}
+
+// lookup returns the cached response to the request if any. params lists the query string
+// parameters that select the variants.
+func lookup(ctx context.Context, store Store, base string, req *http.Request, params []string) *Entry {
+	index, err := store.Get(ctx, base)
+	if err != nil {
+		goa.LogError(ctx, "cache", "err", err)
+		return nil
+	}
+	if index == nil {
+		return nil
+	}
+	e, err := store.Get(ctx, variantKey(base, index.Vary, params, req))
+	if err != nil {
+		goa.LogError(ctx, "cache", "err", err)
+		return nil
+	}
+	return e
+}
+
+// replay writes the cached response.
+func replay(resp *goa.ResponseData, e *Entry) error {
+	header := resp.Header()
+	for k, v := range e.Header {
+		header[k] = append([]string(nil), v...)
+	}
+	header.Set("Age", strconv.FormatInt(int64(time.Since(e.Created)/time.Second), 10))
+	resp.WriteHeader(e.Status)
+	_, err := resp.Write(e.Body)
+	return err
+}
+
+// save stores the buffered response if it may be cached. Only the headers set by the handler are
+// stored.
+func save(ctx context.Context, store Store, base string, req *http.Request, cw *cacheWriter, before http.Header, ttl time.Duration, o *options) {
+	header := cw.Header()
+	vary, ok := varyHeaders(header, o.vary)
+	if !ok || header.Get("Set-Cookie") != "" {
+		return
+	}
+	for _, d := range []string{"private", "no-cache", "no-store"} {
+		if hasDirective(header, d) {
+			return
+		}
+	}
+	if o.hasCredentials(req) && !hasDirective(header, "public") && !hasDirective(header, "s-maxage") {
+		return
+	}
+	set := make(http.Header)
+	for k, v := range header {
+		if !equal(before[k], v) {
+			set[k] = append([]string(nil), v...)
+		}
+	}
+	now := time.Now()
+	e := &Entry{Status: cw.status, Header: set, Body: cw.body.Bytes(), Created: now}
+	if err := store.Set(ctx, base, &Entry{Vary: vary, Created: now}, ttl); err != nil {
+		goa.LogError(ctx, "cache", "err", err)
+		return
+	}
+	if err := store.Set(ctx, variantKey(base, vary, o.credParams, req), e, ttl); err != nil {
+		goa.LogError(ctx, "cache", "err", err)
+	}
+}
+
+// varyHeaders returns the sorted canonical names of the request headers listed in the "Vary"
+// response header, in extra and the "Accept" header. varyHeaders returns false if the response
+// varies on "*" and thus cannot be cached.
+func varyHeaders(header http.Header, extra []string) ([]string, bool) {
+	names := map[string]bool{"Accept": true}
+	for _, name := range extra {
+		names[textproto.CanonicalMIMEHeaderKey(name)] = true
+	}
+	for _, v := range header["Vary"] {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return nil, false
+			}
+			if name != "" {
+				names[textproto.CanonicalMIMEHeaderKey(name)] = true
+			}
+		}
+	}
+	vary := make([]string, 0, len(names))
+	for name := range names {
+		vary = append(vary, name)
+	}
+	sort.Strings(vary)
+	return vary, true
+}
+
+// variantKey returns the key of the response selected by the values of the given request headers
+// and query string parameters. The values are hashed so that credentials are not written to the
+// store.
+func variantKey(base string, vary, params []string, req *http.Request) string {
+	values := make(url.Values, len(vary)+len(params))
+	for _, name := range vary {
+		values.Set(name, req.Header.Get(name))
+	}
+	query := req.URL.Query()
+	for _, name := range params {
+		values.Set("?"+name, query.Get(name))
+	}
+	sum := sha256.Sum256([]byte(values.Encode()))
+	return base + "#" + hex.EncodeToString(sum[:])
+}
+
+// hasCredentials returns true if the request has an "Authorization" header or includes the
+// credentials declared with WithCredentials.
+func (o *options) hasCredentials(req *http.Request) bool {
+	if req.Header.Get("Authorization") != "" {
+		return true
+	}
+	for _, h := range o.credHeaders {
+		if req.Header.Get(h) != "" {
+			return true
+		}
+	}
+	query := req.URL.Query()
+	for _, p := range o.credParams {
+		if query.Get(p) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDirective returns true if the "Cache-Control" header includes the given directive, with or
+// without a value.
+func hasDirective(header http.Header, directive string) bool {
+	for _, v := range header["Cache-Control"] {
+		for _, d := range strings.Split(v, ",") {
+			name := strings.TrimSpace(d)
+			if i := strings.IndexByte(name, '='); i >= 0 {
+				name = strings.TrimSpace(name[:i])
+			}
+			if strings.EqualFold(name, directive) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// contextStore returns the store registered with UseStore or the default store.
+func contextStore(ctx context.Context) Store {
+	if s, ok := ctx.Value(storeKey).(Store); ok {
+		return s
+	}
+	return defaultStore
+}
+
+// cloneHeader returns a copy of the given header.
+func cloneHeader(h http.Header) http.Header {
+	c := make(http.Header, len(h))
+	for k, v := range h {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
+
+// equal returns true if a and b contain the same values.
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteHeader records the response status code and sets the "Cache-Control" header of successful
+// responses that do not set it already.
+func (w *cacheWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+	if status == http.StatusOK && w.cacheControl != "" && w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", w.cacheControl)
+	}
+	if w.flushed {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// Write buffers the response body until the response is flushed.
+func (w *cacheWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.flushed {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+// Flush implements http.Flusher so that streamed responses such as event streams reach the client
+// as they are written. Flush writes the buffered response and flushes the underlying writer, the
+// response is not cached.
+func (w *cacheWriter) Flush() {
+	if !w.flushed {
+		if w.status == 0 {
+			w.WriteHeader(http.StatusOK)
+		}
+		if err := w.Close(); err != nil {
+			return
+		}
+		w.flushed = true
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close writes the buffered response to the underlying writer unless it was flushed already.
+func (w *cacheWriter) Close() error {
+	if w.flushed || w.status == 0 {
+		return nil
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(w.body.Bytes())
+	return err
+}
@@ -0,0 +1,331 @@
+package cache_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/cache"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var opts []cache.Option
+	var vary, cacheControl string
+	var h goa.Handler
+	var calls int
+
+	BeforeEach(func() {
+		opts = []cache.Option{cache.WithStore(cache.NewMemoryStore(10))}
+		vary = ""
+		cacheControl = ""
+		calls = 0
+	})
+
+	JustBeforeEach(func() {
+		h = cache.New(time.Minute, opts...)(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			calls++
+			if vary != "" {
+				rw.Header().Set("Vary", vary)
+			}
+			if cacheControl != "" {
+				rw.Header().Set("Cache-Control", cacheControl)
+			}
+			rw.Header().Set("Content-Type", "text/plain")
+			rw.WriteHeader(200)
+			rw.Write([]byte("call " + strconv.Itoa(calls)))
+			return nil
+		})
+	})
+
+	send := func(method, path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rw := httptest.NewRecorder()
+		ctx := goa.NewContext(context.Background(), rw, req, nil)
+		rw.Header().Set("X-Outer", "outer "+strconv.Itoa(calls))
+		Ω(h(ctx, goa.ContextResponse(ctx), req)).ShouldNot(HaveOccurred())
+		return rw
+	}
+
+	It("caches the responses", func() {
+		send("GET", "/bottles?b=2&a=1", nil)
+		rw := send("GET", "/bottles?a=1&b=2", nil)
+		Ω(calls).Should(Equal(1))
+		Ω(rw.Code).Should(Equal(200))
+		Ω(rw.Body.String()).Should(Equal("call 1"))
+		Ω(rw.Header().Get("Content-Type")).Should(Equal("text/plain"))
+		Ω(rw.Header().Get("Age")).Should(Equal("0"))
+		Ω(rw.Header().Get("X-Outer")).Should(Equal("outer 1"))
+	})
+
+	It("caches each path and query separately", func() {
+		send("GET", "/bottles?a=1", nil)
+		rw := send("GET", "/bottles?a=2", nil)
+		Ω(rw.Body.String()).Should(Equal("call 2"))
+	})
+
+	It("does not cache other methods", func() {
+		send("POST", "/bottles", nil)
+		send("POST", "/bottles", nil)
+		Ω(calls).Should(Equal(2))
+	})
+
+	It("selects the variant using the Accept header", func() {
+		send("GET", "/bottles", http.Header{"Accept": {"application/json"}})
+		rw := send("GET", "/bottles", http.Header{"Accept": {"application/xml"}})
+		Ω(rw.Body.String()).Should(Equal("call 2"))
+		rw = send("GET", "/bottles", http.Header{"Accept": {"application/json"}})
+		Ω(rw.Body.String()).Should(Equal("call 1"))
+	})
+
+	It("refreshes the cache on no-cache requests", func() {
+		send("GET", "/bottles", nil)
+		send("GET", "/bottles", http.Header{"Cache-Control": {"no-cache"}})
+		rw := send("GET", "/bottles", nil)
+		Ω(rw.Body.String()).Should(Equal("call 2"))
+	})
+
+	Context("with a Vary header", func() {
+		BeforeEach(func() {
+			vary = "accept-language"
+		})
+
+		It("selects the variant using the listed headers", func() {
+			send("GET", "/bottles", http.Header{"Accept-Language": {"en"}})
+			rw := send("GET", "/bottles", http.Header{"Accept-Language": {"fr"}})
+			Ω(rw.Body.String()).Should(Equal("call 2"))
+			rw = send("GET", "/bottles", http.Header{"Accept-Language": {"en"}})
+			Ω(rw.Body.String()).Should(Equal("call 1"))
+			rw = send("GET", "/bottles", http.Header{"Accept-Language": {"fr"}})
+			Ω(rw.Body.String()).Should(Equal("call 2"))
+			Ω(calls).Should(Equal(2))
+		})
+	})
+
+	Context("with private responses", func() {
+		BeforeEach(func() {
+			cacheControl = "private, max-age=60"
+		})
+
+		It("does not cache them", func() {
+			send("GET", "/bottles", nil)
+			send("GET", "/bottles", nil)
+			Ω(calls).Should(Equal(2))
+		})
+	})
+
+	Context("with requests carrying credentials", func() {
+		alice := http.Header{"Authorization": {"Bearer alice"}}
+		bob := http.Header{"Authorization": {"Bearer bob"}}
+
+		It("does not cache the responses", func() {
+			send("GET", "/bottles", alice)
+			rw := send("GET", "/bottles", bob)
+			Ω(rw.Body.String()).Should(Equal("call 2"))
+			rw = send("GET", "/bottles", nil)
+			Ω(rw.Body.String()).Should(Equal("call 3"))
+			Ω(calls).Should(Equal(3))
+		})
+
+		Context("and public responses", func() {
+			BeforeEach(func() {
+				cacheControl = "public, max-age=60"
+			})
+
+			It("caches the responses", func() {
+				send("GET", "/bottles", alice)
+				rw := send("GET", "/bottles", bob)
+				Ω(rw.Body.String()).Should(Equal("call 1"))
+			})
+		})
+
+		Context("and shared max age responses varying on the credentials", func() {
+			BeforeEach(func() {
+				cacheControl = "s-maxage=60"
+				opts = append(opts, cache.WithVary("authorization"))
+			})
+
+			It("caches the responses per credentials", func() {
+				send("GET", "/bottles", alice)
+				rw := send("GET", "/bottles", bob)
+				Ω(rw.Body.String()).Should(Equal("call 2"))
+				rw = send("GET", "/bottles", alice)
+				Ω(rw.Body.String()).Should(Equal("call 1"))
+				rw = send("GET", "/bottles", bob)
+				Ω(rw.Body.String()).Should(Equal("call 2"))
+				Ω(calls).Should(Equal(2))
+			})
+		})
+	})
+
+	Context("with credentials sent in a header", func() {
+		alice := http.Header{"X-Api-Key": {"alice"}}
+		bob := http.Header{"X-Api-Key": {"bob"}}
+
+		BeforeEach(func() {
+			opts = append(opts, cache.WithCredentials("header", "X-API-Key"))
+		})
+
+		It("does not cache the responses", func() {
+			send("GET", "/bottles", alice)
+			rw := send("GET", "/bottles", alice)
+			Ω(rw.Body.String()).Should(Equal("call 2"))
+		})
+
+		Context("and public responses", func() {
+			BeforeEach(func() {
+				cacheControl = "public, max-age=60"
+			})
+
+			It("caches the responses per credentials", func() {
+				send("GET", "/bottles", alice)
+				rw := send("GET", "/bottles", bob)
+				Ω(rw.Body.String()).Should(Equal("call 2"))
+				rw = send("GET", "/bottles", alice)
+				Ω(rw.Body.String()).Should(Equal("call 1"))
+			})
+		})
+	})
+
+	Context("with credentials sent in a query string parameter", func() {
+		var store cache.Store
+
+		BeforeEach(func() {
+			store = cache.NewMemoryStore(10)
+			opts = []cache.Option{cache.WithStore(store), cache.WithCredentials("query", "key")}
+		})
+
+		It("does not cache the responses", func() {
+			send("GET", "/bottles?key=alice", nil)
+			rw := send("GET", "/bottles?key=alice", nil)
+			Ω(rw.Body.String()).Should(Equal("call 2"))
+		})
+
+		Context("and shared max age responses", func() {
+			BeforeEach(func() {
+				cacheControl = "s-maxage=60"
+			})
+
+			It("caches the responses per credentials", func() {
+				send("GET", "/bottles?key=alice", nil)
+				rw := send("GET", "/bottles?key=bob", nil)
+				Ω(rw.Body.String()).Should(Equal("call 2"))
+				rw = send("GET", "/bottles?key=alice", nil)
+				Ω(rw.Body.String()).Should(Equal("call 1"))
+			})
+
+			It("does not write the credentials in the keys", func() {
+				send("GET", "/bottles?key=alice", nil)
+				e, err := store.Get(context.Background(), ":/bottles?")
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(e).ShouldNot(BeNil())
+			})
+		})
+	})
+
+	Context("with a Cache-Control option", func() {
+		BeforeEach(func() {
+			opts = append(opts, cache.WithCacheControl("max-age=60, public"))
+		})
+
+		It("sets the header of fresh and cached responses", func() {
+			rw := send("GET", "/bottles", nil)
+			Ω(rw.Header().Get("Cache-Control")).Should(Equal("max-age=60, public"))
+			rw = send("GET", "/bottles", nil)
+			Ω(rw.Header().Get("Cache-Control")).Should(Equal("max-age=60, public"))
+			Ω(calls).Should(Equal(1))
+		})
+
+		Context("and a zero TTL", func() {
+			var rec *httptest.ResponseRecorder
+			var written string
+
+			JustBeforeEach(func() {
+				h = cache.New(0, opts...)(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					calls++
+					rw.WriteHeader(200)
+					rw.Write([]byte("call " + strconv.Itoa(calls)))
+					written = rec.Body.String()
+					return nil
+				})
+			})
+
+			serve := func() {
+				req := httptest.NewRequest("GET", "/bottles", nil)
+				rec = httptest.NewRecorder()
+				ctx := goa.NewContext(context.Background(), rec, req, nil)
+				Ω(h(ctx, goa.ContextResponse(ctx), req)).ShouldNot(HaveOccurred())
+			}
+
+			It("sets the header without buffering the responses", func() {
+				serve()
+				Ω(written).Should(Equal("call 1"))
+				Ω(rec.Header().Get("Cache-Control")).Should(Equal("max-age=60, public"))
+				serve()
+				Ω(rec.Body.String()).Should(Equal("call 2"))
+			})
+		})
+	})
+
+	Context("with a handler that flushes the response", func() {
+		var rec *httptest.ResponseRecorder
+		var flushed string
+
+		JustBeforeEach(func() {
+			h = cache.New(time.Minute, opts...)(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				calls++
+				resp := goa.ContextResponse(ctx)
+				resp.WriteHeader(200)
+				resp.Write([]byte("call " + strconv.Itoa(calls)))
+				resp.ResponseWriter.(http.Flusher).Flush()
+				flushed = rec.Body.String()
+				return nil
+			})
+		})
+
+		serve := func() {
+			req := httptest.NewRequest("GET", "/events", nil)
+			rec = httptest.NewRecorder()
+			ctx := goa.NewContext(context.Background(), rec, req, nil)
+			Ω(h(ctx, goa.ContextResponse(ctx), req)).ShouldNot(HaveOccurred())
+		}
+
+		It("writes the response when flushed and does not cache it", func() {
+			serve()
+			Ω(flushed).Should(Equal("call 1"))
+			Ω(rec.Flushed).Should(BeTrue())
+			serve()
+			Ω(rec.Body.String()).Should(Equal("call 2"))
+		})
+	})
+
+	Context("with a service store", func() {
+		var service *goa.Service
+		var store cache.Store
+
+		BeforeEach(func() {
+			service = goa.New("test")
+			store = cache.NewMemoryStore(10)
+			cache.UseStore(service, store)
+			opts = []cache.Option{cache.WithScope("bottle.list")}
+		})
+
+		It("uses the service store", func() {
+			req := httptest.NewRequest("GET", "/bottles", nil)
+			rw := httptest.NewRecorder()
+			ctx := goa.NewContext(service.Context, rw, req, nil)
+			Ω(h(ctx, goa.ContextResponse(ctx), req)).ShouldNot(HaveOccurred())
+			e, err := store.Get(context.Background(), "bottle.list:/bottles?")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(e).ShouldNot(BeNil())
+			Ω(e.Vary).Should(Equal([]string{"Accept"}))
+		})
+	})
+})
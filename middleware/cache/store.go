@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	// Store records the cached responses. Implementations backed by a shared database make it
+	// possible to share the cache across multiple instances of a service. Implementations must
+	// be safe for concurrent use.
+	Store interface {
+		// Get returns the entry stored under key, nil if there is none or if it expired.
+		Get(ctx context.Context, key string) (*Entry, error)
+		// Set stores the entry under key for the given time.
+		Set(ctx context.Context, key string, e *Entry, ttl time.Duration) error
+	}
+
+	// Entry is a cached response.
+	Entry struct {
+		// Status is the response status code.
+		Status int
+		// Header contains the response headers set by the handler.
+		Header http.Header
+		// Body is the encoded response body.
+		Body []byte
+		// Vary lists the names of the request headers that select the response, it is only
+		// set on the entries that index the responses of a request path and query.
+		Vary []string
+		// Created is the time the response was stored.
+		Created time.Time
+	}
+
+	// memoryStore is a Store that keeps the entries in memory and evicts the least recently
+	// used entries when full.
+	memoryStore struct {
+		mu      sync.Mutex
+		size    int
+		entries map[string]*list.Element
+		lru     *list.List
+	}
+
+	// memoryEntry is an entry of a memory store.
+	memoryEntry struct {
+		key     string
+		entry   *Entry
+		expires time.Time
+	}
+)
+
+// DefaultMemoryStoreSize is the number of entries held by the stores created with
+// NewMemoryStore when given a non positive size.
+const DefaultMemoryStoreSize = 1000
+
+// NewMemoryStore returns a Store that keeps at most size entries in memory. The least recently
+// used entries are evicted when the store is full. The cache is not shared across service
+// instances.
+func NewMemoryStore(size int) Store {
+	if size <= 0 {
+		size = DefaultMemoryStoreSize
+	}
+	return &memoryStore{size: size, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+// Get implements Store.
+func (s *memoryStore) Get(_ context.Context, key string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	me := el.Value.(*memoryEntry)
+	if time.Now().After(me.expires) {
+		s.remove(el)
+		return nil, nil
+	}
+	s.lru.MoveToFront(el)
+	return me.entry, nil
+}
+
+// Set implements Store.
+func (s *memoryStore) Set(_ context.Context, key string, e *Entry, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	me := &memoryEntry{key: key, entry: e, expires: time.Now().Add(ttl)}
+	if el, ok := s.entries[key]; ok {
+		el.Value = me
+		s.lru.MoveToFront(el)
+		return nil
+	}
+	s.entries[key] = s.lru.PushFront(me)
+	for s.lru.Len() > s.size {
+		s.remove(s.lru.Back())
+	}
+	return nil
+}
+
+// remove deletes the given element. s.mu must be held.
+func (s *memoryStore) remove(el *list.Element) {
+	s.lru.Remove(el)
+	delete(s.entries, el.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,43 @@
+package cache_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/goadesign/goa/middleware/cache"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MemoryStore", func() {
+	var store cache.Store
+	var ctx context.Context
+
+	BeforeEach(func() {
+		store = cache.NewMemoryStore(2)
+		ctx = context.Background()
+	})
+
+	It("returns the stored entries", func() {
+		e := &cache.Entry{Status: 200, Body: []byte("a")}
+		Ω(store.Set(ctx, "a", e, time.Minute)).ShouldNot(HaveOccurred())
+		Ω(store.Get(ctx, "a")).Should(Equal(e))
+		Ω(store.Get(ctx, "b")).Should(BeNil())
+	})
+
+	It("expires the entries", func() {
+		store.Set(ctx, "a", &cache.Entry{Status: 200}, time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+		Ω(store.Get(ctx, "a")).Should(BeNil())
+	})
+
+	It("evicts the least recently used entries", func() {
+		store.Set(ctx, "a", &cache.Entry{Status: 200}, time.Minute)
+		store.Set(ctx, "b", &cache.Entry{Status: 200}, time.Minute)
+		store.Get(ctx, "a")
+		store.Set(ctx, "c", &cache.Entry{Status: 200}, time.Minute)
+		Ω(store.Get(ctx, "a")).ShouldNot(BeNil())
+		Ω(store.Get(ctx, "b")).Should(BeNil())
+		Ω(store.Get(ctx, "c")).ShouldNot(BeNil())
+	})
+})
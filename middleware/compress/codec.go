@@ -0,0 +1,128 @@
+package compress
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+	"sync"
+)
+
+type (
+	// WriterFunc returns a writer that compresses the data written to it and writes the result
+	// to w.
+	WriterFunc func(w io.Writer) (io.WriteCloser, error)
+
+	// ReaderFunc returns a reader that decompresses the data read from r.
+	ReaderFunc func(r io.Reader) (io.ReadCloser, error)
+
+	// codec is a registered content coding.
+	codec struct {
+		name      string
+		newWriter WriterFunc
+		newReader ReaderFunc
+	}
+)
+
+var (
+	// codecsMu protects codecs.
+	codecsMu sync.RWMutex
+	// codecs lists the registered content codings in order of preference.
+	codecs []*codec
+)
+
+func init() {
+	Register("gzip", func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	}, func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	})
+	// The "deflate" content coding is the zlib format (RFC 7230 section 4.2.2).
+	Register("deflate", func(w io.Writer) (io.WriteCloser, error) {
+		return zlib.NewWriter(w), nil
+	}, zlib.NewReader)
+}
+
+// Register registers the content coding with the given name, e.g. "br". The middlewares use the
+// registered codings in registration order when the request "Accept-Encoding" header gives them
+// the same preference. Registering a coding that already exists replaces its functions but keeps
+// its order, for example to change the gzip compression level:
+//
+//	compress.Register("gzip", func(w io.Writer) (io.WriteCloser, error) {
+//		return gzip.NewWriterLevel(w, gzip.BestSpeed)
+//	}, func(r io.Reader) (io.ReadCloser, error) {
+//		return gzip.NewReader(r)
+//	})
+//
+// The writers returned by w should implement a "Flush() error" method so that flushed responses,
+// e.g. event streams, are sent to the client right away. Register is safe for concurrent use.
+func Register(name string, w WriterFunc, r ReaderFunc) {
+	name = strings.ToLower(name)
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	for _, c := range codecs {
+		if c.name == name {
+			c.newWriter, c.newReader = w, r
+			return
+		}
+	}
+	codecs = append(codecs, &codec{name: name, newWriter: w, newReader: r})
+}
+
+// lookup returns the registered coding with the given name, nil if there is none.
+func lookup(name string) *codec {
+	name = strings.ToLower(strings.TrimSpace(name))
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	for _, c := range codecs {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// negotiate returns the registered coding with the highest quality in the given "Accept-Encoding"
+// header value, nil if none is acceptable. The quality of a coding not listed in the header is the
+// quality of "*" if present, 0 otherwise.
+func negotiate(acceptEncoding string) *codec {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, q := parseCoding(part)
+		if name != "" {
+			qualities[name] = q
+		}
+	}
+	wildcard, hasWildcard := qualities["*"]
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	var (
+		best  *codec
+		bestQ float64
+	)
+	for _, c := range codecs {
+		q, ok := qualities[c.name]
+		if !ok && hasWildcard {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = c, q
+		}
+	}
+	return best
+}
+
+// parseCoding returns the lower case name and the quality of an element of an "Accept-Encoding"
+// header, e.g. "gzip;q=0.8".
+func parseCoding(part string) (string, float64) {
+	elems := strings.Split(part, ";")
+	name := strings.ToLower(strings.TrimSpace(elems[0]))
+	q := 1.0
+	for _, param := range elems[1:] {
+		param = strings.TrimSpace(param)
+		if strings.HasPrefix(param, "q=") || strings.HasPrefix(param, "Q=") {
+			q = parseQuality(param[2:])
+		}
+	}
+	return name, q
+}
@@ -0,0 +1,265 @@
+/*
+Package compress provides a middleware that compresses the responses with the content coding
+preferred by the client and a handler that decompresses the request bodies.
+
+The gzip and deflate content codings are registered by default, use Register to add others. The
+middleware selects the registered coding with the highest quality value in the request
+"Accept-Encoding" header and only compresses responses whose content type is compressible and whose
+body is larger than a minimum size:
+
+	service.Use(compress.New(compress.WithMinSize(1024)))
+
+Request bodies are decoded before the action payloads are unmarshaled, that is before the
+middleware chain runs. Decoding is thus enabled on the service HTTP server handler rather than
+with a middleware:
+
+	compress.DecodeRequests(service, compress.WithMaxDecodedSize(100<<20))
+
+The client package Client ContentEncoding field compresses the bodies of the requests made by the
+generated clients.
+*/
+package compress
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa"
+)
+
+type (
+	// Option configures the middleware created by New and the handler created by Handler.
+	Option func(*options)
+
+	options struct {
+		minSize        int
+		contentTypes   []string
+		maxDecodedSize int64
+	}
+
+	// compressWriter compresses the response body if the response is compressible.
+	compressWriter struct {
+		http.ResponseWriter
+		codec  *codec
+		o      *options
+		status int
+		buf    bytes.Buffer
+		cw     io.WriteCloser
+		// decided is true once the writer has decided whether to compress the response.
+		decided  bool
+		compress bool
+	}
+)
+
+const (
+	// DefaultMinSize is the default minimum size of the compressed response bodies.
+	DefaultMinSize = 256
+	// DefaultMaxDecodedSize is the default maximum size of the decompressed request bodies.
+	DefaultMaxDecodedSize = 32 << 20
+)
+
+// defaultContentTypes lists the prefixes of the content types compressed by default. Content
+// types with a "+json" or "+xml" structured syntax suffix are compressed as well.
+var defaultContentTypes = []string{
+	"application/javascript",
+	"application/json",
+	"application/vnd.",
+	"application/xml",
+	"image/svg+xml",
+	"text/",
+}
+
+// New returns a middleware that compresses the response body with the registered content coding
+// that has the highest quality value in the request "Accept-Encoding" header. Responses are only
+// compressed if their status code is 2xx, if their content type is compressible and if their body
+// is at least DefaultMinSize bytes long unless overridden with options. Responses that already
+// have a "Content-Encoding" header, responses to HEAD and range requests and WebSocket upgrades
+// are not modified.
+func New(opts ...Option) goa.Middleware {
+	o := newOptions(opts)
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			resp := goa.ContextResponse(ctx)
+			if resp == nil || req.Method == "HEAD" || req.Header.Get("Range") != "" ||
+				req.Header.Get("Sec-WebSocket-Key") != "" {
+				return h(ctx, rw, req)
+			}
+			c := negotiate(req.Header.Get("Accept-Encoding"))
+			if c == nil {
+				return h(ctx, rw, req)
+			}
+			resp.Header().Add("Vary", "Accept-Encoding")
+			w := &compressWriter{ResponseWriter: resp.SwitchWriter(nil), codec: c, o: o}
+			resp.SwitchWriter(w)
+			err := h(ctx, rw, req)
+			resp.SwitchWriter(w.ResponseWriter)
+			if cerr := w.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
+			return err
+		}
+	}
+}
+
+// WithMinSize sets the minimum size of the compressed response bodies.
+func WithMinSize(n int) Option {
+	return func(o *options) {
+		o.minSize = n
+	}
+}
+
+// WithContentTypes sets the prefixes of the compressible content types, e.g. "text/" or
+// "application/json". Content types with a "+json" or "+xml" suffix are always compressible.
+func WithContentTypes(prefixes ...string) Option {
+	return func(o *options) {
+		o.contentTypes = prefixes
+	}
+}
+
+// WithMaxDecodedSize sets the maximum size of the decompressed request bodies.
+func WithMaxDecodedSize(n int64) Option {
+	return func(o *options) {
+		o.maxDecodedSize = n
+	}
+}
+
+// newOptions returns the options with the defaults overridden by opts.
+func newOptions(opts []Option) *options {
+	o := &options{
+		minSize:        DefaultMinSize,
+		contentTypes:   defaultContentTypes,
+		maxDecodedSize: DefaultMaxDecodedSize,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// compressible returns true if responses with the given content type and status code should be
+// compressed.
+func (o *options) compressible(contentType string, status int) bool {
+	if status < 200 || status > 299 || status == http.StatusNoContent {
+		return false
+	}
+	ct := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if strings.HasSuffix(ct, "+json") || strings.HasSuffix(ct, "+xml") {
+		return true
+	}
+	for _, prefix := range o.contentTypes {
+		if strings.HasPrefix(ct, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteHeader records the status code, the header is written with the first bytes of the body.
+func (w *compressWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write buffers the body until it is large enough to decide whether to compress it.
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", http.DetectContentType(b))
+	}
+	if w.decided {
+		if w.compress {
+			return w.cw.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	n, _ := w.buf.Write(b)
+	if w.buf.Len() < w.o.minSize {
+		return n, nil
+	}
+	return n, w.decide()
+}
+
+// decide writes the header and the buffered body, compressing it if the response is
+// compressible.
+func (w *compressWriter) decide() error {
+	w.decided = true
+	header := w.Header()
+	w.compress = header.Get("Content-Encoding") == "" &&
+		w.buf.Len() >= w.o.minSize &&
+		w.o.compressible(header.Get("Content-Type"), w.status)
+	if !w.compress {
+		w.ResponseWriter.WriteHeader(w.status)
+		_, err := w.ResponseWriter.Write(w.buf.Bytes())
+		return err
+	}
+	cw, err := w.codec.newWriter(w.ResponseWriter)
+	if err != nil {
+		return err
+	}
+	w.cw = cw
+	header.Set("Content-Encoding", w.codec.name)
+	header.Del("Content-Length")
+	header.Del("Accept-Ranges")
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err = w.cw.Write(w.buf.Bytes())
+	return err
+}
+
+// Flush implements http.Flusher so that streamed responses such as event streams reach the client
+// as they are written. Flush decides whether to compress the response with the data written so
+// far, flushes the compressor if the codec writer has a Flush method and flushes the underlying
+// writer.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		if err := w.decide(); err != nil {
+			return
+		}
+	}
+	if f, ok := w.cw.(interface{ Flush() error }); ok && w.compress {
+		if err := f.Flush(); err != nil {
+			return
+		}
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close writes the buffered body if the writer has not decided whether to compress it yet and
+// flushes the compressor.
+func (w *compressWriter) Close() error {
+	if w.status == 0 {
+		return nil
+	}
+	if !w.decided {
+		if err := w.decide(); err != nil {
+			return err
+		}
+	}
+	if w.compress {
+		return w.cw.Close()
+	}
+	return nil
+}
+
+// parseQuality parses a quality value, invalid values are 0.
+func parseQuality(s string) float64 {
+	q, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || q < 0 {
+		return 0
+	}
+	if q > 1 {
+		return 1
+	}
+	return q
+}
@@ -0,0 +1,246 @@
+package compress_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/compress"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var body, contentType string
+	var opts []compress.Option
+
+	BeforeEach(func() {
+		body = strings.Repeat("bottle ", 100)
+		contentType = "application/json"
+		opts = nil
+	})
+
+	send := func(acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rw := httptest.NewRecorder()
+		ctx := goa.NewContext(context.Background(), rw, req, nil)
+		h := compress.New(opts...)(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			rw.Header().Set("Content-Type", contentType)
+			rw.WriteHeader(200)
+			_, err := rw.Write([]byte(body))
+			return err
+		})
+		Ω(h(ctx, goa.ContextResponse(ctx), req)).ShouldNot(HaveOccurred())
+		return rw
+	}
+
+	decode := func(rw *httptest.ResponseRecorder) string {
+		var r io.Reader
+		var err error
+		switch rw.Header().Get("Content-Encoding") {
+		case "gzip":
+			r, err = gzip.NewReader(rw.Body)
+		case "deflate":
+			r, err = zlib.NewReader(rw.Body)
+		default:
+			return rw.Body.String()
+		}
+		Ω(err).ShouldNot(HaveOccurred())
+		b, err := ioutil.ReadAll(r)
+		Ω(err).ShouldNot(HaveOccurred())
+		return string(b)
+	}
+
+	It("compresses with gzip", func() {
+		rw := send("gzip")
+		Ω(rw.Code).Should(Equal(200))
+		Ω(rw.Header().Get("Content-Encoding")).Should(Equal("gzip"))
+		Ω(rw.Header().Get("Vary")).Should(Equal("Accept-Encoding"))
+		Ω(decode(rw)).Should(Equal(body))
+	})
+
+	It("selects the coding with the highest quality", func() {
+		rw := send("gzip;q=0.5, deflate")
+		Ω(rw.Header().Get("Content-Encoding")).Should(Equal("deflate"))
+		Ω(decode(rw)).Should(Equal(body))
+	})
+
+	It("uses the registration order for codings of equal quality", func() {
+		rw := send("*")
+		Ω(rw.Header().Get("Content-Encoding")).Should(Equal("gzip"))
+	})
+
+	It("excludes codings with a zero quality", func() {
+		rw := send("gzip;q=0, *;q=0.1")
+		Ω(rw.Header().Get("Content-Encoding")).Should(Equal("deflate"))
+		rw = send("gzip;q=0")
+		Ω(rw.Header().Get("Content-Encoding")).Should(BeEmpty())
+		Ω(rw.Body.String()).Should(Equal(body))
+	})
+
+	It("does not compress small bodies", func() {
+		body = "bottle"
+		rw := send("gzip")
+		Ω(rw.Header().Get("Content-Encoding")).Should(BeEmpty())
+		Ω(rw.Body.String()).Should(Equal("bottle"))
+	})
+
+	It("does not compress incompressible content types", func() {
+		contentType = "image/png"
+		rw := send("gzip")
+		Ω(rw.Header().Get("Content-Encoding")).Should(BeEmpty())
+		Ω(rw.Body.String()).Should(Equal(body))
+	})
+
+	Context("with an event stream", func() {
+		var event = map[string]string{"name": strings.Repeat("bottle", 10)}
+		var frame = `data: {"name":"` + strings.Repeat("bottle", 10) + `"}` + "\n\n"
+
+		stream := func(check func(rw *httptest.ResponseRecorder)) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			rw := httptest.NewRecorder()
+			ctx := goa.NewContext(context.Background(), rw, req, nil)
+			h := compress.New(opts...)(func(ctx context.Context, _ http.ResponseWriter, req *http.Request) error {
+				s := goa.NewEventStream(goa.ContextResponse(ctx))
+				Ω(s.Send(event)).ShouldNot(HaveOccurred())
+				check(rw)
+				return s.Close()
+			})
+			Ω(h(ctx, goa.ContextResponse(ctx), req)).ShouldNot(HaveOccurred())
+			return rw
+		}
+
+		It("flushes the events that are too small to be compressed", func() {
+			rw := stream(func(rw *httptest.ResponseRecorder) {
+				Ω(rw.Flushed).Should(BeTrue())
+				Ω(rw.Body.String()).Should(Equal(frame))
+			})
+			Ω(rw.Header().Get("Content-Encoding")).Should(BeEmpty())
+		})
+
+		It("flushes the compressed events", func() {
+			opts = []compress.Option{compress.WithMinSize(1)}
+			rw := stream(func(rw *httptest.ResponseRecorder) {
+				Ω(rw.Flushed).Should(BeTrue())
+				r, err := gzip.NewReader(bytes.NewReader(rw.Body.Bytes()))
+				Ω(err).ShouldNot(HaveOccurred())
+				b := make([]byte, len(frame))
+				_, err = io.ReadFull(r, b)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(b)).Should(Equal(frame))
+			})
+			Ω(rw.Header().Get("Content-Encoding")).Should(Equal("gzip"))
+			Ω(decode(rw)).Should(Equal(frame))
+		})
+	})
+
+	It("compresses structured syntax suffixes", func() {
+		contentType = "application/problem+json"
+		opts = []compress.Option{compress.WithContentTypes("text/")}
+		rw := send("gzip")
+		Ω(rw.Header().Get("Content-Encoding")).Should(Equal("gzip"))
+	})
+})
+
+var _ = Describe("Handler", func() {
+	var service *goa.Service
+	var received string
+	var h http.Handler
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		received = ""
+		h = compress.Handler(service, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			b, _ := ioutil.ReadAll(req.Body)
+			received = string(b)
+			Ω(req.ContentLength).Should(BeEquivalentTo(len(b)))
+			Ω(req.Header.Get("Content-Encoding")).Should(BeEmpty())
+		}), compress.WithMaxDecodedSize(1000))
+	})
+
+	send := func(encoding, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Encoding", encoding)
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, req)
+		return rw
+	}
+
+	gzipped := func(s string) string {
+		var b strings.Builder
+		w := gzip.NewWriter(&b)
+		w.Write([]byte(s))
+		w.Close()
+		return b.String()
+	}
+
+	It("decompresses the request bodies", func() {
+		rw := send("gzip", gzipped(`{"name":"bottle"}`))
+		Ω(rw.Code).Should(Equal(200))
+		Ω(received).Should(Equal(`{"name":"bottle"}`))
+	})
+
+	It("rejects unsupported codings", func() {
+		rw := send("br", "data")
+		Ω(rw.Code).Should(Equal(415))
+		Ω(rw.Header().Get("Content-Type")).Should(Equal(goa.ErrorMediaIdentifier))
+	})
+
+	It("rejects invalid bodies", func() {
+		rw := send("gzip", "not gzip")
+		Ω(rw.Code).Should(Equal(400))
+	})
+
+	It("rejects bodies that are too large once decompressed", func() {
+		rw := send("gzip", gzipped(strings.Repeat("a", 1001)))
+		Ω(rw.Code).Should(Equal(413))
+		Ω(received).Should(BeEmpty())
+	})
+})
+
+var _ = Describe("Register", func() {
+	It("makes the coding available to the middleware", func() {
+		compress.Register("x-upper", func(w io.Writer) (io.WriteCloser, error) {
+			return upperWriter{w}, nil
+		}, func(r io.Reader) (io.ReadCloser, error) {
+			return ioutil.NopCloser(r), nil
+		})
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", "x-upper")
+		rw := httptest.NewRecorder()
+		ctx := goa.NewContext(context.Background(), rw, req, nil)
+		h := compress.New(compress.WithMinSize(1))(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			rw.Header().Set("Content-Type", "text/plain")
+			_, err := rw.Write([]byte("bottle"))
+			return err
+		})
+		Ω(h(ctx, goa.ContextResponse(ctx), req)).ShouldNot(HaveOccurred())
+		Ω(rw.Header().Get("Content-Encoding")).Should(Equal("x-upper"))
+		Ω(rw.Body.String()).Should(Equal("BOTTLE"))
+	})
+})
+
+// upperWriter is a test coding that upper cases the data.
+type upperWriter struct {
+	w io.Writer
+}
+
+func (u upperWriter) Write(b []byte) (int, error) {
+	return u.w.Write([]byte(strings.ToUpper(string(b))))
+}
+
+func (u upperWriter) Close() error {
+	return nil
+}
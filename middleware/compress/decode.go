@@ -0,0 +1,77 @@
+package compress
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa"
+)
+
+// Handler returns a HTTP handler that decompresses the bodies of the requests that have a
+// "Content-Encoding" header using the registered content codings before calling h. The request
+// "Content-Encoding" header is removed and its "Content-Length" header is set to the size of the
+// decompressed body.
+//
+// Requests that use an unknown coding get a 415 response, requests whose body cannot be
+// decompressed get a 400 response and requests whose decompressed body is larger than
+// DefaultMaxDecodedSize bytes (or the size given to WithMaxDecodedSize) get a 413 response. The
+// errors are sent using the service encoder.
+func Handler(service *goa.Service, h http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		encoding := req.Header.Get("Content-Encoding")
+		if encoding == "" || strings.EqualFold(encoding, "identity") || req.Body == nil {
+			h.ServeHTTP(rw, req)
+			return
+		}
+		if err := decodeBody(req, encoding, o.maxDecodedSize); err != nil {
+			ctx := goa.NewContext(service.Context, rw, req, nil)
+			rw.Header().Set("Content-Type", goa.ErrorMediaIdentifier)
+			service.Send(ctx, err.(goa.ServiceError).ResponseStatus(), err)
+			return
+		}
+		h.ServeHTTP(rw, req)
+	})
+}
+
+// DecodeRequests wraps the handler of the service HTTP server with Handler so that the service
+// accepts compressed request bodies. DecodeRequests must be called after UseMux if the service
+// uses a custom mux.
+func DecodeRequests(service *goa.Service, opts ...Option) {
+	service.Server.Handler = Handler(service, service.Server.Handler, opts...)
+}
+
+// decodeBody replaces the request body with its decompressed content. The errors returned by
+// decodeBody implement goa.ServiceError.
+func decodeBody(req *http.Request, encoding string, max int64) error {
+	c := lookup(encoding)
+	if c == nil {
+		msg := fmt.Sprintf("unsupported content encoding %q", encoding)
+		return goa.ErrUnsupportedEncoding(msg, "encoding", encoding)
+	}
+	defer req.Body.Close()
+	r, err := c.newReader(req.Body)
+	if err != nil {
+		return goa.ErrInvalidEncoding(err)
+	}
+	defer r.Close()
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(r, max+1))
+	if err != nil {
+		return goa.ErrInvalidEncoding(err)
+	}
+	if n > max {
+		msg := fmt.Sprintf("decompressed request body length exceeds %d bytes", max)
+		return goa.ErrRequestBodyTooLarge(msg)
+	}
+	req.Body = ioutil.NopCloser(&buf)
+	req.ContentLength = n
+	req.Header.Del("Content-Encoding")
+	req.Header.Set("Content-Length", strconv.FormatInt(n, 10))
+	return nil
+}
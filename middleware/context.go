@@ -12,4 +12,7 @@ const (
 	traceKey
 	spanKey
 	parentSpanKey
+	sampledKey
+	traceStateKey
+	propagatorKey
 )
@@ -14,6 +14,8 @@ import (
 // understands instances of goa.ServiceError and returns the status and response body embodied in
 // them, it turns other Go error types into a 500 internal error response.
 // If verbose is false the details of internal errors is not included in HTTP responses.
+// Errors are rendered as RFC 7807 problem details documents if the service ProblemDetails field
+// is true.
 // If you use github.com/pkg/errors then wrapping the error will allow a trace to be printed to the logs
 func ErrorHandler(service *goa.Service, verbose bool) goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
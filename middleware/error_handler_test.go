@@ -161,6 +161,22 @@ var _ = Describe("ErrorHandler", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(decoded.Error()).Should(Equal(gerr.Error()))
 		})
+
+		Context("with problem details", func() {
+			BeforeEach(func() {
+				service.ProblemDetails = true
+			})
+
+			It("renders goa errors as problem details documents", func() {
+				Ω(rw.Status).Should(Equal(418))
+				Ω(rw.ParentHeader["Content-Type"]).Should(Equal([]string{goa.ProblemMediaIdentifier}))
+				decoded, err := service.Decoder.DecodeError(bytes.NewBuffer(rw.Body), goa.ProblemMediaIdentifier)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(decoded.Error()).Should(Equal(gerr.Error()))
+				Ω(string(rw.Body)).Should(ContainSubstring(`"type":"about:blank","title":"I'm a teapot","status":418`))
+				Ω(string(rw.Body)).Should(ContainSubstring(`"instance":"/foo"`))
+			})
+		})
 	})
 
 	Context("with a handler returning a pkg errors wrapped error", func() {
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/goadesign/goa"
+)
+
+// etagWriter buffers the response so that its entity tag can be computed before it is sent.
+type etagWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// ETag returns a middleware that sets the "ETag" header of the successful responses to GET and
+// HEAD requests to the strong entity tag of the response body, see goa.NewETag. Handlers may set
+// the header themselves, for example to derive the tag from the version of the resource, in which
+// case the middleware uses the given value.
+//
+// The middleware replies with a 304 Not Modified response without body to requests whose
+// "If-None-Match" header matches the entity tag or, if the request does not have a
+// "If-None-Match" header, whose "If-Modified-Since" header is not older than the response
+// "Last-Modified" header set by the handler.
+//
+// The middleware buffers the response body. Requests that use other methods are not modified,
+// actions that modify resources use goa.CheckIfMatch to honor "If-Match" preconditions.
+func ETag() goa.Middleware {
+	return etag(false)
+}
+
+// WeakETag returns a middleware that behaves like ETag but that produces weak entity tags.
+func WeakETag() goa.Middleware {
+	return etag(true)
+}
+
+// etag implements ETag and WeakETag.
+func etag(weak bool) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			resp := goa.ContextResponse(ctx)
+			if resp == nil || (req.Method != "GET" && req.Method != "HEAD") {
+				return h(ctx, rw, req)
+			}
+			ew := &etagWriter{header: resp.Header()}
+			rwo := resp.SwitchWriter(ew)
+			err := h(ctx, rw, req)
+			resp.SwitchWriter(rwo)
+			if err == nil && ew.status == http.StatusOK {
+				tag := ew.header.Get("ETag")
+				if tag == "" {
+					tag = goa.NewETag(ew.body.Bytes(), weak)
+					ew.header.Set("ETag", tag)
+				}
+				if notModified(req, ew.header, tag) {
+					ew.header.Del("Content-Type")
+					ew.header.Del("Content-Length")
+					resp.Status = http.StatusNotModified
+					resp.Length = 0
+					rwo.WriteHeader(http.StatusNotModified)
+					return nil
+				}
+			}
+			if ew.status != 0 {
+				rwo.WriteHeader(ew.status)
+				if _, werr := rwo.Write(ew.body.Bytes()); werr != nil && err == nil {
+					err = werr
+				}
+			}
+			return err
+		}
+	}
+}
+
+// notModified returns true if the request conditional headers indicate that the client already
+// has the response with the given entity tag.
+func notModified(req *http.Request, header http.Header, tag string) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		return goa.MatchETag(inm, tag, false)
+	}
+	ims, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lm, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return !lm.After(ims)
+}
+
+// Header returns the header of the underlying response writer.
+func (w *etagWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader records the response status code.
+func (w *etagWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write buffers the response body.
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
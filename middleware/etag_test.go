@@ -0,0 +1,95 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ETag", func() {
+	var service *goa.Service
+	var req *http.Request
+	var rw *testResponseWriter
+	var ctx context.Context
+	var status int
+	var lastModified string
+	var mw goa.Middleware
+
+	BeforeEach(func() {
+		service = newService(nil)
+		req, _ = http.NewRequest("GET", "/bottles/1", nil)
+		rw = newTestResponseWriter()
+		status = http.StatusOK
+		lastModified = ""
+		mw = middleware.ETag()
+	})
+
+	serve := func() error {
+		ctx = newContext(service, rw, req, nil)
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if lastModified != "" {
+				rw.Header().Set("Last-Modified", lastModified)
+			}
+			return service.Send(ctx, status, map[string]int{"id": 1})
+		}
+		return mw(h)(ctx, goa.ContextResponse(ctx), req)
+	}
+
+	It("sets the ETag header of successful responses", func() {
+		Ω(serve()).ShouldNot(HaveOccurred())
+		Ω(rw.Status).Should(Equal(http.StatusOK))
+		Ω(string(rw.Body)).Should(Equal("{\"id\":1}\n"))
+		Ω(rw.Header().Get("ETag")).Should(Equal(goa.NewETag(rw.Body, false)))
+	})
+
+	It("sets weak tags", func() {
+		mw = middleware.WeakETag()
+		Ω(serve()).ShouldNot(HaveOccurred())
+		Ω(rw.Header().Get("ETag")).Should(HavePrefix("W/"))
+	})
+
+	It("does not tag other responses", func() {
+		status = http.StatusNotFound
+		Ω(serve()).ShouldNot(HaveOccurred())
+		Ω(rw.Status).Should(Equal(http.StatusNotFound))
+		Ω(rw.Header().Get("ETag")).Should(BeEmpty())
+		Ω(rw.Body).ShouldNot(BeEmpty())
+	})
+
+	It("replies with 304 if If-None-Match matches", func() {
+		serve()
+		req.Header.Set("If-None-Match", `"other", `+rw.Header().Get("ETag"))
+		rw = newTestResponseWriter()
+		Ω(serve()).ShouldNot(HaveOccurred())
+		Ω(rw.Status).Should(Equal(http.StatusNotModified))
+		Ω(rw.Body).Should(BeEmpty())
+		Ω(rw.Header().Get("ETag")).ShouldNot(BeEmpty())
+		Ω(goa.ContextResponse(ctx).Status).Should(Equal(http.StatusNotModified))
+	})
+
+	It("sends the body if If-None-Match does not match", func() {
+		req.Header.Set("If-None-Match", `"other"`)
+		Ω(serve()).ShouldNot(HaveOccurred())
+		Ω(rw.Status).Should(Equal(http.StatusOK))
+		Ω(rw.Body).ShouldNot(BeEmpty())
+	})
+
+	It("replies with 304 if the response was not modified since If-Modified-Since", func() {
+		now := time.Now().UTC()
+		lastModified = now.Add(-time.Hour).Format(http.TimeFormat)
+		req.Header.Set("If-Modified-Since", now.Format(http.TimeFormat))
+		Ω(serve()).ShouldNot(HaveOccurred())
+		Ω(rw.Status).Should(Equal(http.StatusNotModified))
+	})
+
+	It("does not buffer the responses to other methods", func() {
+		req.Method = "PUT"
+		Ω(serve()).ShouldNot(HaveOccurred())
+		Ω(rw.Header().Get("ETag")).Should(BeEmpty())
+	})
+})
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
@@ -19,7 +20,8 @@ import (
 // LogRequest creates a request logger middleware.
 // This middleware is aware of the RequestID middleware and if registered after it leverages the
 // request ID for logging.
-// If verbose is true then the middlware logs the request and response bodies.
+// If verbose is true then the middlware logs the request and response bodies. The sensitive
+// attributes of payloads that implement goa.Redactor are masked, see the Sensitive DSL.
 func LogRequest(verbose bool, sensitiveHeaders ...string) goa.Middleware {
 	var suppressed map[string]struct{}
 	if len(sensitiveHeaders) > 0 {
@@ -74,7 +76,8 @@ func LogRequest(verbose bool, sensitiveHeaders ...string) goa.Middleware {
 					goa.LogInfo(ctx, "params", logCtx...)
 				}
 				if r.ContentLength > 0 {
-					if mp, ok := r.Payload.(map[string]interface{}); ok {
+					payload := goa.Redact(r.Payload)
+					if mp, ok := payload.(map[string]interface{}); ok {
 						logCtx := make([]interface{}, 2*len(mp))
 						i := 0
 						for k, v := range mp {
@@ -85,11 +88,7 @@ func LogRequest(verbose bool, sensitiveHeaders ...string) goa.Middleware {
 						goa.LogInfo(ctx, "payload", logCtx...)
 					} else {
 						// Not the most efficient but this is used for debugging
-						js, err := json.Marshal(r.Payload)
-						if err != nil {
-							js = []byte("<invalid JSON>")
-						}
-						goa.LogInfo(ctx, "payload", "raw", string(js))
+						goa.LogInfo(ctx, "payload", "raw", logJSON(payload))
 					}
 				}
 			}
@@ -109,6 +108,18 @@ func LogRequest(verbose bool, sensitiveHeaders ...string) goa.Middleware {
 	}
 }
 
+// logJSON returns the JSON representation of v or "<invalid JSON>" if v cannot be serialized.
+// HTML characters are not escaped so that the redacted values remain readable.
+func logJSON(v interface{}) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "<invalid JSON>"
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
 // shortID produces a "unique" 6 bytes long string.
 // Do not use as a reliable way to get unique IDs, instead use for things like logging.
 func shortID() string {
@@ -124,4 +124,17 @@ var _ = Describe("LogRequest", func() {
 		Ω(logger.InfoEntries[1].Data[5]).Should(Equal("<hidden>"))
 
 	})
+
+	It("redacts sensitive payload attributes", func() {
+		goa.ContextRequest(ctx).Payload = &credentials{User: "joe", Password: "secret"}
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return service.Send(ctx, 200, "ok")
+		}
+		lg := middleware.LogRequest(true)(h)
+		Ω(lg(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(logger.InfoEntries).Should(HaveLen(4))
+
+		Ω(logger.InfoEntries[2].Data[2]).Should(Equal("raw"))
+		Ω(logger.InfoEntries[2].Data[3]).Should(Equal(`{"user":"joe","password":"<redacted>"}`))
+	})
 })
@@ -14,16 +14,27 @@ import (
 type loggingResponseWriter struct {
 	http.ResponseWriter
 	ctx context.Context
+	// redacted is true once the redacted response body has been logged.
+	redacted bool
 }
 
-// Write will write raw data to logger and response writer.
+// Write will write raw data to logger and response writer. The redacted body is logged instead if
+// the body sent by the handler implements goa.Redactor.
 func (lrw *loggingResponseWriter) Write(buf []byte) (int, error) {
+	if r := goa.ContextResponse(lrw.ctx); r != nil && r.Redactor != nil {
+		if !lrw.redacted {
+			lrw.redacted = true
+			goa.LogInfo(lrw.ctx, "response", "body", logJSON(r.Redactor.Redact()))
+		}
+		return lrw.ResponseWriter.Write(buf)
+	}
 	goa.LogInfo(lrw.ctx, "response", "body", string(buf))
 	return lrw.ResponseWriter.Write(buf)
 }
 
 // LogResponse creates a response logger middleware.
-// Only Logs the raw response data without accumulating any statistics.
+// Only Logs the raw response data without accumulating any statistics. Response bodies that
+// implement goa.Redactor are logged as JSON with their sensitive attributes masked.
 func LogResponse() goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
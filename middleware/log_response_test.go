@@ -19,11 +19,12 @@ var _ = Describe("LogResponse", func() {
 	var req *http.Request
 	var rw http.ResponseWriter
 	var params url.Values
+	var service *goa.Service
 	responseText := "some response data to be logged"
 
 	BeforeEach(func() {
 		logger = new(testLogger)
-		service := newService(logger)
+		service = newService(logger)
 
 		var err error
 		req, err = http.NewRequest("POST", "/goo", strings.NewReader(`{"payload":42}`))
@@ -47,4 +48,19 @@ var _ = Describe("LogResponse", func() {
 		Ω(logger.InfoEntries[0].Data[0]).Should(Equal("body"))
 		Ω(logger.InfoEntries[0].Data[1]).Should(Equal(responseText))
 	})
+
+	It("redacts sensitive response attributes", func() {
+		rw = newTestResponseWriter()
+		ctx = newContext(service, rw, req, params)
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return service.Send(ctx, 200, &credentials{User: "joe", Password: "secret"})
+		}
+		lg := middleware.LogResponse()(h)
+		Ω(lg(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(logger.InfoEntries).Should(HaveLen(1))
+
+		Ω(logger.InfoEntries[0].Data).Should(HaveLen(2))
+		Ω(logger.InfoEntries[0].Data[1]).Should(Equal(`{"user":"joe","password":"<redacted>"}`))
+		Ω(string(rw.(*testResponseWriter).Body)).Should(ContainSubstring(`"password":"secret"`))
+	})
 })
@@ -29,6 +29,18 @@ type logEntry struct {
 	Data []interface{}
 }
 
+// credentials is a payload with a sensitive attribute.
+type credentials struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+func (c *credentials) Redact() interface{} {
+	res := *c
+	res.Password = goa.RedactedValue
+	return &res
+}
+
 type testLogger struct {
 	Context      []interface{}
 	InfoEntries  []logEntry
@@ -0,0 +1,264 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa/client"
+)
+
+var (
+	// TraceParentHeader is the name of the W3C Trace Context HTTP header containing the trace
+	// ID, the parent span ID and the sampling decision.
+	TraceParentHeader = "traceparent"
+
+	// TraceStateHeader is the name of the W3C Trace Context HTTP header containing the vendor
+	// specific trace information.
+	TraceStateHeader = "tracestate"
+)
+
+type (
+	// Propagator reads and writes the trace information carried by the HTTP request headers.
+	// Use the Propagation tracer option to select the propagator used by the tracer middleware
+	// and the trace doer.
+	Propagator interface {
+		// Extract returns the trace information carried by the given request headers. The
+		// SpanID field of the returned trace is the ID of the span of the caller. Extract
+		// returns false if the headers do not carry valid trace information.
+		Extract(h http.Header) (Trace, bool)
+		// Inject sets the headers that carry the given trace information in h.
+		Inject(h http.Header, t Trace)
+	}
+
+	// Trace is the trace information propagated across services.
+	Trace struct {
+		// TraceID is the ID of the trace.
+		TraceID string
+		// SpanID is the ID of the current span.
+		SpanID string
+		// Sampled is true if the trace is recorded.
+		Sampled bool
+		// State contains the vendor specific trace information, it is the value of the W3C
+		// "tracestate" header.
+		State string
+	}
+
+	// goaPropagator propagates the traces with the TraceIDHeader and ParentSpanIDHeader
+	// headers.
+	goaPropagator struct{}
+
+	// w3cPropagator propagates the traces with the W3C Trace Context headers.
+	w3cPropagator struct{}
+
+	// multiPropagator extracts the traces with the first propagator that succeeds and injects
+	// them with all the propagators.
+	multiPropagator []Propagator
+)
+
+// NewGoaPropagator returns the propagator used by default. It propagates the traces using the
+// TraceIDHeader and ParentSpanIDHeader headers. The presence of the trace ID header means that
+// the trace is sampled, unsampled traces are thus not propagated.
+func NewGoaPropagator() Propagator {
+	return goaPropagator{}
+}
+
+// NewW3CPropagator returns a propagator that uses the W3C Trace Context "traceparent" and
+// "tracestate" headers. Trace IDs produced by the xray package NewTraceID function are converted
+// to the W3C format when injected, trace and span IDs that cannot be converted are not
+// propagated.
+func NewW3CPropagator() Propagator {
+	return w3cPropagator{}
+}
+
+// NewMultiPropagator returns a propagator that extracts the traces using the first of the given
+// propagators that succeeds and that injects the traces with all of them. This makes it possible
+// to migrate services from a propagation format to another.
+func NewMultiPropagator(ps ...Propagator) Propagator {
+	return multiPropagator(ps)
+}
+
+// Propagation sets the propagator used by the tracer middleware to extract the incoming traces.
+// The propagator is also recorded in the request context so that TraceDoer injects the traces
+// of the outgoing requests using the same format.
+//
+// When a propagator is set the trace and span IDs are W3C compatible hexadecimal values by
+// default, and requests that are not sampled are given trace IDs as well so that the sampling
+// decision reaches the downstream services. Use ContextTraceSampled to check whether the
+// request trace is recorded.
+func Propagation(p Propagator) TracerOption {
+	if p == nil {
+		panic("propagator cannot be nil")
+	}
+	return func(o *tracerOptions) *tracerOptions {
+		o.propagator = p
+		return o
+	}
+}
+
+// NewTraceDoer wraps a goa client Doer and sets the trace headers using the given propagator,
+// regardless of the propagator recorded in the request context.
+func NewTraceDoer(doer client.Doer, p Propagator) client.Doer {
+	return &tracedDoer{Doer: doer, propagator: p}
+}
+
+// ContextTraceSampled returns true if the trace recorded in the given context is sampled. Traces
+// recorded with WithTrace only are always sampled.
+func ContextTraceSampled(ctx context.Context) bool {
+	if s, ok := ctx.Value(sampledKey).(bool); ok {
+		return s
+	}
+	return ContextTraceID(ctx) != ""
+}
+
+// ContextTraceState returns the W3C "tracestate" value extracted from the incoming request if
+// any, the empty string otherwise.
+func ContextTraceState(ctx context.Context) string {
+	if s := ctx.Value(traceStateKey); s != nil {
+		return s.(string)
+	}
+	return ""
+}
+
+// WithTraceSampling returns a context that records whether the trace is sampled and the W3C
+// "tracestate" value to propagate.
+func WithTraceSampling(ctx context.Context, sampled bool, state string) context.Context {
+	ctx = context.WithValue(ctx, sampledKey, sampled)
+	if state != "" {
+		ctx = context.WithValue(ctx, traceStateKey, state)
+	}
+	return ctx
+}
+
+// contextPropagator returns the propagator recorded in the context by the tracer middleware, the
+// goa propagator if there is none.
+func contextPropagator(ctx context.Context) Propagator {
+	if p, ok := ctx.Value(propagatorKey).(Propagator); ok {
+		return p
+	}
+	return goaPropagator{}
+}
+
+// Extract implements Propagator.
+func (goaPropagator) Extract(h http.Header) (Trace, bool) {
+	traceID := h.Get(TraceIDHeader)
+	if traceID == "" {
+		return Trace{}, false
+	}
+	return Trace{TraceID: traceID, SpanID: h.Get(ParentSpanIDHeader), Sampled: true}, true
+}
+
+// Inject implements Propagator.
+func (goaPropagator) Inject(h http.Header, t Trace) {
+	if !t.Sampled {
+		return
+	}
+	h.Set(TraceIDHeader, t.TraceID)
+	h.Set(ParentSpanIDHeader, t.SpanID)
+}
+
+// Extract implements Propagator.
+func (w3cPropagator) Extract(h http.Header) (Trace, bool) {
+	// version "-" trace-id "-" parent-id "-" trace-flags, future versions may append fields.
+	tp := strings.TrimSpace(h.Get(TraceParentHeader))
+	parts := strings.Split(tp, "-")
+	if len(parts) < 4 || !isHex(parts[0], 2) || parts[0] == "ff" ||
+		(parts[0] == "00" && len(parts) != 4) {
+		return Trace{}, false
+	}
+	traceID, spanID, flags := parts[1], parts[2], parts[3]
+	if !isHex(traceID, 32) || isZero(traceID) || !isHex(spanID, 16) || isZero(spanID) ||
+		!isHex(flags, 2) {
+		return Trace{}, false
+	}
+	f, _ := hex.DecodeString(flags)
+	state := strings.Join(h[http.CanonicalHeaderKey(TraceStateHeader)], ",")
+	return Trace{TraceID: traceID, SpanID: spanID, Sampled: f[0]&1 == 1, State: state}, true
+}
+
+// Inject implements Propagator.
+func (w3cPropagator) Inject(h http.Header, t Trace) {
+	traceID := w3cTraceID(t.TraceID)
+	if traceID == "" || !isHex(t.SpanID, 16) || isZero(t.SpanID) {
+		return
+	}
+	flags := "00"
+	if t.Sampled {
+		flags = "01"
+	}
+	h.Set(TraceParentHeader, fmt.Sprintf("00-%s-%s-%s", traceID, t.SpanID, flags))
+	if t.State != "" {
+		h.Set(TraceStateHeader, t.State)
+	} else {
+		h.Del(TraceStateHeader)
+	}
+}
+
+// Extract implements Propagator.
+func (m multiPropagator) Extract(h http.Header) (Trace, bool) {
+	for _, p := range m {
+		if t, ok := p.Extract(h); ok {
+			return t, true
+		}
+	}
+	return Trace{}, false
+}
+
+// Inject implements Propagator.
+func (m multiPropagator) Inject(h http.Header, t Trace) {
+	for _, p := range m {
+		p.Inject(h, t)
+	}
+}
+
+// w3cTraceID returns the W3C representation of the given trace ID. Trace IDs produced by the xray
+// package ("1-" 8 hex digits "-" 24 hex digits) are converted by removing the version and dashes.
+// w3cTraceID returns the empty string if the ID cannot be converted.
+func w3cTraceID(id string) string {
+	if parts := strings.Split(id, "-"); len(parts) == 3 && parts[0] == "1" &&
+		isHex(parts[1], 8) && isHex(parts[2], 24) {
+		id = parts[1] + parts[2]
+	}
+	if !isHex(id, 32) || isZero(id) {
+		return ""
+	}
+	return id
+}
+
+// newTraceID returns a random W3C trace ID.
+func newTraceID() string {
+	return randomHex(16)
+}
+
+// newSpanID returns a random W3C span ID, it is also a valid AWS X-Ray segment ID.
+func newSpanID() string {
+	return randomHex(8)
+}
+
+// randomHex returns the hexadecimal representation of n random bytes.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// isHex returns true if s is made of n lower case hexadecimal digits.
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// isZero returns true if s only contains zeros.
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestW3CPropagatorExtract(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	cases := map[string]struct {
+		TraceParent, TraceState string
+		// output
+		OK      bool
+		Sampled bool
+	}{
+		"sampled":        {"00-" + traceID + "-" + spanID + "-01", "", true, true},
+		"not-sampled":    {"00-" + traceID + "-" + spanID + "-00", "", true, false},
+		"state":          {"00-" + traceID + "-" + spanID + "-01", "congo=t61rcWkgMzE", true, true},
+		"future-version": {"cc-" + traceID + "-" + spanID + "-01-what", "", true, true},
+		"missing":        {"", "", false, false},
+		"invalid-ver":    {"ff-" + traceID + "-" + spanID + "-01", "", false, false},
+		"extra-field":    {"00-" + traceID + "-" + spanID + "-01-what", "", false, false},
+		"zero-trace":     {"00-00000000000000000000000000000000-" + spanID + "-01", "", false, false},
+		"zero-span":      {"00-" + traceID + "-0000000000000000-01", "", false, false},
+		"upper-case":     {"00-4BF92F3577B34DA6A3CE929D0E0E4736-" + spanID + "-01", "", false, false},
+		"short-span":     {"00-" + traceID + "-00f067aa-01", "", false, false},
+	}
+	for k, c := range cases {
+		h := make(http.Header)
+		if c.TraceParent != "" {
+			h.Set(TraceParentHeader, c.TraceParent)
+		}
+		if c.TraceState != "" {
+			h.Set(TraceStateHeader, c.TraceState)
+		}
+		tr, ok := NewW3CPropagator().Extract(h)
+		if ok != c.OK {
+			t.Errorf("%s: invalid result, expected %v - got %v", k, c.OK, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if tr.TraceID != traceID || tr.SpanID != spanID {
+			t.Errorf("%s: invalid IDs %q %q", k, tr.TraceID, tr.SpanID)
+		}
+		if tr.Sampled != c.Sampled {
+			t.Errorf("%s: invalid sampled flag, expected %v - got %v", k, c.Sampled, tr.Sampled)
+		}
+		if tr.State != c.TraceState {
+			t.Errorf("%s: invalid state, expected %q - got %q", k, c.TraceState, tr.State)
+		}
+	}
+}
+
+func TestW3CPropagatorInject(t *testing.T) {
+	const spanID = "00f067aa0ba902b7"
+	cases := map[string]struct {
+		Trace Trace
+		// output
+		TraceParent, TraceState string
+	}{
+		"sampled": {
+			Trace{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: spanID, Sampled: true, State: "a=b"},
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-" + spanID + "-01", "a=b",
+		},
+		"not-sampled": {
+			Trace{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: spanID},
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-" + spanID + "-00", "",
+		},
+		"xray": {
+			Trace{TraceID: "1-5759e988-bd862e3fe1be46a994272793", SpanID: spanID, Sampled: true},
+			"00-5759e988bd862e3fe1be46a994272793-" + spanID + "-01", "",
+		},
+		"invalid-trace": {Trace{TraceID: "trace", SpanID: spanID, Sampled: true}, "", ""},
+		"invalid-span":  {Trace{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "span"}, "", ""},
+	}
+	for k, c := range cases {
+		h := make(http.Header)
+		NewW3CPropagator().Inject(h, c.Trace)
+		if actual := h.Get(TraceParentHeader); actual != c.TraceParent {
+			t.Errorf("%s: invalid traceparent, expected %q - got %q", k, c.TraceParent, actual)
+		}
+		if actual := h.Get(TraceStateHeader); actual != c.TraceState {
+			t.Errorf("%s: invalid tracestate, expected %q - got %q", k, c.TraceState, actual)
+		}
+	}
+}
+
+func TestTracerPropagation(t *testing.T) {
+	var (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+		newID   = func() string { return spanID }
+	)
+	cases := map[string]struct {
+		Rate        int
+		TraceParent string
+		// output
+		CtxTraceID, CtxParentID string
+		Sampled                 bool
+		Flags                   string
+	}{
+		"sampled-parent":     {0, "00-" + traceID + "-b7ad6b7169203331-01", traceID, "b7ad6b7169203331", true, "01"},
+		"not-sampled-parent": {100, "00-" + traceID + "-b7ad6b7169203331-00", traceID, "b7ad6b7169203331", false, "00"},
+		"new-sampled":        {100, "", "", "", true, "01"},
+		"new-not-sampled":    {0, "", "", "", false, "00"},
+	}
+	for k, c := range cases {
+		var (
+			ctxTraceID, ctxParentID string
+			sampled                 bool
+			out                     *http.Request
+
+			m = NewTracer(SamplingPercent(c.Rate), SpanIDFunc(newID), Propagation(NewW3CPropagator()))
+			d = TraceDoer(doFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+				out = req
+				return nil, nil
+			}))
+			h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				ctxTraceID = ContextTraceID(ctx)
+				ctxParentID = ContextParentSpanID(ctx)
+				sampled = ContextTraceSampled(ctx)
+				r, _ := http.NewRequest("GET", "/downstream", nil)
+				d.Do(ctx, r)
+				return nil
+			}
+		)
+		req, _ := http.NewRequest("GET", "/", nil)
+		if c.TraceParent != "" {
+			req.Header.Set(TraceParentHeader, c.TraceParent)
+			req.Header.Set(TraceStateHeader, "congo=t61rcWkgMzE")
+		}
+
+		m(h)(context.Background(), httptest.NewRecorder(), req)
+
+		if c.CtxTraceID != "" && ctxTraceID != c.CtxTraceID {
+			t.Errorf("%s: invalid TraceID, expected %v - got %v", k, c.CtxTraceID, ctxTraceID)
+		}
+		if !isHex(ctxTraceID, 32) {
+			t.Errorf("%s: invalid W3C TraceID %v", k, ctxTraceID)
+		}
+		if ctxParentID != c.CtxParentID {
+			t.Errorf("%s: invalid ParentSpanID, expected %v - got %v", k, c.CtxParentID, ctxParentID)
+		}
+		if sampled != c.Sampled {
+			t.Errorf("%s: invalid sampled flag, expected %v - got %v", k, c.Sampled, sampled)
+		}
+		expected := "00-" + ctxTraceID + "-" + spanID + "-" + c.Flags
+		if actual := out.Header.Get(TraceParentHeader); actual != expected {
+			t.Errorf("%s: invalid traceparent, expected %v - got %v", k, expected, actual)
+		}
+		if c.TraceParent != "" && out.Header.Get(TraceStateHeader) != "congo=t61rcWkgMzE" {
+			t.Errorf("%s: tracestate not propagated, got %q", k, out.Header.Get(TraceStateHeader))
+		}
+		if out.Header.Get(TraceIDHeader) != "" {
+			t.Errorf("%s: unexpected %s header", k, TraceIDHeader)
+		}
+	}
+}
+
+// doFunc is a client Doer implemented by a function.
+type doFunc func(ctx context.Context, req *http.Request) (*http.Response, error)
+
+func (f doFunc) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return f(ctx, req)
+}
@@ -0,0 +1,222 @@
+/*
+Package ratelimit provides a middleware that limits the number of requests clients may make during
+a period of time. The limits are enforced using token buckets: each client gets a bucket that holds
+at most limit tokens and that is refilled at the rate of limit tokens per period, each request
+consumes one token.
+
+The middleware sets the "RateLimit-Limit", "RateLimit-Remaining" and "RateLimit-Reset" headers on
+all the responses. Requests made when the bucket is empty are rejected with a 429 response that
+also includes the "Retry-After" header.
+
+The buckets are kept in memory by default. Use UseStore or the WithStore option to share the state
+across service instances:
+
+	ratelimit.UseStore(service, redisStore)
+	service.Use(ratelimit.New(100, time.Minute, ratelimit.WithKey(ratelimit.ByHeader("X-API-Key"))))
+
+Clients are identified by the request remote address by default. Services running behind proxies
+or load balancers should use ByForwardedFor to identify clients by the address recorded by the
+trusted proxies in the "X-Forwarded-For" header:
+
+	service.Use(ratelimit.New(100, time.Minute, ratelimit.WithKey(ratelimit.ByForwardedFor("10.0.0.0/8"))))
+
+The controllers generated from designs that use the RateLimit DSL apply the middleware to the
+corresponding actions.
+*/
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+type (
+	// KeyFunc returns the key that identifies the client making the request. Requests that
+	// share the same key share the same limit.
+	KeyFunc func(*http.Request) string
+
+	// Option configures the middleware created by New.
+	Option func(*options)
+
+	options struct {
+		key   KeyFunc
+		scope string
+		store Store
+	}
+
+	// key is the type of the context keys used by the package.
+	key int
+)
+
+const (
+	// storeKey is the key used to store the service Store in the context.
+	storeKey key = iota + 1
+)
+
+// defaultStore is the store used when none is configured.
+var defaultStore = NewMemoryStore()
+
+// New returns a middleware that allows at most limit requests per period for each client. Clients
+// are identified by their IP address unless the WithKey option is used. Requests whose key is
+// empty are identified by their IP address as well.
+//
+// The middleware uses the store given to the WithStore option, the store registered on the
+// service with UseStore or an in-memory store in this order. Requests are let through if the
+// store fails.
+func New(limit int, period time.Duration, opts ...Option) goa.Middleware {
+	o := options{key: ByIP()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			k := o.key(req)
+			if k == "" {
+				k = remoteIP(req)
+			}
+			store := o.store
+			if store == nil {
+				store = contextStore(ctx)
+			}
+			q, err := store.Take(ctx, o.scope+":"+k, limit, period)
+			if err != nil {
+				goa.LogError(ctx, "rate limit", "err", err)
+				return h(ctx, rw, req)
+			}
+			rw.Header().Set("RateLimit-Limit", strconv.Itoa(q.Limit))
+			rw.Header().Set("RateLimit-Remaining", strconv.Itoa(q.Remaining))
+			rw.Header().Set("RateLimit-Reset", strconv.FormatInt(seconds(q.Reset), 10))
+			if !q.Allowed {
+				retry := seconds(q.RetryAfter)
+				rw.Header().Set("Retry-After", strconv.FormatInt(retry, 10))
+				return goa.TooManyRequestsError(q.Limit, retry)
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// WithKey sets the function used to identify the clients.
+func WithKey(fn KeyFunc) Option {
+	return func(o *options) {
+		o.key = fn
+	}
+}
+
+// WithScope sets the name of the limit. Middlewares with the same scope and store share their
+// buckets, the default scope is empty.
+func WithScope(scope string) Option {
+	return func(o *options) {
+		o.scope = scope
+	}
+}
+
+// WithStore sets the store used to record the state of the buckets.
+func WithStore(store Store) Option {
+	return func(o *options) {
+		o.store = store
+	}
+}
+
+// UseStore sets the store used by the middlewares mounted on the service that are not given a
+// store explicitly. UseStore must be called before the controllers are created.
+func UseStore(service *goa.Service, store Store) {
+	service.Context = context.WithValue(service.Context, storeKey, store)
+}
+
+// ByIP returns a KeyFunc that identifies clients by the IP address of the request remote address.
+// Clients cannot spoof the address but all the requests relayed by a proxy share its address, see
+// ByForwardedFor.
+func ByIP() KeyFunc {
+	return remoteIP
+}
+
+// ByForwardedFor returns a KeyFunc that identifies clients by IP address taking into account the
+// "X-Forwarded-For" header set by the given trusted proxies. Each trusted proxy is an IP address
+// or a CIDR range such as "10.0.0.0/8". The key is the remote address of the request unless it is
+// a trusted proxy, in which case it is the right-most address of the header that is not a trusted
+// proxy: the addresses on its left may be spoofed by clients. ByForwardedFor panics if a trusted
+// proxy is neither an IP address nor a CIDR range.
+func ByForwardedFor(trustedProxies ...string) KeyFunc {
+	nets := make([]*net.IPNet, len(trustedProxies))
+	for i, p := range trustedProxies {
+		if !strings.Contains(p, "/") {
+			if ip := net.ParseIP(p); ip != nil && ip.To4() != nil {
+				p += "/32"
+			} else {
+				p += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			panic(fmt.Sprintf("ratelimit: invalid trusted proxy %q", trustedProxies[i]))
+		}
+		nets[i] = n
+	}
+	trusted := func(addr string) bool {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			return false
+		}
+		for _, n := range nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(req *http.Request) string {
+		ip := remoteIP(req)
+		if !trusted(ip) {
+			return ip
+		}
+		hops := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop == "" {
+				continue
+			}
+			ip = hop
+			if !trusted(hop) {
+				break
+			}
+		}
+		return ip
+	}
+}
+
+// ByHeader returns a KeyFunc that identifies clients by the value of the given request header.
+func ByHeader(name string) KeyFunc {
+	return func(req *http.Request) string {
+		return req.Header.Get(name)
+	}
+}
+
+// contextStore returns the store registered with UseStore or the default store.
+func contextStore(ctx context.Context) Store {
+	if s, ok := ctx.Value(storeKey).(Store); ok {
+		return s
+	}
+	return defaultStore
+}
+
+// remoteIP returns the IP address of the request remote address.
+func remoteIP(req *http.Request) string {
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return ip
+}
+
+// seconds rounds d up to the next second.
+func seconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
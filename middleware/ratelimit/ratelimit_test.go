@@ -0,0 +1,167 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/ratelimit"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, int, time.Duration) (*ratelimit.Quota, error) {
+	return nil, errors.New("unavailable")
+}
+
+var _ = Describe("New", func() {
+	var opts []ratelimit.Option
+	var h goa.Handler
+	var calls int
+
+	BeforeEach(func() {
+		opts = []ratelimit.Option{ratelimit.WithStore(ratelimit.NewMemoryStore())}
+		calls = 0
+	})
+
+	JustBeforeEach(func() {
+		h = ratelimit.New(2, time.Hour, opts...)(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			calls++
+			return nil
+		})
+	})
+
+	send := func(remoteAddr, apiKey string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		rw := httptest.NewRecorder()
+		return rw, h(context.Background(), rw, req)
+	}
+
+	forward := func(remoteAddr, forwardedFor string) error {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		return h(context.Background(), httptest.NewRecorder(), req)
+	}
+
+	It("sets the rate limit headers", func() {
+		rw, err := send("10.0.0.1:4242", "")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rw.Header().Get("RateLimit-Limit")).Should(Equal("2"))
+		Ω(rw.Header().Get("RateLimit-Remaining")).Should(Equal("1"))
+		Ω(rw.Header().Get("RateLimit-Reset")).Should(Equal("1800"))
+		Ω(rw.Header().Get("Retry-After")).Should(BeEmpty())
+	})
+
+	It("rejects the requests that exceed the limit", func() {
+		send("10.0.0.1:4242", "")
+		send("10.0.0.1:4242", "")
+		rw, err := send("10.0.0.1:4242", "")
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(429))
+		Ω(err.(*goa.ErrorResponse).Meta["retry_after"]).Should(BeEquivalentTo(1800))
+		Ω(rw.Header().Get("Retry-After")).Should(Equal("1800"))
+		Ω(rw.Header().Get("RateLimit-Remaining")).Should(Equal("0"))
+		Ω(calls).Should(Equal(2))
+	})
+
+	It("limits each client IP separately", func() {
+		send("10.0.0.1:4242", "")
+		send("10.0.0.1:4242", "")
+		_, err := send("10.0.0.2:4242", "")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("ignores the X-Forwarded-For header", func() {
+		forward("10.0.0.1:4242", "203.0.113.1")
+		forward("10.0.0.1:4242", "203.0.113.2")
+		Ω(forward("10.0.0.1:4242", "203.0.113.3")).Should(HaveOccurred())
+	})
+
+	Context("with a forwarded for key", func() {
+		BeforeEach(func() {
+			opts = append(opts, ratelimit.WithKey(ratelimit.ByForwardedFor("10.0.0.0/8", "192.168.1.1")))
+		})
+
+		It("limits each client forwarded by the trusted proxies separately", func() {
+			forward("10.0.0.1:4242", "203.0.113.1, 192.168.1.1")
+			forward("10.0.0.1:4242", "203.0.113.1, 192.168.1.1")
+			Ω(forward("10.0.0.1:4242", "203.0.113.2, 192.168.1.1")).ShouldNot(HaveOccurred())
+			Ω(forward("10.0.0.2:4242", "203.0.113.1")).Should(HaveOccurred())
+		})
+
+		It("ignores the addresses added by clients", func() {
+			forward("10.0.0.1:4242", "198.51.100.1, 203.0.113.1")
+			forward("10.0.0.1:4242", "198.51.100.2, 203.0.113.1")
+			Ω(forward("10.0.0.1:4242", "198.51.100.3, 203.0.113.1")).Should(HaveOccurred())
+		})
+
+		It("ignores the header of requests not made by a trusted proxy", func() {
+			forward("203.0.113.1:4242", "198.51.100.1")
+			forward("203.0.113.1:4242", "198.51.100.2")
+			Ω(forward("203.0.113.1:4242", "198.51.100.3")).Should(HaveOccurred())
+		})
+
+		It("panics given an invalid trusted proxy", func() {
+			Ω(func() { ratelimit.ByForwardedFor("proxy") }).Should(Panic())
+		})
+	})
+
+	Context("with a header key", func() {
+		BeforeEach(func() {
+			opts = append(opts, ratelimit.WithKey(ratelimit.ByHeader("X-API-Key")))
+		})
+
+		It("limits each header value separately", func() {
+			send("10.0.0.1:4242", "a")
+			send("10.0.0.1:4242", "a")
+			_, err := send("10.0.0.1:4242", "b")
+			Ω(err).ShouldNot(HaveOccurred())
+			_, err = send("10.0.0.1:4242", "a")
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a failing store", func() {
+		BeforeEach(func() {
+			opts = []ratelimit.Option{ratelimit.WithStore(failingStore{})}
+		})
+
+		It("lets the requests through", func() {
+			for i := 0; i < 3; i++ {
+				_, err := send("10.0.0.1:4242", "")
+				Ω(err).ShouldNot(HaveOccurred())
+			}
+			Ω(calls).Should(Equal(3))
+		})
+	})
+
+	Context("with a service store", func() {
+		var service *goa.Service
+		var store ratelimit.Store
+
+		BeforeEach(func() {
+			service = goa.New("test")
+			store = ratelimit.NewMemoryStore()
+			ratelimit.UseStore(service, store)
+			opts = []ratelimit.Option{ratelimit.WithScope("foo")}
+		})
+
+		It("uses the service store", func() {
+			req := httptest.NewRequest("GET", "/", nil)
+			Ω(h(service.Context, httptest.NewRecorder(), req)).ShouldNot(HaveOccurred())
+			q, err := store.Take(context.Background(), "foo:192.0.2.1", 2, time.Hour)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(q.Remaining).Should(Equal(0))
+		})
+	})
+})
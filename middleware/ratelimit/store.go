@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type (
+	// Store records the state of the token buckets used to enforce rate limits. Implementations
+	// backed by a shared database make it possible to enforce limits across multiple instances
+	// of a service. Implementations must be safe for concurrent use.
+	Store interface {
+		// Take attempts to consume a token from the bucket identified by key. The bucket
+		// holds at most limit tokens and is refilled at the rate of limit tokens per
+		// period. Buckets that do not exist yet are created full.
+		Take(ctx context.Context, key string, limit int, period time.Duration) (*Quota, error)
+	}
+
+	// Quota describes the state of a bucket after a call to Take.
+	Quota struct {
+		// Allowed is true if a token was consumed, false if the bucket was empty.
+		Allowed bool
+		// Limit is the capacity of the bucket.
+		Limit int
+		// Remaining is the number of tokens left in the bucket.
+		Remaining int
+		// Reset is the time it takes for the bucket to be full again.
+		Reset time.Duration
+		// RetryAfter is the time it takes for a token to be available when Allowed is
+		// false, zero otherwise.
+		RetryAfter time.Duration
+	}
+
+	// memoryStore is a Store that keeps the buckets in memory.
+	memoryStore struct {
+		mu        sync.Mutex
+		buckets   map[string]*bucket
+		lastSweep time.Time
+	}
+
+	// bucket is a token bucket.
+	bucket struct {
+		tokens float64
+		last   time.Time
+		period time.Duration
+	}
+)
+
+// sweepInterval is the minimum interval between two removals of the idle buckets of a memory
+// store.
+const sweepInterval = time.Minute
+
+// NewMemoryStore returns a Store that keeps the buckets in memory. The store only enforces limits
+// per service instance. Buckets that are full again are removed periodically.
+func NewMemoryStore() Store {
+	return &memoryStore{buckets: make(map[string]*bucket), lastSweep: time.Now()}
+}
+
+// Take implements Store.
+func (s *memoryStore) Take(_ context.Context, key string, limit int, period time.Duration) (*Quota, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+	rate := float64(limit) / float64(period)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit), last: now, period: period}
+		s.buckets[key] = b
+	} else {
+		b.tokens = math.Min(float64(limit), b.tokens+float64(now.Sub(b.last))*rate)
+		b.last = now
+	}
+	q := &Quota{Limit: limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		q.Allowed = true
+	} else {
+		q.RetryAfter = time.Duration(math.Ceil((1 - b.tokens) / rate))
+	}
+	q.Remaining = int(b.tokens)
+	q.Reset = time.Duration(math.Ceil((float64(limit) - b.tokens) / rate))
+	return q, nil
+}
+
+// sweep removes the buckets that have been idle long enough to be full again.
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for k, b := range s.buckets {
+		if now.Sub(b.last) >= b.period {
+			delete(s.buckets, k)
+		}
+	}
+}
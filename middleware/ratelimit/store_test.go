@@ -0,0 +1,44 @@
+package ratelimit_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/goadesign/goa/middleware/ratelimit"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MemoryStore", func() {
+	var store ratelimit.Store
+
+	BeforeEach(func() {
+		store = ratelimit.NewMemoryStore()
+	})
+
+	It("creates full buckets", func() {
+		q, err := store.Take(context.Background(), "k", 10, time.Minute)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(q.Allowed).Should(BeTrue())
+		Ω(q.Limit).Should(Equal(10))
+		Ω(q.Remaining).Should(Equal(9))
+		Ω(q.Reset).Should(BeNumerically("~", 6*time.Second, time.Millisecond))
+		Ω(q.RetryAfter).Should(BeZero())
+	})
+
+	It("refills the buckets over time", func() {
+		store.Take(context.Background(), "k", 1, 20*time.Millisecond)
+		q, _ := store.Take(context.Background(), "k", 1, 20*time.Millisecond)
+		Ω(q.Allowed).Should(BeFalse())
+		Ω(q.RetryAfter).Should(BeNumerically(">", 0))
+		time.Sleep(q.RetryAfter)
+		q, _ = store.Take(context.Background(), "k", 1, 20*time.Millisecond)
+		Ω(q.Allowed).Should(BeTrue())
+	})
+
+	It("keeps separate buckets per key", func() {
+		store.Take(context.Background(), "a", 1, time.Hour)
+		q, _ := store.Take(context.Background(), "b", 1, time.Hour)
+		Ω(q.Allowed).Should(BeTrue())
+	})
+})
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/goadesign/goa"
+
+	"context"
+)
+
+// ListRoutes creates a middleware that responds to GET requests made to path with the list of
+// routes mounted on the service mux (see goa.ServeMux Routes). The list is rendered as a plain
+// text table if the request Accept header lists "text/plain" and encoded with the service
+// encoder otherwise. The middleware should be mounted at the service level, it handles requests
+// made to path even though no handler is mounted there.
+func ListRoutes(service *goa.Service, path string) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if req.Method != "GET" || req.URL.Path != path {
+				return h(ctx, rw, req)
+			}
+			routes := service.Mux.Routes()
+			if !strings.Contains(req.Header.Get("Accept"), "text/plain") {
+				return service.Send(ctx, http.StatusOK, routes)
+			}
+			var buf bytes.Buffer
+			w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "METHOD\tPATH\tCONTROLLER\tACTION")
+			for _, r := range routes {
+				action := r.Action
+				if r.File != "" {
+					action += " (" + r.File + ")"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Method, r.Path, r.Controller, action)
+			}
+			w.Flush()
+			rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			rw.WriteHeader(http.StatusOK)
+			_, err := rw.Write(buf.Bytes())
+			return err
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ListRoutes", func() {
+	var service *goa.Service
+	var rw *httptest.ResponseRecorder
+	var req *http.Request
+
+	BeforeEach(func() {
+		service = newService(nil)
+		service.Use(middleware.ListRoutes(service, "/debug/routes"))
+		ctrl := service.NewController("bottle")
+		handler := func(context.Context, http.ResponseWriter, *http.Request) error { return nil }
+		goa.HandleRoute(service.Mux, "GET", "/bottles/:id", &goa.Route{Controller: "bottle", Action: "show"}, ctrl.MuxHandler("show", handler, nil))
+		var err error
+		req, err = http.NewRequest("GET", "/debug/routes", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		rw = httptest.NewRecorder()
+		service.Mux.ServeHTTP(rw, req)
+	})
+
+	It("serves the routes as JSON", func() {
+		Ω(rw.Code).Should(Equal(http.StatusOK))
+		var routes []*goa.Route
+		Ω(json.Unmarshal(rw.Body.Bytes(), &routes)).ShouldNot(HaveOccurred())
+		Ω(routes).Should(Equal([]*goa.Route{{Method: "GET", Path: "/bottles/:id", Controller: "bottle", Action: "show"}}))
+	})
+
+	Context("with a plain text Accept header", func() {
+		BeforeEach(func() {
+			req.Header.Set("Accept", "text/plain")
+		})
+
+		It("serves the routes as a table", func() {
+			Ω(rw.Code).Should(Equal(http.StatusOK))
+			Ω(rw.Header().Get("Content-Type")).Should(Equal("text/plain; charset=utf-8"))
+			Ω(rw.Body.String()).Should(Equal("METHOD  PATH          CONTROLLER  ACTION\n" +
+				"GET     /bottles/:id  bottle      show\n"))
+		})
+	})
+
+	Context("with another path", func() {
+		BeforeEach(func() {
+			req.URL.Path = "/bottles/1"
+		})
+
+		It("invokes the next handler", func() {
+			Ω(rw.Code).Should(Equal(http.StatusOK))
+			Ω(rw.Body.Len()).Should(Equal(0))
+		})
+	})
+})
@@ -34,12 +34,16 @@ type (
 		samplingPercent int
 		maxSamplingRate int
 		sampleSize      int
+		propagator      Propagator
 	}
 
 	// tracedDoer is a goa client Doer that inserts the tracing headers for
 	// each request it makes.
 	tracedDoer struct {
 		client.Doer
+		// propagator is the propagator used to inject the headers, the propagator recorded
+		// in the request context is used if nil.
+		propagator Propagator
 	}
 )
 
@@ -118,16 +122,35 @@ func SampleSize(s int) TracerOption {
 // IDs respectively. This is configurable so that the created IDs are compatible
 // with the various backend tracing systems. The xray package provides
 // implementations that produce AWS X-Ray compatible IDs.
+//
+// The trace information is read from the TraceID and ParentSpanID headers
+// unless the Propagation option selects another propagator such as the W3C
+// Trace Context propagator. In this case the sampling decision carried by the
+// incoming request is honored.
 func NewTracer(opts ...TracerOption) goa.Middleware {
 	o := &tracerOptions{
-		traceIDFunc:     shortID,
-		spanIDFunc:      shortID,
 		samplingPercent: 100,
 		sampleSize:      1000, // only applies if maxSamplingRate is set
 	}
 	for _, opt := range opts {
 		o = opt(o)
 	}
+	propagator := o.propagator
+	if propagator == nil {
+		propagator = goaPropagator{}
+	}
+	if o.traceIDFunc == nil {
+		o.traceIDFunc = shortID
+		if o.propagator != nil {
+			o.traceIDFunc = newTraceID
+		}
+	}
+	if o.spanIDFunc == nil {
+		o.spanIDFunc = shortID
+		if o.propagator != nil {
+			o.spanIDFunc = newSpanID
+		}
+	}
 	var sampler Sampler
 	if o.maxSamplingRate > 0 {
 		sampler = NewAdaptiveSampler(o.maxSamplingRate, o.sampleSize)
@@ -137,20 +160,23 @@ func NewTracer(opts ...TracerOption) goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 			// insert a new trace ID only if not already being traced.
-			traceID := req.Header.Get(TraceIDHeader)
-			if traceID == "" {
-				// insert tracing only within sample.
-				if sampler.Sample() {
-					traceID = o.traceIDFunc()
-				} else {
+			t, ok := propagator.Extract(req.Header)
+			if !ok {
+				// insert tracing only within sample unless the
+				// sampling decision must be propagated.
+				t = Trace{Sampled: sampler.Sample()}
+				if !t.Sampled && o.propagator == nil {
 					return h(ctx, rw, req)
 				}
+				t.TraceID = o.traceIDFunc()
 			}
 
 			// insert IDs into context to enable tracing.
-			spanID := o.spanIDFunc()
-			parentID := req.Header.Get(ParentSpanIDHeader)
-			ctx = WithTrace(ctx, traceID, spanID, parentID)
+			ctx = WithTrace(ctx, t.TraceID, o.spanIDFunc(), t.SpanID)
+			if o.propagator != nil {
+				ctx = WithTraceSampling(ctx, t.Sampled, t.State)
+				ctx = context.WithValue(ctx, propagatorKey, o.propagator)
+			}
 			return h(ctx, rw, req)
 		}
 	}
@@ -163,8 +189,10 @@ func Tracer(sampleRate int, spanIDFunc, traceIDFunc IDFunc) goa.Middleware {
 
 // TraceDoer wraps a goa client Doer and sets the trace headers so that the
 // downstream service may properly retrieve the parent span ID and trace ID.
+// The headers are set using the propagator recorded in the request context by
+// the tracer middleware, see Propagation.
 func TraceDoer(doer client.Doer) client.Doer {
-	return &tracedDoer{doer}
+	return &tracedDoer{Doer: doer}
 }
 
 // ContextTraceID returns the trace ID extracted from the given context if any,
@@ -207,13 +235,17 @@ func WithTrace(ctx context.Context, traceID, spanID, parentID string) context.Co
 
 // Do adds the tracing headers to the requests before making it.
 func (d *tracedDoer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
-	var (
-		traceID = ContextTraceID(ctx)
-		spanID  = ContextSpanID(ctx)
-	)
-	if traceID != "" {
-		req.Header.Set(TraceIDHeader, traceID)
-		req.Header.Set(ParentSpanIDHeader, spanID)
+	if traceID := ContextTraceID(ctx); traceID != "" {
+		p := d.propagator
+		if p == nil {
+			p = contextPropagator(ctx)
+		}
+		p.Inject(req.Header, Trace{
+			TraceID: traceID,
+			SpanID:  ContextSpanID(ctx),
+			Sampled: ContextTraceSampled(ctx),
+			State:   ContextTraceState(ctx),
+		})
 	}
 
 	return d.Doer.Do(ctx, req)
@@ -3,6 +3,7 @@ package xray
 import (
 	"context"
 	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"net/http"
@@ -26,7 +27,10 @@ const (
 //
 // The middleware works by extracting the trace information from the context
 // using the tracing middleware package. The tracing middleware must be mounted
-// first on the service.
+// first on the service. Requests whose trace is not sampled are not recorded,
+// see middleware.ContextTraceSampled. W3C trace IDs are converted to the X-Ray
+// format so that services may propagate the traces using the W3C Trace Context
+// headers.
 //
 // The middleware stores the request segment in the context. Use ContextSegment
 // to retrieve it. User code can further configure the segment for example to set
@@ -59,7 +63,7 @@ func New(service, daemon string) (goa.Middleware, error) {
 				err     error
 				traceID = middleware.ContextTraceID(ctx)
 			)
-			if traceID == "" {
+			if traceID == "" || !middleware.ContextTraceSampled(ctx) {
 				// No tracing
 				return h(ctx, rw, req)
 			}
@@ -122,7 +126,7 @@ func newSegment(ctx context.Context, traceID, name string, req *http.Request, c
 		parentID = middleware.ContextParentSpanID(ctx)
 	)
 
-	s := NewSegment(name, traceID, spanID, c)
+	s := NewSegment(name, xrayTraceID(traceID), spanID, c)
 	s.RecordRequest(req, "")
 
 	if parentID != "" {
@@ -132,6 +136,19 @@ func newSegment(ctx context.Context, traceID, name string, req *http.Request, c
 	return s
 }
 
+// xrayTraceID returns the X-Ray representation of the given trace ID. W3C trace
+// IDs (32 hexadecimal digits) are converted by prepending the version and
+// splitting the time from the random part, other IDs are returned unchanged.
+func xrayTraceID(id string) string {
+	if len(id) != 32 {
+		return id
+	}
+	if _, err := hex.DecodeString(id); err != nil {
+		return id
+	}
+	return fmt.Sprintf("1-%s-%s", id[:8], id[8:])
+}
+
 // now returns the current time as a float appropriate for X-Ray processing.
 func now() float64 {
 	return float64(time.Now().Truncate(time.Millisecond).UnixNano()) / 1e9
@@ -387,3 +387,19 @@ func readUDP(t *testing.T, sender func()) string {
 
 	return <-readChan
 }
+
+func TestXRayTraceID(t *testing.T) {
+	cases := map[string]struct {
+		ID, Expected string
+	}{
+		"w3c":     {"5759e988bd862e3fe1be46a994272793", "1-5759e988-bd862e3fe1be46a994272793"},
+		"xray":    {"1-5759e988-bd862e3fe1be46a994272793", "1-5759e988-bd862e3fe1be46a994272793"},
+		"other":   {"traceID", "traceID"},
+		"not-hex": {"5759e988bd862e3fe1be46a99427279z", "5759e988bd862e3fe1be46a99427279z"},
+	}
+	for k, c := range cases {
+		if actual := xrayTraceID(c.ID); actual != c.Expected {
+			t.Errorf("%s: invalid trace ID, expected %v - got %v", k, c.Expected, actual)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package goa
 import (
 	"net/http"
 	"net/url"
+	"sort"
 
 	"github.com/dimfeld/httptreemux"
 )
@@ -35,6 +36,32 @@ type (
 		HandleMethodNotAllowed(handle MethodNotAllowedHandler)
 		// Lookup returns the MuxHandler associated with the given HTTP method and path.
 		Lookup(method, path string) MuxHandler
+		// Routes returns the routes registered with Handle sorted by path and method.
+		Routes() []*Route
+	}
+
+	// Route describes a handler registered with a ServeMux.
+	Route struct {
+		// Method is the route HTTP method.
+		Method string `json:"method" xml:"method" form:"method"`
+		// Path is the route request path.
+		Path string `json:"path" xml:"path" form:"path"`
+		// Controller is the name of the controller that handles the requests if the route was
+		// registered with HandleRoute.
+		Controller string `json:"controller,omitempty" xml:"controller,omitempty" form:"controller,omitempty"`
+		// Action is the name of the controller action that handles the requests.
+		Action string `json:"action,omitempty" xml:"action,omitempty" form:"action,omitempty"`
+		// File is the name of the file or directory served by the route if the route was
+		// mounted by ServeFiles.
+		File string `json:"file,omitempty" xml:"file,omitempty" form:"file,omitempty"`
+	}
+
+	// RouteHandler is implemented by the muxes that record the controller and action of the
+	// handlers they register, see HandleRoute.
+	RouteHandler interface {
+		// HandleRoute sets the MuxHandler for a given HTTP method and path and records the
+		// controller, action and file described by r in the route listed by Routes.
+		HandleRoute(method, path string, r *Route, handle MuxHandler)
 	}
 
 	// Muxer implements an adapter that given a request handler can produce a mux handler.
@@ -44,8 +71,15 @@ type (
 
 	// mux is the default ServeMux implementation.
 	mux struct {
-		router  *httptreemux.TreeMux
+		*routeTable
+		router *httptreemux.TreeMux
+	}
+
+	// routeTable records the handlers and routes registered with a mux. It implements the
+	// Lookup and Routes methods of ServeMux.
+	routeTable struct {
 		handles map[string]MuxHandler
+		routes  map[string]*Route
 	}
 )
 
@@ -54,13 +88,38 @@ func NewMux() ServeMux {
 	r := httptreemux.New()
 	r.EscapeAddedRoutes = true
 	return &mux{
-		router:  r,
+		routeTable: newRouteTable(),
+		router:     r,
+	}
+}
+
+// newRouteTable returns an empty route table.
+func newRouteTable() *routeTable {
+	return &routeTable{
 		handles: make(map[string]MuxHandler),
+		routes:  make(map[string]*Route),
+	}
+}
+
+// HandleRoute registers handle with mux for the given method and path. The route listed by the mux
+// Routes method includes the controller, action and file described by r if mux implements
+// RouteHandler. The generated controllers and Controller.ServeFiles use HandleRoute to mount their
+// handlers.
+func HandleRoute(mux ServeMux, method, path string, r *Route, handle MuxHandler) {
+	if rh, ok := mux.(RouteHandler); ok {
+		rh.HandleRoute(method, path, r, handle)
+		return
 	}
+	mux.Handle(method, path, handle)
 }
 
 // Handle sets the handler for the given verb and path.
 func (m *mux) Handle(method, path string, handle MuxHandler) {
+	m.HandleRoute(method, path, nil, handle)
+}
+
+// HandleRoute sets the handler for the given verb and path and records the route description.
+func (m *mux) HandleRoute(method, path string, r *Route, handle MuxHandler) {
 	hthandle := func(rw http.ResponseWriter, req *http.Request, htparams map[string]string) {
 		params := req.URL.Query()
 		for n, p := range htparams {
@@ -68,7 +127,7 @@ func (m *mux) Handle(method, path string, handle MuxHandler) {
 		}
 		handle(rw, req, params)
 	}
-	m.handles[method+path] = handle
+	m.add(method, path, r, handle)
 	m.router.Handle(method, path, hthandle)
 }
 
@@ -90,12 +149,38 @@ func (m *mux) HandleMethodNotAllowed(handle MethodNotAllowedHandler) {
 	m.router.MethodNotAllowedHandler = mna
 }
 
+// ServeHTTP is the function called back by the underlying HTTP server to handle incoming requests.
+func (m *mux) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	m.router.ServeHTTP(rw, req)
+}
+
+// add records the handler and route registered for the given method and path. r describes the
+// route controller, action and file if not nil.
+func (m *routeTable) add(method, path string, r *Route, handle MuxHandler) {
+	route := &Route{Method: method, Path: path}
+	if r != nil {
+		route.Controller, route.Action, route.File = r.Controller, r.Action, r.File
+	}
+	m.handles[method+path] = handle
+	m.routes[method+path] = route
+}
+
 // Lookup returns the MuxHandler associated with the given method and path.
-func (m *mux) Lookup(method, path string) MuxHandler {
+func (m *routeTable) Lookup(method, path string) MuxHandler {
 	return m.handles[method+path]
 }
 
-// ServeHTTP is the function called back by the underlying HTTP server to handle incoming requests.
-func (m *mux) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	m.router.ServeHTTP(rw, req)
+// Routes returns the registered routes sorted by path and method.
+func (m *routeTable) Routes() []*Route {
+	routes := make([]*Route, 0, len(m.routes))
+	for _, r := range m.routes {
+		routes = append(routes, r)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+	return routes
 }
@@ -0,0 +1,124 @@
+//go:build go1.22
+// +build go1.22
+
+package goa
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dimfeld/httptreemux"
+)
+
+// stdMux is a ServeMux implementation built on top of the net/http ServeMux.
+type stdMux struct {
+	*routeTable
+	mux              *http.ServeMux
+	methods          map[string]bool
+	notFound         MuxHandler
+	methodNotAllowed MethodNotAllowedHandler
+}
+
+// NewStdMux returns a ServeMux that relies on the routing patterns of the net/http ServeMux
+// introduced in Go 1.22. Paths use the goa syntax: ":name" matches a path segment and "*name" at
+// the end of the path matches the remainder of the path. Requests that match the path of a
+// handler but not its HTTP method are given to the handler set with HandleMethodNotAllowed, as
+// with NewMux.
+func NewStdMux() ServeMux {
+	return &stdMux{
+		routeTable: newRouteTable(),
+		mux:        http.NewServeMux(),
+		methods:    make(map[string]bool),
+	}
+}
+
+// Handle sets the handler for the given verb and path.
+func (m *stdMux) Handle(method, path string, handle MuxHandler) {
+	m.HandleRoute(method, path, nil, handle)
+}
+
+// HandleRoute sets the handler for the given verb and path and records the route description.
+func (m *stdMux) HandleRoute(method, path string, r *Route, handle MuxHandler) {
+	pattern, names := stdPattern(path)
+	m.mux.HandleFunc(method+" "+pattern, func(rw http.ResponseWriter, req *http.Request) {
+		params := req.URL.Query()
+		for _, n := range names {
+			params.Set(n, req.PathValue(n))
+		}
+		handle(rw, req, params)
+	})
+	m.methods[method] = true
+	m.add(method, path, r, handle)
+}
+
+// HandleNotFound sets the MuxHandler invoked for requests that don't match any
+// handler registered with Handle.
+func (m *stdMux) HandleNotFound(handle MuxHandler) {
+	m.notFound = handle
+}
+
+// HandleMethodNotAllowed sets the MuxHandler invoked for requests that match
+// the path of a handler but not its HTTP method.
+func (m *stdMux) HandleMethodNotAllowed(handle MethodNotAllowedHandler) {
+	m.methodNotAllowed = handle
+}
+
+// ServeHTTP dispatches the request to the handler whose pattern matches the request method and
+// path.
+func (m *stdMux) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if _, pattern := m.mux.Handler(req); pattern != "" {
+		m.mux.ServeHTTP(rw, req)
+		return
+	}
+	if allowed := m.allowed(req); len(allowed) > 0 && m.methodNotAllowed != nil {
+		m.methodNotAllowed(rw, req, nil, allowed)
+		return
+	}
+	if m.notFound != nil {
+		m.notFound(rw, req, nil)
+		return
+	}
+	m.mux.ServeHTTP(rw, req)
+}
+
+// allowed returns the methods of the handlers whose patterns match the request path.
+func (m *stdMux) allowed(req *http.Request) map[string]httptreemux.HandlerFunc {
+	var allowed map[string]httptreemux.HandlerFunc
+	for method := range m.methods {
+		r := req.Clone(req.Context())
+		r.Method = method
+		if _, pattern := m.mux.Handler(r); pattern == "" {
+			continue
+		}
+		if allowed == nil {
+			allowed = make(map[string]httptreemux.HandlerFunc)
+		}
+		allowed[method] = func(rw http.ResponseWriter, req *http.Request, _ map[string]string) {
+			m.mux.ServeHTTP(rw, req)
+		}
+	}
+	return allowed
+}
+
+// stdPattern converts a goa path into a net/http ServeMux pattern and returns the names of the
+// path wildcards.
+func stdPattern(path string) (string, []string) {
+	var names []string
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		switch {
+		case strings.HasPrefix(s, ":"):
+			names = append(names, s[1:])
+			segments[i] = "{" + s[1:] + "}"
+		case strings.HasPrefix(s, "*"):
+			names = append(names, s[1:])
+			segments[i] = "{" + s[1:] + "...}"
+		}
+	}
+	pattern := strings.Join(segments, "/")
+	if strings.HasSuffix(pattern, "/") {
+		// Patterns ending with a slash match all the paths they prefix.
+		pattern += "{$}"
+	}
+	return pattern, names
+}
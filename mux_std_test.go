@@ -0,0 +1,101 @@
+//go:build go1.22
+// +build go1.22
+
+package goa_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StdMux", func() {
+	var service *goa.Service
+	var params url.Values
+	var rw *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.UseMux(goa.NewStdMux())
+		service.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		params = nil
+		handle := func(rw http.ResponseWriter, req *http.Request, p url.Values) { params = p }
+		service.Mux.Handle("GET", "/bottles/:id", handle)
+		service.Mux.Handle("POST", "/bottles/:id", handle)
+		service.Mux.Handle("GET", "/files/*filepath", handle)
+		service.Mux.Handle("GET", "/", handle)
+	})
+
+	serve := func(h http.Handler, method, path string) {
+		req, err := http.NewRequest(method, path, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = httptest.NewRecorder()
+		h.ServeHTTP(rw, req)
+	}
+
+	It("sets the path parameters", func() {
+		serve(service.Mux, "GET", "/bottles/42?view=full")
+		Ω(rw.Code).Should(Equal(200))
+		Ω(params.Get("id")).Should(Equal("42"))
+		Ω(params.Get("view")).Should(Equal("full"))
+	})
+
+	It("matches wildcards", func() {
+		serve(service.Mux, "GET", "/files/css/goa.css")
+		Ω(params.Get("filepath")).Should(Equal("css/goa.css"))
+	})
+
+	It("matches paths ending with a slash exactly", func() {
+		serve(service.Mux, "GET", "/unknown")
+		Ω(params).Should(BeNil())
+		Ω(rw.Code).Should(Equal(404))
+	})
+
+	It("responds with 405 when the method does not match", func() {
+		serve(service.Mux, "DELETE", "/bottles/42")
+		Ω(rw.Code).Should(Equal(405))
+		Ω(strings.Split(rw.Header().Get("Allow"), ", ")).Should(ConsistOf("GET", "POST"))
+	})
+
+	It("lists the routes", func() {
+		routes := service.Mux.Routes()
+		Ω(routes).Should(HaveLen(4))
+		Ω(routes[0].Path).Should(Equal("/"))
+		Ω(routes[1].Method).Should(Equal("GET"))
+		Ω(routes[1].Path).Should(Equal("/bottles/:id"))
+		Ω(routes[2].Method).Should(Equal("POST"))
+	})
+
+	Context("mounted under a prefix", func() {
+		var mux *http.ServeMux
+
+		BeforeEach(func() {
+			mux = http.NewServeMux()
+			mux.Handle("/api/", service.PrefixHandler("/api/"))
+		})
+
+		It("removes the prefix", func() {
+			serve(mux, "GET", "/api/bottles/42")
+			Ω(rw.Code).Should(Equal(200))
+			Ω(params.Get("id")).Should(Equal("42"))
+		})
+
+		It("serves the root path", func() {
+			serve(mux, "GET", "/api/")
+			Ω(rw.Code).Should(Equal(200))
+			Ω(params).ShouldNot(BeNil())
+		})
+
+		It("uses the service not found handler", func() {
+			serve(mux, "GET", "/api/unknown")
+			Ω(rw.Code).Should(Equal(404))
+			Ω(params).Should(BeNil())
+		})
+	})
+
+})
@@ -2,6 +2,7 @@ package goa_test
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -84,3 +85,39 @@ var _ = Describe("Mux", func() {
 	})
 
 })
+
+var _ = Describe("Routes", func() {
+	var service *goa.Service
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.WithLogger(nil)
+	})
+
+	It("lists the mounted routes with their controller and action", func() {
+		ctrl := service.NewController("bottle")
+		handler := func(context.Context, http.ResponseWriter, *http.Request) error { return nil }
+		goa.HandleRoute(service.Mux, "POST", "/bottles", &goa.Route{Controller: "bottle", Action: "create"}, ctrl.MuxHandler("create", handler, nil))
+		goa.HandleRoute(service.Mux, "GET", "/bottles/:id", &goa.Route{Controller: "bottle", Action: "show"}, ctrl.MuxHandler("show", handler, nil))
+		service.Mux.Handle("GET", "/raw", func(http.ResponseWriter, *http.Request, url.Values) {})
+		Ω(ctrl.ServeFiles("/swagger.json", "swagger/swagger.json")).ShouldNot(HaveOccurred())
+		Ω(service.Mux.Routes()).Should(Equal([]*goa.Route{
+			{Method: "POST", Path: "/bottles", Controller: "bottle", Action: "create"},
+			{Method: "GET", Path: "/bottles/:id", Controller: "bottle", Action: "show"},
+			{Method: "GET", Path: "/raw"},
+			{Method: "GET", Path: "/swagger.json", Controller: "bottle", Action: "serve", File: "swagger/swagger.json"},
+		}))
+	})
+
+	It("does not attribute handlers mounted with Handle to a controller", func() {
+		ctrl := service.NewController("bottle")
+		handler := func(context.Context, http.ResponseWriter, *http.Request) error { return nil }
+		unused := ctrl.MuxHandler("show", handler, nil)
+		service.Mux.Handle("GET", "/raw", func(http.ResponseWriter, *http.Request, url.Values) {})
+		service.Mux.Handle("GET", "/bottles/:id", unused)
+		Ω(service.Mux.Routes()).Should(Equal([]*goa.Route{
+			{Method: "GET", Path: "/bottles/:id"},
+			{Method: "GET", Path: "/raw"},
+		}))
+	})
+})
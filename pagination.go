@@ -0,0 +1,31 @@
+package goa
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// PageLinks returns the value of the "Link" header (RFC 8288) that references the next and
+// previous pages of a paginated request. The links are built from the request URL u by setting
+// the cursor query string parameter to next and prev respectively. Empty cursors omit the
+// corresponding link and PageLinks returns an empty string if both cursors are empty.
+func PageLinks(u *url.URL, cursorParam, next, prev string) string {
+	var links []string
+	if next != "" {
+		links = append(links, pageLink(u, cursorParam, next, "next"))
+	}
+	if prev != "" {
+		links = append(links, pageLink(u, cursorParam, prev, "prev"))
+	}
+	return strings.Join(links, ", ")
+}
+
+// pageLink returns a single link to the page identified by cursor.
+func pageLink(u *url.URL, cursorParam, cursor, rel string) string {
+	link := *u
+	query := link.Query()
+	query.Set(cursorParam, cursor)
+	link.RawQuery = query.Encode()
+	return fmt.Sprintf("<%s>; rel=%q", link.String(), rel)
+}
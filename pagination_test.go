@@ -0,0 +1,34 @@
+package goa_test
+
+import (
+	"net/url"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PageLinks", func() {
+	var u *url.URL
+
+	BeforeEach(func() {
+		var err error
+		u, err = url.Parse("/bottles?cursor=b&limit=10")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("links to the next and previous pages", func() {
+		links := goa.PageLinks(u, "cursor", "c", "a")
+		Ω(links).Should(Equal(`</bottles?cursor=c&limit=10>; rel="next", </bottles?cursor=a&limit=10>; rel="prev"`))
+	})
+
+	It("omits empty cursors", func() {
+		Ω(goa.PageLinks(u, "cursor", "", "a")).Should(Equal(`</bottles?cursor=a&limit=10>; rel="prev"`))
+		Ω(goa.PageLinks(u, "cursor", "", "")).Should(BeEmpty())
+	})
+
+	It("does not modify the request URL", func() {
+		goa.PageLinks(u, "cursor", "c", "")
+		Ω(u.RawQuery).Should(Equal("cursor=b&limit=10"))
+	})
+})
@@ -0,0 +1,172 @@
+package goa
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+	// ProblemMediaIdentifier is the media type identifier of RFC 7807 problem details
+	// documents encoded in JSON.
+	ProblemMediaIdentifier = "application/problem+json"
+
+	// ProblemXMLMediaIdentifier is the media type identifier of RFC 7807 problem details
+	// documents encoded in XML.
+	ProblemXMLMediaIdentifier = "application/problem+xml"
+
+	// ProblemTypeBlank is the problem type used when the problem has no additional semantics
+	// beyond that of the HTTP status code.
+	ProblemTypeBlank = "about:blank"
+)
+
+// ProblemResponse is the RFC 7807 problem details representation of a ServiceError. The error ID
+// and code are rendered as the "id" and "code" extension members, the error metadata entries are
+// rendered as additional extension members. ProblemResponse implements ServiceError.
+type ProblemResponse struct {
+	// Type is a URI reference that identifies the problem type.
+	Type string `json:"type" xml:"type" form:"type"`
+	// Title is a short summary of the problem type.
+	Title string `json:"title" xml:"title" form:"title"`
+	// Status is the HTTP status code of the response.
+	Status int `json:"status" xml:"status" form:"status"`
+	// Detail describes the specific problem occurrence.
+	Detail string `json:"detail,omitempty" xml:"detail,omitempty" form:"detail,omitempty"`
+	// Instance is a URI reference that identifies the specific problem occurrence.
+	Instance string `json:"instance,omitempty" xml:"instance,omitempty" form:"instance,omitempty"`
+	// ID is the unique error instance identifier.
+	ID string `json:"id,omitempty" xml:"id,omitempty" form:"id,omitempty"`
+	// Code identifies the class of errors.
+	Code string `json:"code,omitempty" xml:"code,omitempty" form:"code,omitempty"`
+	// Extensions contains the additional members of the problem details document.
+	Extensions map[string]interface{} `json:"-" xml:"-" form:"-"`
+}
+
+// problemMembers lists the members of the problem details document that are not extensions.
+var problemMembers = map[string]bool{
+	"type": true, "title": true, "status": true, "detail": true, "instance": true, "id": true, "code": true,
+}
+
+// NewProblemResponse converts err into a problem details document. The problem type is typeBase
+// followed by the error code, or ProblemTypeBlank if typeBase is empty. instance identifies the
+// problem occurrence, typically the request path.
+func NewProblemResponse(err ServiceError, typeBase, instance string) *ProblemResponse {
+	if p, ok := err.(*ProblemResponse); ok {
+		return p
+	}
+	p := &ProblemResponse{
+		Type:     ProblemTypeBlank,
+		Title:    http.StatusText(err.ResponseStatus()),
+		Status:   err.ResponseStatus(),
+		Detail:   err.Error(),
+		Instance: instance,
+		ID:       err.Token(),
+	}
+	if e, ok := err.(*ErrorResponse); ok {
+		p.Detail, p.Code, p.Extensions = e.Detail, e.Code, e.Meta
+	}
+	if typeBase != "" && p.Code != "" {
+		p.Type = typeBase + p.Code
+	}
+	return p
+}
+
+// ErrorResponse converts the problem details document back into an ErrorResponse.
+func (p *ProblemResponse) ErrorResponse() *ErrorResponse {
+	code := p.Code
+	if code == "" && p.Type != ProblemTypeBlank {
+		code = p.Type[strings.LastIndexAny(p.Type, "/#:")+1:]
+	}
+	return &ErrorResponse{ID: p.ID, Code: code, Status: p.Status, Detail: p.Detail, Meta: p.Extensions}
+}
+
+// Error returns the problem occurrence details.
+func (p *ProblemResponse) Error() string { return p.ErrorResponse().Error() }
+
+// ResponseStatus is the status used to build responses.
+func (p *ProblemResponse) ResponseStatus() int { return p.Status }
+
+// Token is the unique error occurrence identifier.
+func (p *ProblemResponse) Token() string { return p.ID }
+
+// MarshalJSON renders the extensions as top level members of the document. Extensions never
+// override the standard members.
+func (p *ProblemResponse) MarshalJSON() ([]byte, error) {
+	type problem ProblemResponse
+	b, err := json.Marshal((*problem)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return b, err
+	}
+	ext := make(map[string]interface{}, len(p.Extensions))
+	for k, v := range p.Extensions {
+		if !problemMembers[k] {
+			ext[k] = v
+		}
+	}
+	if len(ext) == 0 {
+		return b, nil
+	}
+	e, err := json.Marshal(ext)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(b[:len(b)-1], ','), e[1:]...), nil
+}
+
+// UnmarshalJSON loads the members that are not defined by RFC 7807 or goa into Extensions.
+func (p *ProblemResponse) UnmarshalJSON(b []byte) error {
+	type problem ProblemResponse
+	if err := json.Unmarshal(b, (*problem)(p)); err != nil {
+		return err
+	}
+	var members map[string]interface{}
+	if err := json.Unmarshal(b, &members); err != nil {
+		return err
+	}
+	p.Extensions = nil
+	for k, v := range members {
+		if problemMembers[k] {
+			continue
+		}
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]interface{})
+		}
+		p.Extensions[k] = v
+	}
+	return nil
+}
+
+// IsProblemContentType returns true if contentType is the media type of problem details
+// documents.
+func IsProblemContentType(contentType string) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+	return contentType == ProblemMediaIdentifier || contentType == ProblemXMLMediaIdentifier
+}
+
+// DecodeError decodes an error response body. The body may either be an ErrorResponse or a
+// problem details document depending on contentType, both are returned as an ErrorResponse.
+func (decoder *HTTPDecoder) DecodeError(body io.Reader, contentType string) (*ErrorResponse, error) {
+	if !IsProblemContentType(contentType) {
+		var decoded ErrorResponse
+		err := decoder.Decode(&decoded, body, contentType)
+		return &decoded, err
+	}
+	var p ProblemResponse
+	if err := decoder.Decode(&p, body, contentType); err != nil {
+		return nil, err
+	}
+	return p.ErrorResponse(), nil
+}
+
+// problemContentType returns the problem details media type matching the given negotiated
+// response content type.
+func problemContentType(contentType string) string {
+	if strings.HasSuffix(contentType, "xml") {
+		return ProblemXMLMediaIdentifier
+	}
+	return ProblemMediaIdentifier
+}
@@ -0,0 +1,87 @@
+package goa_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProblemResponse", func() {
+	var problem *goa.ProblemResponse
+
+	BeforeEach(func() {
+		err := goa.ErrInvalidRequest("missing required parameter", "name", "id", "code", "ignored")
+		problem = goa.NewProblemResponse(err.(goa.ServiceError), "", "/bottles")
+	})
+
+	It("converts service errors", func() {
+		Ω(problem.Type).Should(Equal(goa.ProblemTypeBlank))
+		Ω(problem.Title).Should(Equal("Bad Request"))
+		Ω(problem.Status).Should(Equal(400))
+		Ω(problem.Detail).Should(Equal("missing required parameter"))
+		Ω(problem.Instance).Should(Equal("/bottles"))
+		Ω(problem.Code).Should(Equal("invalid_request"))
+		Ω(problem.ID).ShouldNot(BeEmpty())
+	})
+
+	It("renders the extensions as top level members", func() {
+		b, err := json.Marshal(problem)
+		Ω(err).ShouldNot(HaveOccurred())
+		var doc map[string]interface{}
+		Ω(json.Unmarshal(b, &doc)).ShouldNot(HaveOccurred())
+		Ω(doc).Should(HaveKeyWithValue("name", "id"))
+		Ω(doc).Should(HaveKeyWithValue("code", "invalid_request"))
+	})
+
+	It("round trips through JSON", func() {
+		b, err := json.Marshal(problem)
+		Ω(err).ShouldNot(HaveOccurred())
+		var decoded goa.ProblemResponse
+		Ω(json.Unmarshal(b, &decoded)).ShouldNot(HaveOccurred())
+		Ω(decoded.Extensions).Should(Equal(map[string]interface{}{"name": "id"}))
+		Ω(decoded.ErrorResponse()).Should(Equal(&goa.ErrorResponse{
+			ID:     problem.ID,
+			Code:   "invalid_request",
+			Status: 400,
+			Detail: "missing required parameter",
+			Meta:   map[string]interface{}{"name": "id"},
+		}))
+	})
+
+	It("derives the error code from the problem type", func() {
+		p := &goa.ProblemResponse{Type: "https://example.com/problems/out_of_stock", Status: 409}
+		Ω(p.ErrorResponse().Code).Should(Equal("out_of_stock"))
+	})
+})
+
+var _ = Describe("DecodeError", func() {
+	var decoder *goa.HTTPDecoder
+
+	BeforeEach(func() {
+		decoder = goa.NewHTTPDecoder()
+		decoder.Register(goa.NewJSONDecoder, "*/*")
+	})
+
+	It("decodes error responses", func() {
+		body := bytes.NewBufferString(`{"id":"foo","code":"not_found","status":404,"detail":"not found"}`)
+		resp, err := decoder.DecodeError(body, goa.ErrorMediaIdentifier)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp).Should(Equal(&goa.ErrorResponse{ID: "foo", Code: "not_found", Status: 404, Detail: "not found"}))
+	})
+
+	It("decodes problem details documents", func() {
+		body := bytes.NewBufferString(`{"type":"about:blank","title":"Not Found","status":404,"detail":"not found","id":"foo","code":"not_found","bottle":1}`)
+		resp, err := decoder.DecodeError(body, goa.ProblemMediaIdentifier+"; charset=utf-8")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp).Should(Equal(&goa.ErrorResponse{
+			ID:     "foo",
+			Code:   "not_found",
+			Status: 404,
+			Detail: "not found",
+			Meta:   map[string]interface{}{"bottle": float64(1)},
+		}))
+	})
+})
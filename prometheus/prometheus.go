@@ -0,0 +1,362 @@
+/*
+Package prometheus provides a goa metrics collector that exposes the metrics using the Prometheus
+text exposition format. The metrics are kept in memory and scraped from the service itself, no
+network sink is required:
+
+	c := prometheus.New()
+	goa.SetMetrics(c)
+	c.Mount(service)
+
+The controllers record the request count, duration, response size and in-flight request metrics of
+each action automatically, see goa.RequestsKey. The metric names are the keys joined with
+underscores. Timing metrics recorded with MeasureSince are histograms in seconds whose names end
+with "_seconds". Samples recorded with AddSample are histograms if buckets are configured for their
+name with WithBuckets and summaries without quantiles otherwise.
+*/
+package prometheus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/goadesign/goa"
+)
+
+type (
+	// Collector is a goa.LabeledCollector that keeps the metrics in memory and writes them
+	// using the Prometheus text exposition format.
+	Collector struct {
+		// Path is the path of the metrics endpoint mounted by Mount, DefaultPath if empty.
+		Path string
+
+		mu       sync.Mutex
+		buckets  map[string][]float64
+		families map[string]*family
+	}
+
+	// Option configures the collector created by New.
+	Option func(*Collector)
+
+	// family groups the series of a metric.
+	family struct {
+		name   string
+		typ    string
+		series map[string]*series
+	}
+
+	// series is the value of a metric for a set of labels.
+	series struct {
+		labels []metrics.Label
+		// value is the value of counters and gauges.
+		value float64
+		// bounds are the upper bounds of the histogram buckets and counts the cumulative
+		// number of observations for each bucket.
+		bounds []float64
+		counts []uint64
+		sum    float64
+		count  uint64
+	}
+)
+
+const (
+	// DefaultPath is the default path of the metrics endpoint.
+	DefaultPath = "/metrics"
+
+	// ContentType is the content type of the text exposition format.
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+var (
+	// DefaultDurationBuckets are the buckets of the timing metrics in seconds.
+	DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+	// DefaultSizeBuckets are the buckets of the response size metric in bytes.
+	DefaultSizeBuckets = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+)
+
+// New returns a collector. The response size metric recorded by the controllers uses
+// DefaultSizeBuckets unless overridden with WithBuckets.
+func New(opts ...Option) *Collector {
+	c := &Collector{
+		Path:     DefaultPath,
+		buckets:  map[string][]float64{metricName(goa.ResponseSizeKey): DefaultSizeBuckets},
+		families: make(map[string]*family),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithBuckets sets the upper bounds of the histogram buckets of the metric with the given name,
+// e.g. "goa_http_request_duration_seconds".
+func WithBuckets(name string, buckets ...float64) Option {
+	return func(c *Collector) {
+		b := append([]float64(nil), buckets...)
+		sort.Float64s(b)
+		c.buckets[name] = b
+	}
+}
+
+// Mount mounts the metrics endpoint on the service mux.
+func (c *Collector) Mount(service *goa.Service) {
+	path := c.Path
+	if path == "" {
+		path = DefaultPath
+	}
+	ctrl := service.NewController("metrics")
+	goa.HandleRoute(service.Mux, "GET", path, &goa.Route{Controller: ctrl.Name, Action: "show"},
+		ctrl.MuxHandler("show", c.handle, nil))
+	service.LogInfo("mount", "ctrl", "metrics", "action", "show", "route", "GET "+path)
+}
+
+// ServeHTTP writes the metrics, it makes it possible to serve them outside of a goa service.
+func (c *Collector) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", ContentType)
+	c.WriteTo(rw)
+}
+
+// WriteTo writes the metrics using the text exposition format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	c.mu.Lock()
+	names := make([]string, 0, len(c.families))
+	for name := range c.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c.families[name].write(&buf)
+	}
+	c.mu.Unlock()
+	return buf.WriteTo(w)
+}
+
+// AddSample implements goa.Collector.
+func (c *Collector) AddSample(key []string, val float32) {
+	c.AddSampleWithLabels(key, val, nil)
+}
+
+// EmitKey implements goa.Collector, the emitted values are recorded as gauges.
+func (c *Collector) EmitKey(key []string, val float32) {
+	c.SetGaugeWithLabels(key, val, nil)
+}
+
+// IncrCounter implements goa.Collector.
+func (c *Collector) IncrCounter(key []string, val float32) {
+	c.IncrCounterWithLabels(key, val, nil)
+}
+
+// MeasureSince implements goa.Collector.
+func (c *Collector) MeasureSince(key []string, start time.Time) {
+	c.MeasureSinceWithLabels(key, start, nil)
+}
+
+// SetGauge implements goa.Collector.
+func (c *Collector) SetGauge(key []string, val float32) {
+	c.SetGaugeWithLabels(key, val, nil)
+}
+
+// AddSampleWithLabels implements goa.LabeledCollector.
+func (c *Collector) AddSampleWithLabels(key []string, val float32, labels []metrics.Label) {
+	c.observe(metricName(key), float64(val), labels, nil)
+}
+
+// IncrCounterWithLabels implements goa.LabeledCollector.
+func (c *Collector) IncrCounterWithLabels(key []string, val float32, labels []metrics.Label) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s := c.series(metricName(key), "counter", labels, nil); s != nil {
+		s.value += float64(val)
+	}
+}
+
+// MeasureSinceWithLabels implements goa.LabeledCollector.
+func (c *Collector) MeasureSinceWithLabels(key []string, start time.Time, labels []metrics.Label) {
+	name := metricName(key) + "_seconds"
+	c.observe(name, time.Since(start).Seconds(), labels, DefaultDurationBuckets)
+}
+
+// SetGaugeWithLabels implements goa.LabeledCollector.
+func (c *Collector) SetGaugeWithLabels(key []string, val float32, labels []metrics.Label) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s := c.series(metricName(key), "gauge", labels, nil); s != nil {
+		s.value = float64(val)
+	}
+}
+
+// handle is the goa handler of the metrics endpoint.
+func (c *Collector) handle(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+	rw.Header().Set("Content-Type", ContentType)
+	rw.WriteHeader(http.StatusOK)
+	_, err := c.WriteTo(rw)
+	return err
+}
+
+// observe records an observation of a histogram or summary. The buckets configured for the
+// metric name override defaultBuckets, the metric is a summary if there are no buckets.
+func (c *Collector) observe(name string, val float64, labels []metrics.Label, defaultBuckets []float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	bounds, ok := c.buckets[name]
+	if !ok {
+		bounds = defaultBuckets
+	}
+	typ := "summary"
+	if len(bounds) > 0 {
+		typ = "histogram"
+	}
+	s := c.series(name, typ, labels, bounds)
+	if s == nil {
+		return
+	}
+	for i, b := range s.bounds {
+		if val <= b {
+			s.counts[i]++
+		}
+	}
+	s.sum += val
+	s.count++
+}
+
+// series returns the series of the metric with the given name and labels, creating it if needed.
+// series returns nil if the metric was already recorded with a different type. c.mu must be
+// held.
+func (c *Collector) series(name, typ string, labels []metrics.Label, bounds []float64) *series {
+	f, ok := c.families[name]
+	if !ok {
+		f = &family{name: name, typ: typ, series: make(map[string]*series)}
+		c.families[name] = f
+	}
+	if f.typ != typ {
+		return nil
+	}
+	sig := signature(labels)
+	s, ok := f.series[sig]
+	if !ok {
+		s = &series{
+			labels: append([]metrics.Label(nil), labels...),
+			bounds: bounds,
+			counts: make([]uint64, len(bounds)),
+		}
+		f.series[sig] = s
+	}
+	return s
+}
+
+// write writes the family using the text exposition format.
+func (f *family) write(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "# TYPE %s %s\n", f.name, f.typ)
+	sigs := make([]string, 0, len(f.series))
+	for sig := range f.series {
+		sigs = append(sigs, sig)
+	}
+	sort.Strings(sigs)
+	for _, sig := range sigs {
+		s := f.series[sig]
+		switch f.typ {
+		case "counter", "gauge":
+			writeSample(buf, f.name, s.labels, "", "", s.value)
+		default:
+			for i, b := range s.bounds {
+				writeSample(buf, f.name+"_bucket", s.labels, "le", formatFloat(b), float64(s.counts[i]))
+			}
+			if f.typ == "histogram" {
+				writeSample(buf, f.name+"_bucket", s.labels, "le", "+Inf", float64(s.count))
+			}
+			writeSample(buf, f.name+"_sum", s.labels, "", "", s.sum)
+			writeSample(buf, f.name+"_count", s.labels, "", "", float64(s.count))
+		}
+	}
+}
+
+// writeSample writes a sample line, extraName and extraValue define an additional label if not
+// empty.
+func writeSample(buf *bytes.Buffer, name string, labels []metrics.Label, extraName, extraValue string, val float64) {
+	buf.WriteString(name)
+	if len(labels) > 0 || extraName != "" {
+		buf.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeLabel(buf, l.Name, l.Value)
+		}
+		if extraName != "" {
+			if len(labels) > 0 {
+				buf.WriteByte(',')
+			}
+			writeLabel(buf, extraName, extraValue)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(formatFloat(val))
+	buf.WriteByte('\n')
+}
+
+// writeLabel writes a label pair escaping its value.
+func writeLabel(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(sanitize(name, false))
+	buf.WriteString(`="`)
+	buf.WriteString(labelValueEscaper.Replace(value))
+	buf.WriteByte('"')
+}
+
+// labelValueEscaper escapes the label values.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricName returns the name of the metric with the given key.
+func metricName(key []string) string {
+	return sanitize(strings.Join(key, "_"), true)
+}
+
+// signature returns a string that identifies the given labels.
+func signature(labels []metrics.Label) string {
+	var b strings.Builder
+	for _, l := range labels {
+		b.WriteString(l.Name)
+		b.WriteByte(0)
+		b.WriteString(l.Value)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// sanitize replaces the characters that are not valid in metric names (colons are only valid in
+// metric names) or label names with underscores.
+func sanitize(name string, metric bool) string {
+	b := []byte(name)
+	for i, c := range b {
+		valid := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			(c >= '0' && c <= '9' && i > 0) || (c == ':' && metric)
+		if !valid {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// formatFloat formats a sample value.
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,150 @@
+package prometheus_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/prometheus"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Collector", func() {
+	var c *prometheus.Collector
+
+	BeforeEach(func() {
+		c = prometheus.New(prometheus.WithBuckets("latency", 1, 10))
+	})
+
+	scrape := func() string {
+		var buf bytes.Buffer
+		_, err := c.WriteTo(&buf)
+		Ω(err).ShouldNot(HaveOccurred())
+		return buf.String()
+	}
+
+	It("writes counters and gauges", func() {
+		c.IncrCounter([]string{"jobs", "total"}, 1)
+		c.IncrCounterWithLabels([]string{"jobs", "total"}, 2, []metrics.Label{{Name: "queue", Value: `a"b`}})
+		c.IncrCounter([]string{"jobs", "total"}, 1)
+		c.SetGauge([]string{"queue.size"}, 3)
+		Ω(scrape()).Should(Equal(`# TYPE jobs_total counter
+jobs_total 2
+jobs_total{queue="a\"b"} 2
+# TYPE queue_size gauge
+queue_size 3
+`))
+	})
+
+	It("writes histograms", func() {
+		c.AddSample([]string{"latency"}, 0.5)
+		c.AddSample([]string{"latency"}, 5)
+		c.AddSample([]string{"latency"}, 50)
+		Ω(scrape()).Should(Equal(`# TYPE latency histogram
+latency_bucket{le="1"} 1
+latency_bucket{le="10"} 2
+latency_bucket{le="+Inf"} 3
+latency_sum 55.5
+latency_count 3
+`))
+	})
+
+	It("writes samples without buckets as summaries", func() {
+		c.AddSample([]string{"size"}, 2)
+		c.AddSample([]string{"size"}, 3)
+		Ω(scrape()).Should(Equal("# TYPE size summary\nsize_sum 5\nsize_count 2\n"))
+	})
+
+	It("records timings in seconds", func() {
+		c.MeasureSince([]string{"work"}, time.Now().Add(-2*time.Second))
+		out := scrape()
+		Ω(out).Should(ContainSubstring("# TYPE work_seconds histogram\n"))
+		Ω(out).Should(ContainSubstring(`work_seconds_bucket{le="1"} 0`))
+		Ω(out).Should(ContainSubstring(`work_seconds_bucket{le="2.5"} 1`))
+	})
+
+	It("ignores samples recorded with a different type", func() {
+		c.IncrCounter([]string{"m"}, 1)
+		c.SetGauge([]string{"m"}, 5)
+		Ω(scrape()).Should(Equal("# TYPE m counter\nm 1\n"))
+	})
+
+	Context("mounted on a service", func() {
+		var service *goa.Service
+
+		BeforeEach(func() {
+			service = goa.New("test")
+			service.WithLogger(nil)
+			goa.SetMetrics(c)
+			c.Mount(service)
+			ctrl := service.NewController("bottle")
+			service.Mux.Handle("GET", "/bottles", ctrl.MuxHandler("list", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				rw.WriteHeader(http.StatusNotFound)
+				_, err := rw.Write([]byte("not found"))
+				return err
+			}, nil))
+		})
+
+		AfterEach(func() {
+			goa.SetMetrics(goa.NewNoOpCollector())
+		})
+
+		It("exposes the request metrics", func() {
+			req, _ := http.NewRequest("GET", "/bottles", nil)
+			service.Mux.ServeHTTP(httptest.NewRecorder(), req)
+			rw := httptest.NewRecorder()
+			req, _ = http.NewRequest("GET", "/metrics", nil)
+			service.Mux.ServeHTTP(rw, req)
+			Ω(rw.Code).Should(Equal(http.StatusOK))
+			Ω(rw.Header().Get("Content-Type")).Should(Equal(prometheus.ContentType))
+			out := rw.Body.String()
+			Ω(out).Should(ContainSubstring(`goa_http_requests_total{controller="bottle",action="list",status="4xx"} 1` + "\n"))
+			Ω(out).Should(ContainSubstring(`goa_http_requests_in_flight{controller="bottle",action="list"} 0` + "\n"))
+			Ω(out).Should(ContainSubstring(`goa_http_requests_in_flight{controller="metrics",action="show"} 1` + "\n"))
+			Ω(out).Should(ContainSubstring(`goa_http_request_duration_seconds_count{controller="bottle",action="list",status="4xx"} 1` + "\n"))
+			Ω(out).Should(ContainSubstring(`goa_http_response_size_bytes_bucket{controller="bottle",action="list",status="4xx",le="100"} 1` + "\n"))
+			Ω(out).Should(ContainSubstring(`goa_http_response_size_bytes_sum{controller="bottle",action="list",status="4xx"} 9` + "\n"))
+		})
+
+		Context("with an action mounted on two routes", func() {
+			var release chan struct{}
+
+			BeforeEach(func() {
+				release = make(chan struct{})
+				ctrl := service.NewController("bottle")
+				show := ctrl.MuxHandler("show", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					if req.URL.Path == "/bottles/slow" {
+						<-release
+					}
+					return nil
+				}, nil)
+				service.Mux.Handle("GET", "/bottles/slow", show)
+				ctrl = service.NewController("bottle")
+				service.Mux.Handle("GET", "/v1/bottles/fast", ctrl.MuxHandler("show", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					return nil
+				}, nil))
+			})
+
+			It("counts the in-flight requests of all the routes", func() {
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					req, _ := http.NewRequest("GET", "/bottles/slow", nil)
+					service.Mux.ServeHTTP(httptest.NewRecorder(), req)
+				}()
+				Eventually(scrape).Should(ContainSubstring(`goa_http_requests_in_flight{controller="bottle",action="show"} 1` + "\n"))
+				req, _ := http.NewRequest("GET", "/v1/bottles/fast", nil)
+				service.Mux.ServeHTTP(httptest.NewRecorder(), req)
+				Ω(scrape()).Should(ContainSubstring(`goa_http_requests_in_flight{controller="bottle",action="show"} 1` + "\n"))
+				close(release)
+				<-done
+				Ω(scrape()).Should(ContainSubstring(`goa_http_requests_in_flight{controller="bottle",action="show"} 0` + "\n"))
+			})
+		})
+	})
+})
@@ -0,0 +1,22 @@
+package goa
+
+// RedactedValue replaces the values of the sensitive string attributes in the values returned by
+// the Redact methods of the generated types.
+const RedactedValue = "<redacted>"
+
+// Redactor is implemented by the generated payload, media and user types that have sensitive
+// attributes, see the Sensitive DSL.
+type Redactor interface {
+	// Redact returns a copy of the value whose sensitive attributes are masked: strings are
+	// replaced with RedactedValue and other values are cleared.
+	Redact() interface{}
+}
+
+// Redact returns the redacted copy of v if v implements Redactor, v otherwise. Use Redact to log
+// values that may contain sensitive data.
+func Redact(v interface{}) interface{} {
+	if r, ok := v.(Redactor); ok {
+		return r.Redact()
+	}
+	return v
+}
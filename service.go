@@ -13,6 +13,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/dimfeld/httptreemux"
 )
@@ -39,9 +40,31 @@ type (
 		Decoder *HTTPDecoder
 		// Response body encoder
 		Encoder *HTTPEncoder
+		// ShutdownDelay is the duration Shutdown waits for after flagging the service as not
+		// ready and before it stops accepting new connections. This gives load balancers
+		// probing the service readiness time to stop routing traffic to it.
+		ShutdownDelay time.Duration
+		// ShutdownHookTimeout is the maximum duration of the hooks registered with
+		// OnShutdown, DefaultShutdownHookTimeout if 0. The hooks get their own deadline so
+		// that they run even if draining the in-flight requests used up the Shutdown context.
+		ShutdownHookTimeout time.Duration
+		// ProblemDetails causes Send to render ServiceError response bodies as RFC 7807
+		// problem details documents instead of ErrorResponse.
+		ProblemDetails bool
+		// ProblemTypeBase is the URI prefix of the problem types, the type of a problem is the
+		// prefix followed by the error code. Problems are of type ProblemTypeBlank if empty.
+		ProblemTypeBase string
+		// Messages contains the translations of error messages. Send translates the detail of
+		// ErrorResponse bodies in the language requested by the request Accept-Language header
+		// if not nil.
+		Messages *MessageCatalog
 
 		middleware []Middleware       // Middleware chain
 		cancel     context.CancelFunc // Service context cancel signal trigger
+		lifecycle  lifecycle          // Start and shutdown hooks and state
+
+		metricsMu sync.Mutex                    // Guards metrics
+		metrics   map[[2]string]*requestMetrics // Request metrics indexed by controller and action
 	}
 
 	// Controller defines the common fields and behavior of generated controllers.
@@ -94,22 +117,32 @@ func New(name string) *Service {
 		stdlog       = log.New(os.Stderr, "", log.LstdFlags)
 		ctx          = WithLogger(context.Background(), NewLogger(stdlog))
 		cctx, cancel = context.WithCancel(ctx)
-		mux          = NewMux()
 		service      = &Service{
 			Name:    name,
 			Context: cctx,
-			Mux:     mux,
-			Server: &http.Server{
-				Handler: mux,
-			},
+			Server:  &http.Server{},
 			Decoder: NewHTTPDecoder(),
 			Encoder: NewHTTPEncoder(),
 
 			cancel: cancel,
 		}
+	)
+	service.UseMux(NewMux())
+
+	return service
+}
+
+// UseMux sets the service mux and installs the service not found and method not allowed
+// handlers on it. The mux also becomes the handler of the service HTTP server. UseMux must be
+// called before any controller is mounted on the service.
+func (service *Service) UseMux(mux ServeMux) {
+	var (
+		ctx                     = service.Context
 		notFoundHandler         Handler
 		methodNotAllowedHandler Handler
 	)
+	service.Mux = mux
+	service.Server.Handler = mux
 
 	// Setup default NotFound handler
 	mux.HandleNotFound(func(rw http.ResponseWriter, req *http.Request, params url.Values) {
@@ -165,8 +198,6 @@ func New(name string) *Service {
 			service.Send(ctx, 405, err)
 		}
 	})
-
-	return service
 }
 
 // CancelAll sends a cancel signals to all request handlers via the context.
@@ -198,22 +229,25 @@ func (service *Service) LogError(msg string, keyvals ...interface{}) {
 }
 
 // ListenAndServe starts a HTTP server and sets up a listener on the given host/port.
+// It runs the OnStart hooks first and returns once the service is shut down.
 func (service *Service) ListenAndServe(addr string) error {
 	service.LogInfo("listen", "transport", "http", "addr", addr)
 	service.Server.Addr = addr
-	return service.Server.ListenAndServe()
+	return service.serve(service.Server.ListenAndServe)
 }
 
 // ListenAndServeTLS starts a HTTPS server and sets up a listener on the given host/port.
+// It runs the OnStart hooks first and returns once the service is shut down.
 func (service *Service) ListenAndServeTLS(addr, certFile, keyFile string) error {
 	service.LogInfo("listen", "transport", "https", "addr", addr)
 	service.Server.Addr = addr
-	return service.Server.ListenAndServeTLS(certFile, keyFile)
+	return service.serve(func() error { return service.Server.ListenAndServeTLS(certFile, keyFile) })
 }
 
 // Serve accepts incoming HTTP connections on the listener l, invoking the service mux handler for each.
+// It runs the OnStart hooks first and returns once the service is shut down.
 func (service *Service) Serve(l net.Listener) error {
-	return service.Server.Serve(l)
+	return service.serve(func() error { return service.Server.Serve(l) })
 }
 
 // NewController returns a controller for the given resource. This method is mainly intended for
@@ -230,17 +264,126 @@ func (service *Service) NewController(name string) *Controller {
 	}
 }
 
+// RegisterMessages adds the given translations of error messages in the language lang to the
+// service message catalog. See MessageCatalog for the format of the messages.
+func (service *Service) RegisterMessages(lang string, messages map[string]string) {
+	if service.Messages == nil {
+		service.Messages = NewMessageCatalog()
+	}
+	service.Messages.Register(lang, messages)
+}
+
 // Send serializes the given body matching the request Accept header against the service
-// encoders. It uses the default service encoder if no match is found.
+// encoders. It uses the default service encoder if the header accepts any content type. Send sets
+// the response Content-Type header to the negotiated content type unless it is already set. Send
+// responds with a 406 Not Acceptable error instead if no registered encoder produces a content
+// type accepted by the client. Send translates the detail of ErrorResponse bodies using Messages
+// and renders ServiceError bodies as problem details documents if ProblemDetails is true. Send
+// records bodies that implement Redactor in the response data so that they can be logged safely.
 func (service *Service) Send(ctx context.Context, code int, body interface{}) error {
 	r := ContextResponse(ctx)
 	if r == nil {
 		return fmt.Errorf("no response data in context")
 	}
+	var accept string
+	if req := ContextRequest(ctx); req != nil {
+		accept = req.Header.Get("Accept")
+	}
+	encoder := service.encoder(ctx)
+	contentType, err := encoder.Negotiate(accept)
+	if err != nil && len(encoder.contentTypes) > 0 {
+		return service.sendNotAcceptable(ctx, r, err)
+	}
+	body = service.errorBody(ctx, r, body, contentType)
+	if r.Header().Get("Content-Type") == "" {
+		if ct := encoder.ContentType(contentType); ct != "" {
+			r.Header().Set("Content-Type", ct)
+		}
+	}
+	if rd, ok := body.(Redactor); ok {
+		r.Redactor = rd
+	}
 	r.WriteHeader(code)
 	return service.EncodeResponse(ctx, body)
 }
 
+// errorBody translates the detail of ErrorResponse bodies if Messages is not nil and converts
+// ServiceError bodies into problem details documents if ProblemDetails is true. Other bodies are
+// returned as is.
+func (service *Service) errorBody(ctx context.Context, r *ResponseData, body interface{}, contentType string) interface{} {
+	if e, ok := body.(*ErrorResponse); ok && service.Messages != nil {
+		body = service.localize(ctx, r, e)
+	}
+	if serr, ok := body.(ServiceError); ok && service.ProblemDetails {
+		body = service.problem(ctx, r, serr, contentType)
+	}
+	return body
+}
+
+// localize translates the detail of err in the language requested by the client and sets the
+// response Content-Language header accordingly.
+func (service *Service) localize(ctx context.Context, r *ResponseData, err *ErrorResponse) *ErrorResponse {
+	req := ContextRequest(ctx)
+	if req == nil {
+		return err
+	}
+	lang := service.Messages.Match(req.Header.Get("Accept-Language"))
+	if lang == "" {
+		return err
+	}
+	localized := service.Messages.Localize(err, lang)
+	if localized != err {
+		r.Header().Set("Content-Language", lang)
+		r.Header().Add("Vary", "Accept-Language")
+	}
+	return localized
+}
+
+// problem converts err into a problem details document and sets the response Content-Type
+// accordingly unless it was set to a media type other than ErrorMediaIdentifier.
+func (service *Service) problem(ctx context.Context, r *ResponseData, err ServiceError, contentType string) *ProblemResponse {
+	var instance string
+	if req := ContextRequest(ctx); req != nil {
+		instance = req.URL.Path
+	}
+	if ct := r.Header().Get("Content-Type"); ct == "" || ct == ErrorMediaIdentifier {
+		r.Header().Set("Content-Type", problemContentType(service.encoder(ctx).ContentType(contentType)))
+	}
+	return NewProblemResponse(err, service.ProblemTypeBase, instance)
+}
+
+// sendNotAcceptable writes a 406 response containing the given error. The error is encoded with
+// the default encoder, it is written as plain text if there is none.
+func (service *Service) sendNotAcceptable(ctx context.Context, r *ResponseData, err error) error {
+	encoder := service.encoder(ctx)
+	if _, nerr := encoder.Negotiate("*/*"); nerr != nil {
+		r.Header().Set("Content-Type", "text/plain")
+		r.WriteHeader(http.StatusNotAcceptable)
+		_, werr := r.Write([]byte(err.Error()))
+		return werr
+	}
+	r.Header().Set("Content-Type", ErrorMediaIdentifier)
+	body := service.errorBody(ctx, r, err, "*/*")
+	r.WriteHeader(http.StatusNotAcceptable)
+	return encoder.Encode(body, r, "*/*")
+}
+
+// PrefixHandler returns a http.Handler that serves requests made to paths under prefix with the
+// service mux after removing prefix from the request path. This makes it possible to embed the
+// service in an existing http.Handler tree, for example:
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("/api/", service.PrefixHandler("/api"))
+func (service *Service) PrefixHandler(prefix string) http.Handler {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return http.StripPrefix(prefix, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "" {
+			req.URL.Path = "/"
+		}
+		service.Mux.ServeHTTP(rw, req)
+	}))
+}
+
 // ServeFiles create a "FileServer" controller and calls ServerFiles on it.
 func (service *Service) ServeFiles(path, filename string) error {
 	ctrl := service.NewController("FileServer")
@@ -248,12 +391,17 @@ func (service *Service) ServeFiles(path, filename string) error {
 }
 
 // DecodeRequest uses the HTTP decoder to unmarshal the request body into the provided value based
-// on the request Content-Type header.
+// on the request Content-Type header. The decoder of the request context set with WithDecoder is
+// used instead of the service Decoder if there is one.
 func (service *Service) DecodeRequest(req *http.Request, v interface{}) error {
 	body, contentType := req.Body, req.Header.Get("Content-Type")
 	defer body.Close()
 
-	if err := service.Decoder.Decode(v, body, contentType); err != nil {
+	decoder := service.Decoder
+	if d := ContextDecoder(req.Context()); d != nil {
+		decoder = d
+	}
+	if err := decoder.Decode(v, body, contentType); err != nil {
 		return fmt.Errorf("failed to decode request body with content type %#v: %s", contentType, err)
 	}
 
@@ -261,10 +409,20 @@ func (service *Service) DecodeRequest(req *http.Request, v interface{}) error {
 }
 
 // EncodeResponse uses the HTTP encoder to marshal and write the response body based on the request
-// Accept header.
+// Accept header. The encoder of the context set with WithEncoder is used instead of the service
+// Encoder if there is one.
 func (service *Service) EncodeResponse(ctx context.Context, v interface{}) error {
 	accept := ContextRequest(ctx).Header.Get("Accept")
-	return service.Encoder.Encode(v, ContextResponse(ctx), accept)
+	return service.encoder(ctx).Encode(v, ContextResponse(ctx), accept)
+}
+
+// encoder returns the response encoder of the given context if any, the service Encoder
+// otherwise.
+func (service *Service) encoder(ctx context.Context) *HTTPEncoder {
+	if e := ContextEncoder(ctx); e != nil {
+		return e
+	}
+	return service.Encoder
 }
 
 // ServeFiles replies to the request with the contents of the named file or directory. See
@@ -280,7 +438,8 @@ func (ctrl *Controller) ServeFiles(path, filename string) error {
 		}
 		return nil
 	}
-	ctrl.Service.Mux.Handle("GET", path, ctrl.MuxHandler("serve", handler, nil))
+	h := ctrl.MuxHandler("serve", handler, nil)
+	HandleRoute(ctrl.Service.Mux, "GET", path, &Route{Controller: ctrl.Name, Action: "serve", File: filename}, h)
 	return nil
 }
 
@@ -290,16 +449,39 @@ func (ctrl *Controller) Use(m Middleware) {
 	ctrl.middleware = append(ctrl.middleware, m)
 }
 
+// requestMetrics returns the request metrics of the given controller action. The handlers of all
+// the routes of an action share the same metrics so that the in-flight requests add up.
+func (ctrl *Controller) requestMetrics(action string) *requestMetrics {
+	service := ctrl.Service
+	service.metricsMu.Lock()
+	defer service.metricsMu.Unlock()
+	key := [2]string{ctrl.Name, action}
+	rm, ok := service.metrics[key]
+	if !ok {
+		if service.metrics == nil {
+			service.metrics = make(map[[2]string]*requestMetrics)
+		}
+		rm = newRequestMetrics(ctrl.Name, action)
+		service.metrics[key] = rm
+	}
+	return rm
+}
+
 // MuxHandler wraps a request handler into a MuxHandler. The MuxHandler initializes the request
 // context by loading the request state, invokes the handler and in case of error invokes the
 // controller (if there is one) or Service error handler.
 // This function is intended for the controller generated code. User code should not need to call
-// it directly.
+// it directly. Register the returned handler with HandleRoute so that the mux Routes method lists
+// the controller and action names.
+//
+// The returned handler records the request count, duration, response size and in-flight request
+// metrics of the action with the metrics collector, see RequestsKey.
 func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) MuxHandler {
 	// Use closure to enable late computation of handlers to ensure all middleware has been
 	// registered.
 	var handler Handler
 	var initHandler sync.Once
+	rm := ctrl.requestMetrics(name)
 
 	return func(rw http.ResponseWriter, req *http.Request, params url.Values) {
 		// Build handler middleware chains on first invocation
@@ -320,6 +502,11 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 
 		// Build context
 		ctx := NewContext(WithAction(ctrl.Context, name), rw, req, params)
+		if e := ContextEncoder(req.Context()); e != nil {
+			ctx = WithEncoder(ctx, e)
+		}
+		start := rm.start()
+		defer func() { rm.done(ContextResponse(ctx), start) }()
 
 		// Protect against request bodies with unreasonable length
 		if ctrl.MaxRequestBodyLength > 0 {
@@ -2,6 +2,7 @@ package goa_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -187,6 +188,32 @@ var _ = Describe("Service", func() {
 			Ω(muxHandler).ShouldNot(BeNil())
 		})
 
+		Context("with action encoders", func() {
+			var enc *goa.HTTPEncoder
+
+			BeforeEach(func() {
+				s = goa.New("test")
+				s.Decoder.Register(goa.NewJSONDecoder, "application/json")
+				enc = goa.NewHTTPEncoder()
+				enc.Register(goa.NewJSONEncoder, "*/*")
+			})
+
+			JustBeforeEach(func() {
+				dec := goa.NewHTTPDecoder()
+				dec.Register(goa.NewJSONDecoder, "application/vnd.custom")
+				r, err := http.NewRequest("POST", "/foo", bytes.NewReader([]byte(`{"hello": "world"}`)))
+				Ω(err).ShouldNot(HaveOccurred())
+				r.Header.Set("Content-Type", "application/vnd.custom")
+				rw := &TestResponseWriter{ParentHeader: make(http.Header)}
+				goa.EncodingMuxHandler(muxHandler, enc, dec)(rw, r, nil)
+			})
+
+			It("uses the action encoder and decoder", func() {
+				Ω(goa.ContextEncoder(ctx)).Should(BeIdenticalTo(enc))
+				Ω(goa.ContextRequest(ctx).Payload).Should(Equal(map[string]interface{}{"hello": "world"}))
+			})
+		})
+
 		Context("with multiple instances and middlewares", func() {
 			var ctrl *goa.Controller
 			var handlers []goa.MuxHandler
@@ -377,6 +404,105 @@ var _ = Describe("Service", func() {
 		})
 	})
 
+	Describe("Send", func() {
+		var rw *TestResponseWriter
+		var ctx context.Context
+
+		BeforeEach(func() {
+			s.Encoder.Register(goa.NewXMLEncoder, "application/xml")
+			req, err := http.NewRequest("GET", "/", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			req.Header.Set("Accept", "text/html, application/xml;q=0.8")
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctx = goa.NewContext(context.Background(), rw, req, nil)
+		})
+
+		It("encodes the body using the negotiated content type", func() {
+			Ω(s.Send(ctx, 200, "ok")).ShouldNot(HaveOccurred())
+			Ω(rw.Status).Should(Equal(200))
+			Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal("application/xml"))
+			Ω(string(rw.Body)).Should(Equal("<string>ok</string>"))
+		})
+
+		Context("with no acceptable content type", func() {
+			BeforeEach(func() {
+				goa.ContextRequest(ctx).Header.Set("Accept", "text/html")
+			})
+
+			It("responds with a not acceptable error", func() {
+				Ω(s.Send(ctx, 200, "ok")).ShouldNot(HaveOccurred())
+				Ω(rw.Status).Should(Equal(406))
+				Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal(goa.ErrorMediaIdentifier))
+				Ω(string(rw.Body)).Should(ContainSubstring(`"code":"not_acceptable","status":406`))
+			})
+		})
+
+		Context("with an encoder in the context", func() {
+			BeforeEach(func() {
+				enc := goa.NewHTTPEncoder()
+				enc.Register(goa.NewJSONEncoder, "application/json")
+				ctx = goa.WithEncoder(ctx, enc)
+				goa.ContextRequest(ctx).Header.Set("Accept", "application/json")
+			})
+
+			It("uses the context encoder", func() {
+				Ω(s.Send(ctx, 200, "ok")).ShouldNot(HaveOccurred())
+				Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal("application/json"))
+				Ω(string(rw.Body)).Should(Equal("\"ok\"\n"))
+			})
+		})
+
+		Context("with translations", func() {
+			BeforeEach(func() {
+				s.RegisterMessages("fr", map[string]string{goa.MsgMissingParam: "paramètre {name} manquant"})
+				goa.ContextRequest(ctx).Header.Set("Accept", "*/*")
+				goa.ContextRequest(ctx).Header.Set("Accept-Language", "fr-FR, en;q=0.5")
+			})
+
+			It("translates the error details", func() {
+				Ω(s.Send(ctx, 400, goa.MissingParamError("id"))).ShouldNot(HaveOccurred())
+				Ω(rw.ParentHeader.Get("Content-Language")).Should(Equal("fr"))
+				Ω(rw.ParentHeader.Get("Vary")).Should(Equal("Accept-Language"))
+				Ω(string(rw.Body)).Should(ContainSubstring(`"detail":"paramètre id manquant","meta":{"name":"id"}`))
+			})
+		})
+
+		Context("with problem details", func() {
+			BeforeEach(func() {
+				s.Encoder.Register(goa.NewJSONEncoder, "application/json")
+				s.ProblemDetails = true
+				s.ProblemTypeBase = "https://errors.goa.design/"
+				goa.ContextRequest(ctx).Header.Set("Accept", "application/json")
+				goa.ContextRequest(ctx).URL.Path = "/bottles/1"
+			})
+
+			It("renders service errors as problem details documents", func() {
+				rw.ParentHeader.Set("Content-Type", goa.ErrorMediaIdentifier)
+				err := goa.ErrNotFound("bottle not found", "bottle", 1)
+				Ω(s.Send(ctx, 404, err)).ShouldNot(HaveOccurred())
+				Ω(rw.Status).Should(Equal(404))
+				Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal(goa.ProblemMediaIdentifier))
+				var doc map[string]interface{}
+				Ω(json.Unmarshal(rw.Body, &doc)).ShouldNot(HaveOccurred())
+				Ω(doc).Should(Equal(map[string]interface{}{
+					"type":     "https://errors.goa.design/not_found",
+					"title":    "Not Found",
+					"status":   float64(404),
+					"detail":   "bottle not found",
+					"instance": "/bottles/1",
+					"id":       err.(goa.ServiceError).Token(),
+					"code":     "not_found",
+					"bottle":   float64(1),
+				}))
+			})
+
+			It("does not alter other bodies", func() {
+				Ω(s.Send(ctx, 200, "ok")).ShouldNot(HaveOccurred())
+				Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal("application/json"))
+			})
+		})
+	})
+
 	Describe("FileHandler", func() {
 		const publicPath = "github.com/goadesign/goa/public"
 